@@ -0,0 +1,113 @@
+package goldga
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const updateModeInteractive = "interactive"
+
+// ErrSnapshotSkipped is returned when a mismatched snapshot is skipped by the Approver.
+var ErrSnapshotSkipped = errors.New("snapshot was skipped and does not match the golden file")
+
+type Decision int
+
+const (
+	// DecisionReject keeps the golden file and fails the match.
+	DecisionReject Decision = iota
+	// DecisionAccept updates the golden file and passes the match.
+	DecisionAccept
+	// DecisionSkip keeps the golden file and fails the match with ErrSnapshotSkipped, so it can be
+	// reviewed again in a later run.
+	DecisionSkip
+)
+
+func (d Decision) String() string {
+	switch d {
+	case DecisionReject:
+		return "reject"
+	case DecisionAccept:
+		return "accept"
+	case DecisionSkip:
+		return "skip"
+	default:
+		return fmt.Sprintf("Decision(%d)", int(d))
+	}
+}
+
+// Approver decides what to do with a snapshot which does not match the golden file.
+type Approver interface {
+	Approve(name string, diff []byte) (Decision, error)
+}
+
+var _ Approver = (*TerminalApprover)(nil)
+
+// stdinApprover is shared by all matchers in interactive mode, so input buffered for one
+// snapshot is not lost when the next one is prompted.
+// nolint: gochecknoglobals
+var stdinApprover = &TerminalApprover{In: os.Stdin, Out: os.Stdout}
+
+// TerminalApprover prints the diff and prompts for a decision. It is used when GOLDGA_UPDATE is
+// set to "interactive". Share one TerminalApprover for each input, since it buffers the input.
+type TerminalApprover struct {
+	In  io.Reader
+	Out io.Writer
+
+	mu     sync.Mutex
+	reader *bufio.Reader
+}
+
+func (t *TerminalApprover) Approve(name string, diff []byte) (Decision, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.reader == nil {
+		t.reader = bufio.NewReader(t.In)
+	}
+
+	if _, err := fmt.Fprintf(t.Out, "Snapshot %q does not match the golden file\n%s\n", name, diff); err != nil {
+		return DecisionReject, fmt.Errorf("write error: %w", err)
+	}
+
+	for {
+		if _, err := fmt.Fprint(t.Out, "Accept, reject or skip? [a/r/s] "); err != nil {
+			return DecisionReject, fmt.Errorf("write error: %w", err)
+		}
+
+		line, err := t.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return DecisionReject, fmt.Errorf("read error: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "a", "accept":
+			return DecisionAccept, nil
+		case "r", "reject":
+			return DecisionReject, nil
+		case "s", "skip":
+			return DecisionSkip, nil
+		}
+	}
+}
+
+func getStorageName(storage Storage) string {
+	switch s := storage.(type) {
+	case *SuiteStorage:
+		return s.Name
+	case *DirStorage:
+		return s.Name
+	case *SingleStorage:
+		return s.Path
+	case StorageWrapper:
+		return getStorageName(s.Unwrap())
+	case *InlineStorage:
+		return fmt.Sprintf("%s:%d", s.Path, s.Line)
+	default:
+		return fmt.Sprintf("%T", storage)
+	}
+}
@@ -0,0 +1,87 @@
+package goldga
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+type fakeApprover struct {
+	decision Decision
+	names    []string
+}
+
+func (f *fakeApprover) Approve(name string, diff []byte) (Decision, error) {
+	f.names = append(f.names, name)
+
+	return f.decision, nil
+}
+
+var _ = Describe("TerminalApprover", func() {
+	var out bytes.Buffer
+
+	BeforeEach(func() {
+		out.Reset()
+	})
+
+	approve := func(input string) (Decision, error) {
+		approver := &TerminalApprover{
+			In:  strings.NewReader(input),
+			Out: &out,
+		}
+
+		return approver.Approve("foo", []byte("diff"))
+	}
+
+	DescribeTable("decisions", func(input string, expected Decision) {
+		Expect(approve(input)).To(Equal(expected))
+	},
+		Entry("a", "a\n", DecisionAccept),
+		Entry("accept", "Accept\n", DecisionAccept),
+		Entry("r", "r\n", DecisionReject),
+		Entry("s", "s\n", DecisionSkip),
+		Entry("without newline", "s", DecisionSkip),
+		Entry("invalid input", "x\n\na\n", DecisionAccept),
+	)
+
+	It("should print the diff", func() {
+		_, err := approve("a\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(Equal("Snapshot \"foo\" does not match the golden file\ndiff\nAccept, reject or skip? [a/r/s] "))
+	})
+
+	It("should return error when input is closed", func() {
+		_, err := approve("")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should keep buffered input for later snapshots", func() {
+		approver := &TerminalApprover{In: strings.NewReader("a\nr\n"), Out: &out}
+		Expect(approver.Approve("foo", []byte("diff"))).To(Equal(DecisionAccept))
+		Expect(approver.Approve("bar", []byte("diff"))).To(Equal(DecisionReject))
+	})
+
+	When("GOLDGA_UPDATE is interactive", func() {
+		var updateMode string
+
+		BeforeEach(func() {
+			updateMode = os.Getenv("GOLDGA_UPDATE")
+			Expect(os.Setenv("GOLDGA_UPDATE", "interactive")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Setenv("GOLDGA_UPDATE", updateMode)).To(Succeed())
+		})
+
+		It("should share the approver between matchers", func() {
+			a := newMatcher("foo", "a")
+			b := newMatcher("foo", "b")
+			Expect(a.Approver).To(BeIdenticalTo(stdinApprover))
+			Expect(b.Approver).To(BeIdenticalTo(stdinApprover))
+		})
+	})
+})
@@ -0,0 +1,97 @@
+package goldga
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	backupExt = ".bak"
+	tempExt   = ".tmp"
+)
+
+// writeFileAtomic writes a file by writing a temporary file next to it, syncing it to disk and
+// renaming it over path, so an interrupted write never leaves a truncated file.
+func writeFileAtomic(fs afero.Fs, path string, write func(w *bufio.Writer) error) error {
+	tmpPath := path + tempExt
+
+	file, err := fs.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = fs.Remove(tmpPath)
+
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := fs.Rename(tmpPath, path); err != nil {
+		_ = fs.Remove(tmpPath)
+
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	return nil
+}
+
+// backupSuiteFile copies the suite file to a ".bak" file before it is replaced. Files which
+// cannot be decoded are not copied, so the backup always holds the last valid version.
+func (s *SuiteStorage) backupSuiteFile() error {
+	content, err := afero.ReadFile(s.Fs, s.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if _, err := decodeSuiteData(bytes.NewReader(content)); err != nil {
+		return nil
+	}
+
+	if err := afero.WriteFile(s.Fs, s.Path+backupExt, content, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return nil
+}
+
+// recoverSuiteData points to the ".bak" file when the suite file cannot be decoded and the backup
+// is valid. The backup is not read silently, because the suite file may contain changes which are
+// not in the backup, e.g. unresolved merge conflicts, and the next write would drop them.
+func (s *SuiteStorage) recoverSuiteData(fs afero.Fs, data *suiteData, err error) (*suiteData, error) {
+	decodeErr := new(suiteDecodeError)
+
+	if !errors.As(err, &decodeErr) {
+		return data, err
+	}
+
+	if _, backupErr := readSuiteFile(fs, s.Path+backupExt); backupErr != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("%w (the last valid version of %s is in %s, fix the file or restore the backup)",
+		err, s.Path, s.Path+backupExt)
+}
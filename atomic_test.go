@@ -0,0 +1,80 @@
+package goldga
+
+import (
+	"bufio"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("writeFileAtomic", func() {
+	var fs afero.Fs
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		Expect(afero.WriteFile(fs, "foo", []byte("old"), 0o644)).To(Succeed())
+	})
+
+	It("should replace the file", func() {
+		Expect(writeFileAtomic(fs, "foo", func(w *bufio.Writer) error {
+			_, err := w.WriteString("new")
+
+			return err
+		})).To(Succeed())
+		Expect(mustReadFile(fs, "foo")).To(Equal([]byte("new")))
+		Expect(afero.Exists(fs, "foo"+tempExt)).To(BeFalse())
+	})
+
+	It("should keep the file if write fails", func() {
+		writeErr := errors.New("interrupted")
+		Expect(writeFileAtomic(fs, "foo", func(w *bufio.Writer) error {
+			_, _ = w.WriteString("partial")
+
+			return writeErr
+		})).To(MatchError(writeErr))
+		Expect(mustReadFile(fs, "foo")).To(Equal([]byte("old")))
+		Expect(afero.Exists(fs, "foo"+tempExt)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Suite file recovery", func() {
+	var (
+		fs    afero.Fs
+		suite *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		suite = &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}
+		Expect(suite.Write([]byte("a"))).To(Succeed())
+		Expect(suite.Named("bar").Write([]byte("b"))).To(Succeed())
+	})
+
+	It("should back up the previous version", func() {
+		Expect(ParseSuite(mustReadFile(fs, "foo.golden"+backupExt))).To(Equal(map[string]string{"foo": "a"}))
+	})
+
+	It("should point to the backup if the suite file is truncated", func() {
+		content := mustReadFile(fs, "foo.golden")
+		Expect(afero.WriteFile(fs, "foo.golden", content[:len(content)-5], 0o644)).To(Succeed())
+		_, err := suite.Read()
+		Expect(err).To(MatchError(ContainSubstring("the last valid version of foo.golden is in foo.golden.bak")))
+	})
+
+	It("should not overwrite a suite file which cannot be decoded", func() {
+		conflicted := []byte("<<<<<<< HEAD\n[snapshots]\nfoo = \"a\"\n=======\n")
+		Expect(afero.WriteFile(fs, "foo.golden", conflicted, 0o644)).To(Succeed())
+		Expect(suite.Named("baz").Write([]byte("c"))).NotTo(Succeed())
+		Expect(mustReadFile(fs, "foo.golden")).To(Equal(conflicted))
+		Expect(ParseSuite(mustReadFile(fs, "foo.golden"+backupExt))).To(Equal(map[string]string{"foo": "a"}))
+	})
+
+	It("should return the decode error if there is no valid backup", func() {
+		Expect(afero.WriteFile(fs, "foo.golden"+backupExt, []byte("[snapshots\n"), 0o644)).To(Succeed())
+		Expect(afero.WriteFile(fs, "foo.golden", []byte("[snapshots\n"), 0o644)).To(Succeed())
+		_, err := suite.Read()
+		Expect(err).To(MatchError(HavePrefix("toml decode error")))
+	})
+})
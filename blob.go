@@ -0,0 +1,179 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// blobVersionExt is the extension of files holding the version of cached objects.
+const blobVersionExt = ".version"
+
+// Bucket is a minimal interface of object storages such as Amazon S3 or Google Cloud Storage.
+// Get must return an error wrapping os.ErrNotExist when the object does not exist.
+type Bucket interface {
+	Get(key string) ([]byte, error)
+	Put(key string, data []byte) error
+}
+
+// VersionedBucket is a Bucket which reports the version of objects, such as an ETag or a
+// generation number. BlobStorage checks it before using a cached object.
+type VersionedBucket interface {
+	Bucket
+
+	// Version returns the current version of the object. It must return an error wrapping
+	// os.ErrNotExist when the object does not exist.
+	Version(key string) (string, error)
+}
+
+var _ Storage = (*BlobStorage)(nil)
+
+// BlobStorage stores a golden file as an object in a bucket.
+type BlobStorage struct {
+	Bucket Bucket
+	Prefix string
+	Key    string
+
+	// Cache is an optional local file system caching objects read from the bucket. If Bucket is a
+	// VersionedBucket, cached objects are fetched again when their version changes. Otherwise
+	// objects are only fetched once, until the cache is cleared with ClearCache.
+	Cache afero.Fs
+}
+
+func (b *BlobStorage) objectKey() string {
+	return path.Join(b.Prefix, b.Key)
+}
+
+func (b *BlobStorage) Read() ([]byte, error) {
+	version, err := b.version()
+	if err != nil {
+		return nil, err
+	}
+
+	if data, ok, err := b.readCache(version); err != nil || ok {
+		return data, err
+	}
+
+	data, err := b.Bucket.Get(b.objectKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	if err := b.writeCache(data, version); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (b *BlobStorage) Write(data []byte) error {
+	checkStrictWrite()
+
+	if err := b.Bucket.Put(b.objectKey(), data); err != nil {
+		return fmt.Errorf("failed to put object: %w", err)
+	}
+
+	version, err := b.version()
+	if err != nil {
+		return err
+	}
+
+	return b.writeCache(data, version)
+}
+
+// ClearCache removes the cached object, so the next Read fetches it from the bucket.
+func (b *BlobStorage) ClearCache() error {
+	if b.Cache == nil {
+		return nil
+	}
+
+	name := b.cacheName()
+
+	for _, path := range []string{name, name + blobVersionExt} {
+		if err := b.Cache.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (b *BlobStorage) cacheName() string {
+	return filepath.FromSlash(b.objectKey())
+}
+
+// version returns the version of the object if it is cached and Bucket is a VersionedBucket.
+func (b *BlobStorage) version() (string, error) {
+	bucket, ok := b.Bucket.(VersionedBucket)
+	if !ok || b.Cache == nil {
+		return "", nil
+	}
+
+	version, err := bucket.Version(b.objectKey())
+	if err != nil {
+		return "", fmt.Errorf("failed to get object version: %w", err)
+	}
+
+	return version, nil
+}
+
+// readCache returns the cached object if it exists and has the given version.
+func (b *BlobStorage) readCache(version string) ([]byte, bool, error) {
+	if b.Cache == nil {
+		return nil, false, nil
+	}
+
+	name := b.cacheName()
+
+	if version != "" {
+		cached, err := afero.ReadFile(b.Cache, name+blobVersionExt)
+		if errors.Is(err, afero.ErrFileNotFound) || (err == nil && string(cached) != version) {
+			return nil, false, nil
+		}
+
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read cache: %w", err)
+		}
+	}
+
+	data, err := afero.ReadFile(b.Cache, name)
+	if err != nil {
+		if errors.Is(err, afero.ErrFileNotFound) {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	return data, true, nil
+}
+
+func (b *BlobStorage) writeCache(data []byte, version string) error {
+	if b.Cache == nil {
+		return nil
+	}
+
+	name := b.cacheName()
+
+	if err := b.Cache.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create cache directories: %w", err)
+	}
+
+	if err := afero.WriteFile(b.Cache, name, data, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	if version == "" {
+		return nil
+	}
+
+	if err := afero.WriteFile(b.Cache, name+blobVersionExt, []byte(version), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type memoryBucket struct {
+	objects map[string][]byte
+	gets    int
+}
+
+func (m *memoryBucket) Get(key string) ([]byte, error) {
+	m.gets++
+
+	if data, ok := m.objects[key]; ok {
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("object %q: %w", key, os.ErrNotExist)
+}
+
+func (m *memoryBucket) Put(key string, data []byte) error {
+	m.objects[key] = data
+
+	return nil
+}
+
+// versionedBucket counts writes as object versions.
+type versionedBucket struct {
+	*memoryBucket
+	versions map[string]int
+}
+
+func (v *versionedBucket) Put(key string, data []byte) error {
+	v.versions[key]++
+
+	return v.memoryBucket.Put(key, data)
+}
+
+func (v *versionedBucket) Version(key string) (string, error) {
+	if _, ok := v.objects[key]; !ok {
+		return "", fmt.Errorf("object %q: %w", key, os.ErrNotExist)
+	}
+
+	return fmt.Sprint(v.versions[key]), nil
+}
+
+var _ = Describe("BlobStorage", func() {
+	var (
+		bucket  *memoryBucket
+		storage *BlobStorage
+	)
+
+	BeforeEach(func() {
+		bucket = &memoryBucket{objects: map[string][]byte{}}
+		storage = &BlobStorage{
+			Bucket: bucket,
+			Prefix: "golden",
+			Key:    "foo.golden",
+		}
+	})
+
+	It("should write and read objects under the prefix", func() {
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(bucket.objects).To(HaveKeyWithValue("golden/foo.golden", []byte("foo")))
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should return not found error when object does not exist", func() {
+		_, err := storage.Read()
+		Expect(errors.Is(err, afero.ErrFileNotFound)).To(BeTrue())
+	})
+
+	When("Cache is set", func() {
+		BeforeEach(func() {
+			storage.Cache = afero.NewMemMapFs()
+			bucket.objects["golden/foo.golden"] = []byte("foo")
+		})
+
+		It("should fetch the object only once", func() {
+			Expect(storage.Read()).To(Equal([]byte("foo")))
+			Expect(storage.Read()).To(Equal([]byte("foo")))
+			Expect(bucket.gets).To(Equal(1))
+		})
+
+		It("should update the cache on write", func() {
+			Expect(storage.Read()).To(Equal([]byte("foo")))
+			Expect(storage.Write([]byte("bar"))).To(Succeed())
+			Expect(storage.Read()).To(Equal([]byte("bar")))
+			Expect(bucket.gets).To(Equal(1))
+		})
+
+		It("should fetch the object again after the cache is cleared", func() {
+			Expect(storage.Read()).To(Equal([]byte("foo")))
+			bucket.objects["golden/foo.golden"] = []byte("bar")
+			Expect(storage.Read()).To(Equal([]byte("foo")))
+
+			Expect(storage.ClearCache()).To(Succeed())
+			Expect(storage.Read()).To(Equal([]byte("bar")))
+			Expect(bucket.gets).To(Equal(2))
+		})
+
+		When("Bucket is versioned", func() {
+			var versioned *versionedBucket
+
+			BeforeEach(func() {
+				versioned = &versionedBucket{memoryBucket: bucket, versions: map[string]int{}}
+				storage.Bucket = versioned
+			})
+
+			It("should use the cached object while the version is unchanged", func() {
+				Expect(storage.Read()).To(Equal([]byte("foo")))
+				Expect(storage.Read()).To(Equal([]byte("foo")))
+				Expect(bucket.gets).To(Equal(1))
+			})
+
+			It("should fetch the object again when the version changes", func() {
+				Expect(storage.Read()).To(Equal([]byte("foo")))
+				Expect(versioned.Put("golden/foo.golden", []byte("bar"))).To(Succeed())
+				Expect(storage.Read()).To(Equal([]byte("bar")))
+				Expect(bucket.gets).To(Equal(2))
+			})
+
+			It("should cache the version on write", func() {
+				Expect(storage.Write([]byte("bar"))).To(Succeed())
+				Expect(storage.Read()).To(Equal([]byte("bar")))
+				Expect(bucket.gets).To(Equal(0))
+			})
+
+			It("should return not found error when object does not exist", func() {
+				delete(bucket.objects, "golden/foo.golden")
+				_, err := storage.Read()
+				Expect(errors.Is(err, afero.ErrFileNotFound)).To(BeTrue())
+			})
+		})
+	})
+})
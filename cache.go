@@ -0,0 +1,178 @@
+package goldga
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var _ NamedStorage = (*CacheStorage)(nil)
+
+// CacheStorage caches values read from the inner storage in process. Values are keyed by the
+// name given to Named, and CacheStorage itself uses the empty name.
+type CacheStorage struct {
+	Inner Storage
+
+	name   string
+	parent *CacheStorage
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (c *CacheStorage) root() *CacheStorage {
+	if c.parent != nil {
+		return c.parent
+	}
+
+	return c
+}
+
+// Named returns a storage sharing the cache with c. It returns c if Inner is not a
+// NamedStorage.
+func (c *CacheStorage) Named(name string) Storage {
+	return namedInnerStorage(c, name)
+}
+
+func (c *CacheStorage) Unwrap() Storage {
+	return c.Inner
+}
+
+// Wrap returns a storage sharing the cache with c, whose values are keyed by the name of inner.
+func (c *CacheStorage) Wrap(inner Storage) Storage {
+	return &CacheStorage{
+		Inner:  inner,
+		name:   getStorageName(inner),
+		parent: c.root(),
+	}
+}
+
+func (c *CacheStorage) Read() ([]byte, error) {
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if data, ok := root.values[c.name]; ok {
+		return data, nil
+	}
+
+	data, err := c.Inner.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if root.values == nil {
+		root.values = map[string][]byte{}
+	}
+
+	root.values[c.name] = data
+
+	return data, nil
+}
+
+func (c *CacheStorage) Write(data []byte) error {
+	defer c.Invalidate(c.name)
+
+	return c.Inner.Write(data)
+}
+
+// Invalidate drops the cached value of the given name.
+func (c *CacheStorage) Invalidate(name string) {
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	delete(root.values, name)
+}
+
+// InvalidateAll drops all cached values.
+func (c *CacheStorage) InvalidateAll() {
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	root.values = nil
+}
+
+type suiteCacheKey struct {
+	fs   afero.Fs
+	path string
+}
+
+type suiteCacheEntry struct {
+	modTime time.Time
+	size    int64
+	data    *suiteData
+}
+
+// nolint: gochecknoglobals
+var (
+	suiteCacheMu sync.Mutex
+	suiteCache   = map[suiteCacheKey]*suiteCacheEntry{}
+)
+
+func resetSuiteCache() {
+	suiteCacheMu.Lock()
+	defer suiteCacheMu.Unlock()
+
+	suiteCache = map[suiteCacheKey]*suiteCacheEntry{}
+}
+
+// getCachedSuiteData returns the parsed suite file, which is shared by all SuiteStorages of the
+// same file and parsed again only when its modification time or size changes. The returned data
+// must not be modified.
+func (s *SuiteStorage) getCachedSuiteData() (*suiteData, error) {
+	if !isSuiteCacheable(s.Fs) {
+		return s.getSuiteData()
+	}
+
+	info, err := s.Fs.Stat(s.Path)
+	if err != nil {
+		return s.getSuiteData()
+	}
+
+	key := suiteCacheKey{fs: s.Fs, path: s.Path}
+
+	suiteCacheMu.Lock()
+	entry, ok := suiteCache[key]
+	suiteCacheMu.Unlock()
+
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		debugf("suite cache hit %s", s.Path)
+
+		return entry.data, nil
+	}
+
+	debugf("suite cache miss %s", s.Path)
+
+	data, err := s.getSuiteData()
+	if err != nil {
+		return nil, err
+	}
+
+	suiteCacheMu.Lock()
+	suiteCache[key] = &suiteCacheEntry{modTime: info.ModTime(), size: info.Size(), data: data}
+	suiteCacheMu.Unlock()
+
+	return data, nil
+}
+
+// invalidateSuiteCache drops the parsed suite file after it is written, in case the
+// modification time did not change.
+func (s *SuiteStorage) invalidateSuiteCache() {
+	if !isSuiteCacheable(s.Fs) {
+		return
+	}
+
+	suiteCacheMu.Lock()
+	defer suiteCacheMu.Unlock()
+
+	delete(suiteCache, suiteCacheKey{fs: s.Fs, path: s.Path})
+}
+
+// isSuiteCacheable reports whether fs can be used as a cache key. Values such as afero.IOFS may
+// wrap types which cannot be compared, so only pointers are used. A nil fs is not cached.
+func isSuiteCacheable(fs afero.Fs) bool {
+	return fs != nil && reflect.TypeOf(fs).Kind() == reflect.Ptr
+}
@@ -0,0 +1,175 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("CacheStorage", func() {
+	var (
+		mockCtrl *gomock.Controller
+		inner    *MockStorage
+		storage  *CacheStorage
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		inner = NewMockStorage(mockCtrl)
+		storage = &CacheStorage{Inner: inner}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should read the inner storage only once", func() {
+		inner.EXPECT().Read().Return([]byte("foo"), nil).Times(1)
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should not cache errors", func() {
+		inner.EXPECT().Read().Return(nil, errors.New("error"))
+		inner.EXPECT().Read().Return([]byte("foo"), nil)
+		_, err := storage.Read()
+		Expect(err).To(HaveOccurred())
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should see the new value after write", func() {
+		gomock.InOrder(
+			inner.EXPECT().Read().Return([]byte("foo"), nil),
+			inner.EXPECT().Write([]byte("bar")).Return(nil),
+			inner.EXPECT().Read().Return([]byte("bar"), nil),
+		)
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+		Expect(storage.Write([]byte("bar"))).To(Succeed())
+		Expect(storage.Read()).To(Equal([]byte("bar")))
+	})
+
+	It("should read again after InvalidateAll", func() {
+		inner.EXPECT().Read().Return([]byte("foo"), nil).Times(2)
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+		storage.InvalidateAll()
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should return itself when inner storage does not support names", func() {
+		Expect(storage.Named("foo")).To(BeIdenticalTo(storage))
+	})
+
+	Describe("Named", func() {
+		var suite *SuiteStorage
+
+		BeforeEach(func() {
+			suite = &SuiteStorage{
+				Path: filepath.Join("testdata", "cache.golden"),
+				Fs:   afero.NewMemMapFs(),
+			}
+			storage = &CacheStorage{Inner: suite}
+			Expect(suite.Named("a").Write([]byte("a"))).To(Succeed())
+		})
+
+		It("should share the cache between named storages", func() {
+			Expect(storage.Named("a").Read()).To(Equal([]byte("a")))
+			Expect(suite.Named("a").Write([]byte("changed"))).To(Succeed())
+			Expect(storage.Named("a").Read()).To(Equal([]byte("a")))
+
+			storage.Invalidate("a")
+			Expect(storage.Named("a").Read()).To(Equal([]byte("changed")))
+		})
+
+		It("should invalidate the entry on write", func() {
+			Expect(storage.Named("a").Read()).To(Equal([]byte("a")))
+			Expect(storage.Named("a").Write([]byte("b"))).To(Succeed())
+			Expect(storage.Named("a").Read()).To(Equal([]byte("b")))
+		})
+	})
+})
+
+var _ = Describe("Suite cache", func() {
+	var (
+		fs    afero.Fs
+		suite *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		resetSuiteCache()
+		fs = afero.NewMemMapFs()
+		suite = &SuiteStorage{Path: "foo.golden", Fs: fs}
+		Expect(suite.Named("a").Write([]byte("a"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		resetSuiteCache()
+	})
+
+	It("should parse the file once", func() {
+		first, err := suite.Named("a").(*SuiteStorage).getCachedSuiteData()
+		Expect(err).NotTo(HaveOccurred())
+		second, err := suite.Named("b").(*SuiteStorage).getCachedSuiteData()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("should parse the file again when it is written", func() {
+		Expect(suite.Named("a").Read()).To(Equal([]byte("a")))
+		Expect(suite.Named("a").Write([]byte("b"))).To(Succeed())
+		Expect(suite.Named("a").Read()).To(Equal([]byte("b")))
+	})
+
+	It("should parse the file again when its modification time changes", func() {
+		Expect(suite.Named("a").Read()).To(Equal([]byte("a")))
+		Expect(afero.WriteFile(fs, "foo.golden", []byte("[snapshots]\n\"a\" = \"c\"\n"), 0o644)).To(Succeed())
+		Expect(fs.Chtimes("foo.golden", time.Now(), time.Now().Add(time.Hour))).To(Succeed())
+		Expect(suite.Named("a").Read()).To(Equal([]byte("c")))
+	})
+
+	It("should not cache a nil Fs", func() {
+		Expect(isSuiteCacheable(nil)).To(BeFalse())
+	})
+})
+
+func BenchmarkSuiteStorageRead(b *testing.B) {
+	const count = 2000
+
+	fs := afero.NewMemMapFs()
+	suite := &SuiteStorage{Path: "bench.golden", Fs: fs}
+	data := newSuiteData()
+
+	for i := 0; i < count; i++ {
+		data.Snapshots[fmt.Sprintf("snapshot %d", i)] = strings.Repeat("line\n", 20)
+	}
+
+	if err := suite.writeSuiteData(data); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("parse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := suite.getSuiteData(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		resetSuiteCache()
+		defer resetSuiteCache()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := suite.Named(fmt.Sprintf("snapshot %d", i%count)).Read(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
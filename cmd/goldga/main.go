@@ -0,0 +1,305 @@
+// Command goldga inspects and edits goldga suite files.
+//
+// Usage:
+//
+//	goldga list <suite file>
+//	goldga pending <suite file>
+//	goldga show <suite file> <name>
+//	goldga diff <suite file> <name> [received file]
+//	goldga approve <suite file> [name]...
+//	goldga reject <suite file> [name]...
+//	goldga delete <suite file> <name>...
+//	goldga rollback <suite file|dir> <name>...
+//	goldga migrate <suite file|dir> <suite file|dir>
+//	goldga prune <usage manifest>...
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	"github.com/tommy351/goldga"
+)
+
+const usage = `Usage:
+  goldga list <suite file>                          List snapshot names
+  goldga pending <suite file>                       List pending snapshot names
+  goldga show <suite file> <name>                   Print a snapshot
+  goldga diff <suite file> <name> [received file]   Compare a snapshot with received or pending output
+  goldga approve <suite file> [name]...             Accept pending snapshots, or all if no name is given
+  goldga reject <suite file> [name]...              Discard pending snapshots, or all if no name is given
+  goldga delete <suite file> <name>...              Delete snapshots
+  goldga rollback <suite file|dir> <name>...        Restore snapshots kept before their last update
+  goldga migrate <suite file|dir> <suite file|dir>  Copy snapshots to another storage layout
+  goldga prune <usage manifest>...                  Delete snapshots not listed in usage manifests
+`
+
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	os.Exit(run(os.Args[1:], afero.NewOsFs(), os.Stdout, os.Stderr))
+}
+
+func run(args []string, fs afero.Fs, stdout, stderr io.Writer) int {
+	if err := runCommand(args, fs, stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(stderr, usage)
+
+			return 2
+		}
+
+		fmt.Fprintln(stderr, "goldga:", err)
+
+		return 1
+	}
+
+	return 0
+}
+
+func runCommand(args []string, fs afero.Fs, stdout io.Writer) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	command, path, args := args[0], args[1], args[2:]
+	suite := &goldga.SuiteStorage{Path: path, Fs: fs}
+
+	switch {
+	case command == "list" && len(args) == 0:
+		return list(fs, path, stdout)
+	case command == "pending" && len(args) == 0:
+		return list(fs, pendingPath(suite), stdout)
+	case command == "show" && len(args) == 1:
+		return show(suite, args[0], stdout)
+	case command == "diff" && len(args) == 1:
+		return diff(fs, suite, args[0], "", stdout)
+	case command == "diff" && len(args) == 2:
+		return diff(fs, suite, args[0], args[1], stdout)
+	case command == "approve":
+		return review(fs, suite, args, goldga.AcceptPending, "Approved", stdout)
+	case command == "reject":
+		return review(fs, suite, args, goldga.RejectPending, "Rejected", stdout)
+	case command == "delete" && len(args) > 0:
+		return deleteSnapshots(suite, args, stdout)
+	case command == "rollback" && len(args) > 0:
+		return rollback(fs, path, args, stdout)
+	case command == "migrate" && len(args) == 1:
+		return migrate(fs, path, args[0], stdout)
+	case command == "prune":
+		return prune(fs, append([]string{path}, args...), stdout)
+	default:
+		return errUsage
+	}
+}
+
+func list(fs afero.Fs, path string, w io.Writer) error {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to read suite file: %w", err)
+	}
+
+	snapshots, err := goldga.ParseSuite(content)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range sortedNames(snapshots) {
+		fmt.Fprintln(w, name)
+	}
+
+	return nil
+}
+
+func show(suite *goldga.SuiteStorage, name string, w io.Writer) error {
+	data, err := suite.Named(name).Read()
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %q: %w", name, err)
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+func diff(fs afero.Fs, suite *goldga.SuiteStorage, name, receivedPath string, w io.Writer) error {
+	var (
+		received []byte
+		err      error
+	)
+
+	if receivedPath == "" {
+		received, err = suite.Named(name).(*goldga.SuiteStorage).Pending().Read()
+	} else {
+		received, err = readFile(fs, receivedPath)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read received output: %w", err)
+	}
+
+	snapshot, err := suite.Named(name).Read()
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %q: %w", name, err)
+	}
+
+	differ := &goldga.UnifiedDiffer{Context: 3}
+	fmt.Fprintln(w, string(differ.Diff(snapshot, received)))
+
+	return nil
+}
+
+func deleteSnapshots(suite *goldga.SuiteStorage, names []string, w io.Writer) error {
+	for _, name := range names {
+		if err := suite.Named(name).(goldga.DeletableStorage).Delete(); err != nil {
+			return fmt.Errorf("failed to delete snapshot %q: %w", name, err)
+		}
+
+		fmt.Fprintln(w, "Deleted", name)
+	}
+
+	return nil
+}
+
+func rollback(fs afero.Fs, path string, names []string, w io.Writer) error {
+	storage, err := openStorage(fs, path)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := goldga.RollbackSnapshot(storage.Named(name)); err != nil {
+			return fmt.Errorf("failed to roll back snapshot %q: %w", name, err)
+		}
+
+		fmt.Fprintln(w, "Rolled back", name)
+	}
+
+	return nil
+}
+
+// review applies fn to the pending snapshots with the given names, or all pending snapshots if
+// no name is given.
+func review(fs afero.Fs, suite *goldga.SuiteStorage, names []string,
+	fn func(goldga.StagingStorage) error, verb string, w io.Writer) error {
+	if len(names) == 0 {
+		content, err := afero.ReadFile(fs, pendingPath(suite))
+		if err != nil {
+			return fmt.Errorf("failed to read pending file: %w", err)
+		}
+
+		snapshots, err := goldga.ParseSuite(content)
+		if err != nil {
+			return err
+		}
+
+		names = sortedNames(snapshots)
+	}
+
+	for _, name := range names {
+		if err := fn(suite.Named(name).(*goldga.SuiteStorage)); err != nil {
+			return fmt.Errorf("snapshot %q: %w", name, err)
+		}
+
+		fmt.Fprintln(w, verb, name)
+	}
+
+	return nil
+}
+
+func pendingPath(suite *goldga.SuiteStorage) string {
+	return suite.Pending().(*goldga.SuiteStorage).Path
+}
+
+func migrate(fs afero.Fs, from, to string, w io.Writer) error {
+	source, err := openStorage(fs, from)
+	if err != nil {
+		return err
+	}
+
+	dest, err := openStorage(fs, to)
+	if err != nil {
+		return err
+	}
+
+	names, err := goldga.Migrate(source, dest)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Migrated %d snapshots from %s to %s\n", len(names), from, to)
+
+	return nil
+}
+
+// prune deletes snapshots not listed in usage manifests written by goldga.WriteUsageManifest.
+func prune(fs afero.Fs, manifests []string, w io.Writer) error {
+	for _, manifest := range manifests {
+		content, err := readFile(fs, manifest)
+		if err != nil {
+			return fmt.Errorf("failed to read usage manifest: %w", err)
+		}
+
+		pruned, err := goldga.PruneFromManifest(fs, content)
+		if err != nil {
+			return err
+		}
+
+		paths := make([]string, 0, len(pruned))
+
+		for path := range pruned {
+			paths = append(paths, path)
+		}
+
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			for _, name := range pruned[path] {
+				fmt.Fprintf(w, "Pruned %s from %s\n", name, path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// openStorage returns a DirStorage if path is a directory or has no ".golden" extension, or a
+// SuiteStorage otherwise.
+func openStorage(fs afero.Fs, path string) (goldga.ListableStorage, error) {
+	isDir, err := afero.IsDir(fs, path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	if isDir || (err != nil && filepath.Ext(path) != ".golden") {
+		return &goldga.DirStorage{Dir: path, Fs: fs}, nil
+	}
+
+	return &goldga.SuiteStorage{Path: path, Fs: fs}, nil
+}
+
+// readFile reads a file, or stdin if path is "-".
+func readFile(fs afero.Fs, path string) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+
+	return afero.ReadFile(fs, path)
+}
+
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+	"github.com/tommy351/goldga"
+)
+
+func TestCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "goldga command")
+}
+
+var _ = Describe("run", func() {
+	var (
+		fs             afero.Fs
+		stdout, stderr *bytes.Buffer
+		code           int
+	)
+
+	const path = "testdata/suite.golden"
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		stdout = new(bytes.Buffer)
+		stderr = new(bytes.Buffer)
+
+		suite := &goldga.SuiteStorage{Path: path, Fs: fs}
+		Expect(suite.Named("b").Write([]byte("bar\n"))).To(Succeed())
+		Expect(suite.Named("a").Write([]byte("foo\n"))).To(Succeed())
+	})
+
+	exec := func(args ...string) {
+		code = run(args, fs, stdout, stderr)
+	}
+
+	When("list", func() {
+		BeforeEach(func() {
+			exec("list", path)
+		})
+
+		It("should print sorted names", func() {
+			Expect(code).To(Equal(0))
+			Expect(stdout.String()).To(Equal("a\nb\n"))
+		})
+	})
+
+	When("show", func() {
+		BeforeEach(func() {
+			exec("show", path, "b")
+		})
+
+		It("should print the snapshot", func() {
+			Expect(code).To(Equal(0))
+			Expect(stdout.String()).To(Equal("bar\n"))
+		})
+	})
+
+	When("diff", func() {
+		BeforeEach(func() {
+			Expect(afero.WriteFile(fs, "received.txt", []byte("baz\n"), 0o644)).To(Succeed())
+			exec("diff", path, "a", "received.txt")
+		})
+
+		It("should print the diff", func() {
+			Expect(code).To(Equal(0))
+			Expect(stdout.String()).To(ContainSubstring("-foo"))
+			Expect(stdout.String()).To(ContainSubstring("+baz"))
+		})
+	})
+
+	When("there are pending snapshots", func() {
+		BeforeEach(func() {
+			suite := &goldga.SuiteStorage{Path: path, Fs: fs}
+			Expect(suite.Named("a").(goldga.StagingStorage).Pending().Write([]byte("baz\n"))).To(Succeed())
+		})
+
+		When("pending", func() {
+			BeforeEach(func() {
+				exec("pending", path)
+			})
+
+			It("should print pending names", func() {
+				Expect(code).To(Equal(0))
+				Expect(stdout.String()).To(Equal("a\n"))
+			})
+		})
+
+		When("diff without received file", func() {
+			BeforeEach(func() {
+				exec("diff", path, "a")
+			})
+
+			It("should compare with the pending snapshot", func() {
+				Expect(code).To(Equal(0))
+				Expect(stdout.String()).To(ContainSubstring("+baz"))
+			})
+		})
+
+		When("approve", func() {
+			BeforeEach(func() {
+				exec("approve", path)
+			})
+
+			It("should update the snapshot", func() {
+				Expect(code).To(Equal(0))
+				Expect(stdout.String()).To(Equal("Approved a\n"))
+				Expect(readSuite(fs, path)).To(HaveKeyWithValue("a", "baz\n"))
+				Expect(afero.Exists(fs, path+".pending")).To(BeFalse())
+			})
+		})
+
+		When("reject", func() {
+			BeforeEach(func() {
+				exec("reject", path, "a")
+			})
+
+			It("should keep the snapshot", func() {
+				Expect(code).To(Equal(0))
+				Expect(stdout.String()).To(Equal("Rejected a\n"))
+				Expect(readSuite(fs, path)).To(HaveKeyWithValue("a", "foo\n"))
+				Expect(afero.Exists(fs, path+".pending")).To(BeFalse())
+			})
+		})
+	})
+
+	When("delete", func() {
+		BeforeEach(func() {
+			exec("delete", path, "a")
+		})
+
+		It("should remove the snapshot", func() {
+			Expect(code).To(Equal(0))
+
+			Expect(readSuite(fs, path)).To(Equal(map[string]string{"b": "bar\n"}))
+		})
+	})
+
+	When("rollback", func() {
+		BeforeEach(func() {
+			suite := &goldga.SuiteStorage{Path: path, Fs: fs, KeepPrevious: true}
+			Expect(suite.Named("a").Write([]byte("baz\n"))).To(Succeed())
+			exec("rollback", path, "a")
+		})
+
+		It("should restore the previous snapshot", func() {
+			Expect(code).To(Equal(0))
+			Expect(stdout.String()).To(Equal("Rolled back a\n"))
+			Expect(readSuite(fs, path)).To(Equal(map[string]string{"a": "foo\n", "b": "bar\n"}))
+		})
+
+		It("should fail without a previous snapshot", func() {
+			exec("rollback", path, "b")
+			Expect(code).To(Equal(1))
+			Expect(stderr.String()).To(ContainSubstring("no previous version"))
+		})
+	})
+
+	When("migrate", func() {
+		BeforeEach(func() {
+			exec("migrate", path, "testdata/suite")
+		})
+
+		It("should copy snapshots to a directory", func() {
+			Expect(code).To(Equal(0))
+			Expect(stdout.String()).To(Equal("Migrated 2 snapshots from testdata/suite.golden to testdata/suite\n"))
+			Expect(afero.ReadFile(fs, "testdata/suite/a.golden")).To(Equal([]byte("foo\n")))
+			Expect(afero.ReadFile(fs, "testdata/suite/b.golden")).To(Equal([]byte("bar\n")))
+		})
+
+		It("should copy snapshots back to a suite file", func() {
+			exec("migrate", "testdata/suite", "testdata/copy.golden")
+			Expect(code).To(Equal(0))
+			Expect(readSuite(fs, "testdata/copy.golden")).To(Equal(readSuite(fs, path)))
+		})
+	})
+
+	When("prune", func() {
+		BeforeEach(func() {
+			Expect(afero.WriteFile(fs, "testdata/usage.json", []byte(`{"testdata/suite.golden": {"names": ["a"]}}`), 0o644)).To(Succeed())
+			exec("prune", "testdata/usage.json")
+		})
+
+		It("should delete snapshots not in the manifest", func() {
+			Expect(code).To(Equal(0))
+			Expect(stdout.String()).To(Equal("Pruned b from testdata/suite.golden\n"))
+			Expect(readSuite(fs, path)).To(Equal(map[string]string{"a": "foo\n"}))
+		})
+	})
+
+	When("snapshot does not exist", func() {
+		BeforeEach(func() {
+			exec("show", path, "c")
+		})
+
+		It("should fail", func() {
+			Expect(code).To(Equal(1))
+			Expect(stderr.String()).To(ContainSubstring(`snapshot "c"`))
+		})
+	})
+
+	When("arguments are invalid", func() {
+		BeforeEach(func() {
+			exec("list")
+		})
+
+		It("should print usage", func() {
+			Expect(code).To(Equal(2))
+			Expect(stderr.String()).To(HavePrefix("Usage:"))
+		})
+	})
+})
+
+func readSuite(fs afero.Fs, path string) map[string]string {
+	content, err := afero.ReadFile(fs, path)
+	Expect(err).NotTo(HaveOccurred())
+
+	snapshots, err := goldga.ParseSuite(content)
+	Expect(err).NotTo(HaveOccurred())
+
+	return snapshots
+}
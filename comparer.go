@@ -0,0 +1,207 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// nolint: gochecknoglobals
+var (
+	DefaultComparer Comparer = &ExactComparer{}
+)
+
+// Comparer decides whether the content of a golden file matches the actual content.
+type Comparer interface {
+	Compare(expected, actual []byte) (bool, error)
+}
+
+var _ Comparer = (*ExactComparer)(nil)
+
+// ExactComparer matches only identical content.
+type ExactComparer struct{}
+
+func (ExactComparer) Compare(expected, actual []byte) (bool, error) {
+	return bytes.Equal(expected, actual), nil
+}
+
+var (
+	_ Comparer = (*JSONComparer)(nil)
+	_ Differ   = (*JSONComparer)(nil)
+)
+
+// JSONComparer compares JSON documents structurally, so key order and formatting are ignored.
+// It is also a Differ which lists only the paths that differ.
+type JSONComparer struct {
+	// IgnorePaths are paths excluded from comparison, such as "metadata.createdAt" or "items[*].id",
+	// with the same syntax as IgnoringPaths. "*" matches any object key and "[*]" any array index.
+	IgnorePaths []string
+
+	// Tolerance allows numbers to differ slightly, e.g. results of floating-point computations.
+	Tolerance FloatTolerance
+}
+
+// JSONDifference is a difference between two JSON documents. Expected or Actual is nil when the
+// path does not exist on that side.
+type JSONDifference struct {
+	Path     string
+	Expected interface{}
+	Actual   interface{}
+}
+
+func (j *JSONComparer) Compare(expected, actual []byte) (bool, error) {
+	diffs, err := j.Differences(expected, actual)
+	if err != nil {
+		return false, err
+	}
+
+	return len(diffs) == 0, nil
+}
+
+// Differences returns the differences between two JSON documents sorted by path.
+func (j *JSONComparer) Differences(expected, actual []byte) ([]JSONDifference, error) {
+	var expectedValue, actualValue interface{}
+
+	if err := json.Unmarshal(expected, &expectedValue); err != nil {
+		return nil, fmt.Errorf("failed to decode expected JSON: %w", err)
+	}
+
+	if err := json.Unmarshal(actual, &actualValue); err != nil {
+		return nil, fmt.Errorf("failed to decode actual JSON: %w", err)
+	}
+
+	ignores, err := parseJSONPaths(j.IgnorePaths)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignore path: %w", err)
+	}
+
+	c := &jsonComparison{
+		ignores:   ignores,
+		tolerance: j.Tolerance,
+	}
+
+	c.compare(nil, expectedValue, actualValue)
+
+	return c.diffs, nil
+}
+
+func (j *JSONComparer) Diff(snapshot, received []byte) []byte {
+	diffs, err := j.Differences(snapshot, received)
+	if err != nil {
+		return DefaultDiffer.Diff(snapshot, received)
+	}
+
+	lines := []string{
+		"- Snapshot",
+		"+ Received",
+		"",
+	}
+
+	for _, diff := range diffs {
+		if diff.Expected != nil {
+			lines = append(lines, fmt.Sprintf("- %s: %s", diff.Path, encodeJSONValue(diff.Expected)))
+		}
+
+		if diff.Actual != nil {
+			lines = append(lines, fmt.Sprintf("+ %s: %s", diff.Path, encodeJSONValue(diff.Actual)))
+		}
+	}
+
+	return []byte(strings.Join(colorizeDiffLines(lines), "\n"))
+}
+
+func encodeJSONValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+type jsonComparison struct {
+	ignores   [][]jsonPathSegment
+	tolerance FloatTolerance
+	diffs     []JSONDifference
+}
+
+func (c *jsonComparison) compare(path []jsonPathSegment, expected, actual interface{}) {
+	if isJSONPathIgnored(c.ignores, path) {
+		return
+	}
+
+	appendPath := func(segment jsonPathSegment) []jsonPathSegment {
+		return append(append([]jsonPathSegment{}, path...), segment)
+	}
+
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		if actual, ok := actual.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(expected)+len(actual))
+
+			for k := range expected {
+				keys = append(keys, k)
+			}
+
+			for k := range actual {
+				if _, ok := expected[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				_, inExpected := expected[k]
+				_, inActual := actual[k]
+
+				if inExpected != inActual && !isJSONPathIgnored(c.ignores, appendPath(jsonPathKey(k))) {
+					c.diffs = append(c.diffs, JSONDifference{
+						Path:     formatJSONPath(appendPath(jsonPathKey(k))),
+						Expected: expected[k],
+						Actual:   actual[k],
+					})
+
+					continue
+				}
+
+				c.compare(appendPath(jsonPathKey(k)), expected[k], actual[k])
+			}
+
+			return
+		}
+	case []interface{}:
+		if actual, ok := actual.([]interface{}); ok {
+			for i := 0; i < len(expected) || i < len(actual); i++ {
+				var e, a interface{}
+
+				if i < len(expected) {
+					e = expected[i]
+				}
+
+				if i < len(actual) {
+					a = actual[i]
+				}
+
+				c.compare(appendPath(jsonPathIndex(i)), e, a)
+			}
+
+			return
+		}
+	case float64:
+		if actual, ok := actual.(float64); ok && c.tolerance.Equal(expected, actual) {
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		c.diffs = append(c.diffs, JSONDifference{
+			Path:     formatJSONPath(path),
+			Expected: expected,
+			Actual:   actual,
+		})
+	}
+}
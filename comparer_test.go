@@ -0,0 +1,76 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExactComparer", func() {
+	It("should match identical content", func() {
+		Expect(ExactComparer{}.Compare([]byte("a"), []byte("a"))).To(BeTrue())
+	})
+
+	It("should not match different content", func() {
+		Expect(ExactComparer{}.Compare([]byte("a"), []byte("a "))).To(BeFalse())
+	})
+})
+
+var _ = Describe("JSONComparer", func() {
+	DescribeTable("Compare", func(comparer *JSONComparer, expected, actual string, match bool) {
+		Expect(comparer.Compare([]byte(expected), []byte(actual))).To(Equal(match))
+	},
+		Entry("key order", &JSONComparer{}, `{"a":1,"b":2}`, `{"b":2,"a":1}`, true),
+		Entry("whitespace", &JSONComparer{}, `{"a": [1, 2]}`, "{\n\"a\":[1,2]\n}", true),
+		Entry("different value", &JSONComparer{}, `{"a":1}`, `{"a":2}`, false),
+		Entry("array order", &JSONComparer{}, `[1,2]`, `[2,1]`, false),
+		Entry("missing key", &JSONComparer{}, `{"a":null}`, `{}`, false),
+		Entry("ignored path", &JSONComparer{IgnorePaths: []string{"meta.createdAt"}},
+			`{"meta":{"createdAt":1,"name":"a"}}`, `{"meta":{"createdAt":2,"name":"a"}}`, true),
+		Entry("ignored wildcard", &JSONComparer{IgnorePaths: []string{"items[*].id"}},
+			`{"items":[{"id":1,"v":"a"},{"id":2,"v":"b"}]}`, `{"items":[{"id":3,"v":"a"},{"id":4,"v":"b"}]}`, true),
+		Entry("ignored wildcard with change", &JSONComparer{IgnorePaths: []string{"items[*].id"}},
+			`{"items":[{"id":1,"v":"a"}]}`, `{"items":[{"id":3,"v":"b"}]}`, false),
+		Entry("within tolerance", &JSONComparer{Tolerance: FloatTolerance{Absolute: 1e-9}},
+			`{"a":[0.3]}`, `{"a":[0.30000000000000004]}`, true),
+		Entry("outside tolerance", &JSONComparer{Tolerance: FloatTolerance{Absolute: 1e-9}},
+			`{"a":0.3}`, `{"a":0.31}`, false),
+	)
+
+	It("should return error on invalid ignore paths", func() {
+		_, err := (&JSONComparer{IgnorePaths: []string{"a..b"}}).Compare([]byte(`{}`), []byte(`{}`))
+		Expect(err).To(MatchError(`invalid ignore path: invalid path "a..b"`))
+	})
+
+	It("should return error on invalid JSON", func() {
+		_, err := (&JSONComparer{}).Compare([]byte(`{`), []byte(`{}`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should list differences", func() {
+		comparer := &JSONComparer{}
+		Expect(comparer.Differences(
+			[]byte(`{"a":1,"b":{"c":[1,2]},"d":true}`),
+			[]byte(`{"a":2,"b":{"c":[1]},"e":"x"}`),
+		)).To(Equal([]JSONDifference{
+			{Path: "a", Expected: float64(1), Actual: float64(2)},
+			{Path: "b.c[1]", Expected: float64(2)},
+			{Path: "d", Expected: true},
+			{Path: "e", Actual: "x"},
+		}))
+	})
+
+	It("should render the differences", func() {
+		if colorSupported {
+			Skip("color is supported")
+		}
+
+		comparer := &JSONComparer{}
+		Expect(string(comparer.Diff([]byte(`{"a":1,"b":"x"}`), []byte(`{"a":2}`)))).To(Equal(`- Snapshot
++ Received
+
+- a: 1
++ a: 2
+- b: "x"`))
+	})
+})
@@ -0,0 +1,112 @@
+package goldga
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+)
+
+// compressedHeader marks compressed snapshots. The compressed data follows it in base64, so
+// compressed snapshots can be stored in suite files too.
+const compressedHeader = "goldga:gzip:"
+
+// NoCompression selects gzip.NoCompression for CompressedStorage.Level, because the zero value
+// selects gzip.DefaultCompression.
+const NoCompression = -100
+
+var _ NamedStorage = (*CompressedStorage)(nil)
+
+// CompressedStorage compresses snapshots with gzip before writing them to the inner storage.
+// Snapshots without the compressed header are read as is, so existing golden files keep working.
+type CompressedStorage struct {
+	Inner Storage
+
+	// Level is the gzip compression level. Defaults to gzip.DefaultCompression. Use NoCompression
+	// for gzip.NoCompression.
+	Level int
+}
+
+// WithCompression compresses snapshots of the storage set by previous options with gzip.
+func WithCompression() Option {
+	return func(matcher *Matcher) {
+		matcher.Storage = &CompressedStorage{Inner: matcher.Storage}
+	}
+}
+
+// Named returns a CompressedStorage for the named snapshot. It returns c if Inner is not a
+// NamedStorage.
+func (c *CompressedStorage) Named(name string) Storage {
+	return namedInnerStorage(c, name)
+}
+
+func (c *CompressedStorage) Unwrap() Storage {
+	return c.Inner
+}
+
+func (c *CompressedStorage) Wrap(inner Storage) Storage {
+	wrapped := *c
+	wrapped.Inner = inner
+
+	return &wrapped
+}
+
+func (c *CompressedStorage) Read() ([]byte, error) {
+	data, err := c.Inner.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, []byte(compressedHeader)) {
+		return data, nil
+	}
+
+	data = bytes.TrimSpace(data[len(compressedHeader):])
+	compressed := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+
+	n, err := base64.StdEncoding.Decode(compressed, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode compressed snapshot: %w", err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(compressed[:n]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+
+	data, err = ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *CompressedStorage) Write(data []byte) error {
+	var buf bytes.Buffer
+
+	level := c.Level
+
+	switch level {
+	case 0:
+		level = gzip.DefaultCompression
+	case NoCompression:
+		level = gzip.NoCompression
+	}
+
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+
+	return c.Inner.Write([]byte(compressedHeader + base64.StdEncoding.EncodeToString(buf.Bytes())))
+}
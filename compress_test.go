@@ -0,0 +1,90 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("CompressedStorage", func() {
+	var (
+		fs      afero.Fs
+		inner   *SingleStorage
+		storage *CompressedStorage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		inner = &SingleStorage{Path: "foo.golden", Fs: fs}
+		storage = &CompressedStorage{Inner: inner}
+	})
+
+	It("should compress on write", func() {
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+
+		data, err := inner.Read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(HavePrefix(compressedHeader))
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should read uncompressed snapshots", func() {
+		Expect(inner.Write([]byte("foo"))).To(Succeed())
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should read uncompressed snapshots starting with gzip magic bytes", func() {
+		data := []byte{0x1f, 0x8b, 0x00, 0x01}
+		Expect(inner.Write(data)).To(Succeed())
+		Expect(storage.Read()).To(Equal(data))
+	})
+
+	It("should support gzip.NoCompression", func() {
+		storage.Level = NoCompression
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should return error if the inner storage fails", func() {
+		_, err := storage.Read()
+		Expect(err).To(MatchError(afero.ErrFileNotFound))
+	})
+
+	Describe("Named", func() {
+		It("should wrap the named inner storage", func() {
+			named := (&CompressedStorage{Inner: &DirStorage{Dir: "foo", Fs: fs}}).Named("bar")
+			Expect(named.Write([]byte("baz"))).To(Succeed())
+			Expect(afero.Exists(fs, "foo/bar.golden")).To(BeTrue())
+			Expect(named.Read()).To(Equal([]byte("baz")))
+		})
+
+		It("should return itself if the inner storage does not support names", func() {
+			Expect(storage.Named("bar")).To(BeIdenticalTo(storage))
+		})
+	})
+})
+
+var _ = Describe("WithCompression", func() {
+	It("should wrap the current storage", func() {
+		m := newMatcher("testdata/foo.golden", "foo", WithCompression())
+		Expect(m.Storage).To(Equal(&CompressedStorage{
+			Inner: &SuiteStorage{
+				Path:          "testdata/foo.golden",
+				Name:          "foo",
+				Fs:            defaultFs,
+				DecodeRetries: defaultDecodeRetries,
+			},
+		}))
+	})
+
+	It("should read existing snapshots of suite files", func() {
+		fs := afero.NewMemMapFs()
+		suite := &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}
+		Expect(suite.Write([]byte("foo"))).To(Succeed())
+
+		m := newMatcher("foo.golden", "foo", WithStorage(suite), WithCompression(), WithSerializer(&StringSerializer{}))
+		Expect(m.Match("foo")).To(BeTrue())
+		Expect(m.Sub("bar").Storage.Write([]byte("bar"))).To(Succeed())
+		Expect(m.Sub("bar").Storage.Read()).To(Equal([]byte("bar")))
+	})
+})
@@ -0,0 +1,186 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+)
+
+const configFileName = ".goldga.toml"
+
+// config is the project configuration read from .goldga.toml.
+type config struct {
+	// Storage is "suite" (default) or "dir".
+	Storage string `toml:"storage"`
+	// SuiteFormat is "toml" (default), "json" or "yaml".
+	SuiteFormat string `toml:"suite_format"`
+	// Serializer is "dump" (default), "yaml", "json", "toml" or "string".
+	Serializer string `toml:"serializer"`
+	// UpdateEnv is the name of the environment variable enabling update mode, in addition to
+	// UPDATE_GOLDEN.
+	UpdateEnv string `toml:"update_env"`
+	// Diff is "color" (default) or "unified".
+	Diff        string `toml:"diff"`
+	DiffContext int    `toml:"diff_context"`
+	// NameTemplate is a template for snapshot names, see TemplateNameProvider.
+	NameTemplate string `toml:"name_template"`
+
+	Scrubbers []configScrubber `toml:"scrubbers"`
+}
+
+type configScrubber struct {
+	Pattern     string `toml:"pattern"`
+	Replacement string `toml:"replacement"`
+}
+
+// nolint: gochecknoglobals
+var (
+	configFs      = afero.NewOsFs()
+	configCacheMu sync.Mutex
+	configCache   = map[string][]Option{}
+)
+
+// getConfigOptions returns the options of the nearest .goldga.toml found by walking up from dir.
+func getConfigOptions(dir string) ([]Option, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	configCacheMu.Lock()
+	defer configCacheMu.Unlock()
+
+	if options, ok := configCache[dir]; ok {
+		return options, nil
+	}
+
+	options, err := loadConfigOptions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	configCache[dir] = options
+
+	return options, nil
+}
+
+func resetConfigCache() {
+	configCacheMu.Lock()
+	defer configCacheMu.Unlock()
+
+	configCache = map[string][]Option{}
+}
+
+func loadConfigOptions(dir string) ([]Option, error) {
+	for {
+		path := filepath.Join(dir, configFileName)
+
+		content, err := afero.ReadFile(configFs, path)
+		if err == nil {
+			var conf config
+
+			if _, err := toml.Decode(string(content), &conf); err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+			}
+
+			options, err := conf.options()
+			if err != nil {
+				return nil, fmt.Errorf("invalid config %s: %w", path, err)
+			}
+
+			return options, nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+
+		dir = parent
+	}
+}
+
+func (c *config) options() ([]Option, error) {
+	var options []Option
+
+	switch c.Storage {
+	case "", "suite":
+	case "dir":
+		options = append(options, WithDirStorage())
+	default:
+		return nil, fmt.Errorf("unknown storage %q", c.Storage)
+	}
+
+	switch format := SuiteFormat(c.SuiteFormat); format {
+	case "":
+	case SuiteFormatTOML, SuiteFormatJSON, SuiteFormatYAML:
+		options = append(options, WithSuiteFormat(format))
+	default:
+		return nil, fmt.Errorf("unknown suite format %q", c.SuiteFormat)
+	}
+
+	switch c.Serializer {
+	case "", "dump":
+	case "yaml":
+		options = append(options, WithSerializer(&YAMLSerializer{}))
+	case "json":
+		options = append(options, WithSerializer(&JSONSerializer{}))
+	case "toml":
+		options = append(options, WithSerializer(&TOMLSerializer{}))
+	case "string":
+		options = append(options, WithSerializer(&StringSerializer{}))
+	default:
+		return nil, fmt.Errorf("unknown serializer %q", c.Serializer)
+	}
+
+	switch c.Diff {
+	case "", "color":
+	case "unified":
+		if c.DiffContext < 0 {
+			return nil, fmt.Errorf("diff_context must not be negative: %d", c.DiffContext)
+		}
+
+		options = append(options, WithUnifiedDiff(c.DiffContext))
+	default:
+		return nil, fmt.Errorf("unknown diff %q", c.Diff)
+	}
+
+	if name := c.UpdateEnv; name != "" {
+		options = append(options, func(matcher *Matcher) {
+			if update, _ := strconv.ParseBool(os.Getenv(name)); update {
+				WithUpdatePolicy(UpdatePolicyAlways)(matcher)
+			}
+		})
+	}
+
+	if c.NameTemplate != "" {
+		provider, err := NewTemplateNameProvider(c.NameTemplate)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, WithNameProvider(provider))
+	}
+
+	for _, s := range c.Scrubbers {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrubber pattern: %w", err)
+		}
+
+		options = append(options, WithScrubber(re, s.Replacement))
+	}
+
+	return options, nil
+}
@@ -0,0 +1,146 @@
+package goldga
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Config", func() {
+	var (
+		fs      afero.Fs
+		root    string
+		matcher *Matcher
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		configFs = fs
+		root = filepath.FromSlash("/project")
+		resetConfigCache()
+	})
+
+	AfterEach(func() {
+		configFs = afero.NewOsFs()
+		resetConfigCache()
+	})
+
+	writeConfig := func(content string) {
+		Expect(afero.WriteFile(fs, filepath.Join(root, configFileName), []byte(content), os.ModePerm)).To(Succeed())
+	}
+
+	newTestMatcher := func(options ...Option) *Matcher {
+		return newMatcher(filepath.Join(root, "pkg", "testdata", "foo.golden"), "foo", options...)
+	}
+
+	When("config file does not exist", func() {
+		BeforeEach(func() {
+			matcher = newTestMatcher()
+		})
+
+		It("should use defaults", func() {
+			Expect(matcher.Serializer).To(Equal(DefaultSerializer))
+			Expect(matcher.Storage).To(BeAssignableToTypeOf(&SuiteStorage{}))
+		})
+	})
+
+	When("config file exists in a parent directory", func() {
+		BeforeEach(func() {
+			writeConfig(`
+storage = "dir"
+serializer = "json"
+diff = "unified"
+diff_context = 1
+name_template = "{{.LeafText | slug}}"
+
+[[scrubbers]]
+pattern = "id-\\d+"
+replacement = "<ID>"
+`)
+			matcher = newTestMatcher()
+		})
+
+		It("should set storage", func() {
+			Expect(matcher.Storage).To(BeAssignableToTypeOf(&DirStorage{}))
+		})
+
+		It("should set serializer", func() {
+			Expect(matcher.Serializer).To(Equal(&JSONSerializer{}))
+		})
+
+		It("should set differ", func() {
+			Expect(matcher.Differ).To(Equal(&UnifiedDiffer{Context: 1}))
+		})
+
+		It("should set name", func() {
+			Expect(matcher.Storage.(*DirStorage).Name).To(Equal("foo"))
+		})
+
+		It("should set scrubbers", func() {
+			Expect(matcher.normalize([]byte("id-123"))).To(Equal([]byte("<ID>")))
+		})
+	})
+
+	When("suite_format is set", func() {
+		BeforeEach(func() {
+			writeConfig(`suite_format = "yaml"`)
+			matcher = newTestMatcher()
+		})
+
+		It("should set suite format", func() {
+			Expect(matcher.Storage.(*SuiteStorage).Format).To(Equal(SuiteFormatYAML))
+		})
+	})
+
+	When("options are given", func() {
+		BeforeEach(func() {
+			writeConfig(`serializer = "json"`)
+			matcher = newTestMatcher(WithSerializer(&YAMLSerializer{}))
+		})
+
+		It("should override config", func() {
+			Expect(matcher.Serializer).To(Equal(&YAMLSerializer{}))
+		})
+	})
+
+	When("update_env is set", func() {
+		BeforeEach(func() {
+			writeConfig(`update_env = "GOLDGA_TEST_UPDATE"`)
+			Expect(os.Setenv("GOLDGA_TEST_UPDATE", "true")).To(Succeed())
+			matcher = newTestMatcher()
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("GOLDGA_TEST_UPDATE")).To(Succeed())
+		})
+
+		It("should enable update mode", func() {
+			Expect(matcher.UpdatePolicy).To(Equal(UpdatePolicyAlways))
+		})
+	})
+
+	When("config is invalid", func() {
+		BeforeEach(func() {
+			writeConfig(`serializer = "xml"`)
+		})
+
+		It("should fail the match", func() {
+			_, err := newTestMatcher().Match("foo")
+			Expect(err).To(MatchError(ContainSubstring(`unknown serializer "xml"`)))
+		})
+	})
+
+	When("diff_context is negative", func() {
+		BeforeEach(func() {
+			writeConfig("diff = \"unified\"\ndiff_context = -1")
+		})
+
+		It("should fail the match", func() {
+			_, err := newTestMatcher().Match("foo")
+			Expect(err).To(MatchError(ContainSubstring("diff_context must not be negative")))
+		})
+	})
+})
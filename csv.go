@@ -0,0 +1,267 @@
+package goldga
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const csvTagName = "csv"
+
+var _ Serializer = (*CSVSerializer)(nil)
+
+// CSVSerializer renders tabular data as CSV, or TSV with Comma set to '\t'. Input must be a
+// [][]string whose first row is the header, or a slice of structs or struct pointers whose
+// exported fields are the columns. Columns are named after the csv tag of fields if set, and
+// fields tagged with csv:"-" are skipped.
+type CSVSerializer struct {
+	// Comma is the field delimiter. Defaults to ','.
+	Comma rune
+	// Columns lists the columns to render in order. All columns are rendered if empty.
+	Columns []string
+	// ExcludeColumns lists columns which are not rendered.
+	ExcludeColumns []string
+	// SortBy lists the columns used to order rows. Rows are kept in input order if empty.
+	// Values which are both finite numbers are compared numerically.
+	SortBy []string
+}
+
+func (c *CSVSerializer) Serialize(w io.Writer, input interface{}) error {
+	header, rows, err := csvTable(input)
+	if err != nil {
+		return err
+	}
+
+	// An empty [][]string has no header to select columns from.
+	if header == nil {
+		return nil
+	}
+
+	if err := c.sortRows(header, rows); err != nil {
+		return err
+	}
+
+	indexes, err := c.columnIndexes(header)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if c.Comma != 0 {
+		cw.Comma = c.Comma
+	}
+
+	for _, row := range append([][]string{header}, rows...) {
+		record := make([]string, len(indexes))
+
+		for i, idx := range indexes {
+			if idx < len(row) {
+				record[i] = row[idx]
+			}
+		}
+
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("csv encode error: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("csv encode error: %w", err)
+	}
+
+	return nil
+}
+
+func (c *CSVSerializer) columnIndexes(header []string) ([]int, error) {
+	columns := c.Columns
+	if len(columns) == 0 {
+		columns = header
+	}
+
+	indexes := make([]int, 0, len(columns))
+
+	for _, name := range columns {
+		if indexOfString(c.ExcludeColumns, name) >= 0 {
+			continue
+		}
+
+		idx := indexOfString(header, name)
+		if idx < 0 {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+
+		indexes = append(indexes, idx)
+	}
+
+	return indexes, nil
+}
+
+func (c *CSVSerializer) sortRows(header []string, rows [][]string) error {
+	if len(c.SortBy) == 0 {
+		return nil
+	}
+
+	indexes := make([]int, len(c.SortBy))
+
+	for i, name := range c.SortBy {
+		if indexes[i] = indexOfString(header, name); indexes[i] < 0 {
+			return fmt.Errorf("unknown sort column %q", name)
+		}
+	}
+
+	cell := func(row []string, idx int) string {
+		if idx < len(row) {
+			return row[idx]
+		}
+
+		return ""
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, idx := range indexes {
+			if c := compareCSVValues(cell(rows[i], idx), cell(rows[j], idx)); c != 0 {
+				return c < 0
+			}
+		}
+
+		return false
+	})
+
+	return nil
+}
+
+// parseCSVNumber parses a finite number. Values such as "NaN" and "Inf" are compared as strings,
+// because NaN is not ordered.
+func parseCSVNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+
+	return f, true
+}
+
+func compareCSVValues(a, b string) int {
+	if x, ok := parseCSVNumber(a); ok {
+		if y, ok := parseCSVNumber(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(a, b)
+}
+
+// csvTable returns the header and rows of input.
+func csvTable(input interface{}) ([]string, [][]string, error) {
+	if table, ok := input.([][]string); ok {
+		if len(table) == 0 {
+			return nil, nil, nil
+		}
+
+		rows := make([][]string, len(table)-1)
+		copy(rows, table[1:])
+
+		return table[0], rows, nil
+	}
+
+	v := reflect.ValueOf(input)
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("unsupported CSV input type %T", input)
+	}
+
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("unsupported CSV input type %T", input)
+	}
+
+	var (
+		header []string
+		fields []int
+	)
+
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		name := strings.Split(field.Tag.Get(csvTagName), ",")[0]
+
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+
+	rows := make([][]string, v.Len())
+
+	for i := range rows {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				rows[i] = make([]string, len(fields))
+
+				continue
+			}
+
+			elem = elem.Elem()
+		}
+
+		row := make([]string, len(fields))
+
+		for j, idx := range fields {
+			row[j] = formatCSVValue(elem.Field(idx))
+		}
+
+		rows[i] = row
+	}
+
+	return header, rows, nil
+}
+
+func formatCSVValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+func indexOfString(values []string, s string) int {
+	for i, v := range values {
+		if v == s {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,102 @@
+package goldga
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type csvRecord struct {
+	ID     int     `csv:"id"`
+	Name   string  `csv:"name"`
+	Score  float64 `csv:"score"`
+	Secret string  `csv:"-"`
+	Note   *string
+	hidden string
+}
+
+var _ = Describe("CSVSerializer", func() {
+	serialize := func(s *CSVSerializer, input interface{}) (string, error) {
+		var buf bytes.Buffer
+		err := s.Serialize(&buf, input)
+
+		return buf.String(), err
+	}
+
+	note := "a, b"
+	records := []csvRecord{
+		{ID: 10, Name: "b", Score: 1.5, Secret: "x", Note: &note, hidden: "y"},
+		{ID: 9, Name: "a", Score: 2},
+	}
+
+	It("should render a slice of structs with a header", func() {
+		Expect(serialize(&CSVSerializer{}, records)).To(Equal(
+			"id,name,score,Note\n10,b,1.5,\"a, b\"\n9,a,2,\n"))
+	})
+
+	It("should render struct pointers", func() {
+		Expect(serialize(&CSVSerializer{}, []*csvRecord{&records[1], nil})).To(Equal(
+			"id,name,score,Note\n9,a,2,\n,,,\n"))
+	})
+
+	It("should render [][]string with the first row as header", func() {
+		input := [][]string{{"a", "b"}, {"1", "2"}}
+		Expect(serialize(&CSVSerializer{}, input)).To(Equal("a,b\n1,2\n"))
+	})
+
+	It("should render TSV", func() {
+		input := [][]string{{"a", "b"}, {"1", "2"}}
+		Expect(serialize(&CSVSerializer{Comma: '\t'}, input)).To(Equal("a\tb\n1\t2\n"))
+	})
+
+	It("should select columns", func() {
+		Expect(serialize(&CSVSerializer{Columns: []string{"name", "id"}}, records)).To(Equal(
+			"name,id\nb,10\na,9\n"))
+	})
+
+	It("should exclude columns", func() {
+		Expect(serialize(&CSVSerializer{ExcludeColumns: []string{"score", "Note"}}, records)).To(Equal(
+			"id,name\n10,b\n9,a\n"))
+	})
+
+	It("should sort numbers numerically", func() {
+		Expect(serialize(&CSVSerializer{Columns: []string{"id"}, SortBy: []string{"id"}}, records)).To(Equal(
+			"id\n9\n10\n"))
+	})
+
+	It("should sort by multiple columns", func() {
+		input := [][]string{{"k", "v"}, {"b", "1"}, {"a", "2"}, {"a", "1"}}
+		Expect(serialize(&CSVSerializer{SortBy: []string{"k", "v"}}, input)).To(Equal(
+			"k,v\na,1\na,2\nb,1\n"))
+		Expect(input[1]).To(Equal([]string{"b", "1"}))
+	})
+
+	It("should sort by excluded columns", func() {
+		Expect(serialize(&CSVSerializer{ExcludeColumns: []string{"id"}, SortBy: []string{"id"}}, records)).To(Equal(
+			"name,score,Note\na,2,\nb,1.5,\"a, b\"\n"))
+	})
+
+	It("should sort non-finite numbers as strings", func() {
+		input := [][]string{{"v"}, {"NaN"}, {"2"}, {"Inf"}, {"10"}, {"NaN"}, {"1"}}
+		Expect(serialize(&CSVSerializer{SortBy: []string{"v"}}, input)).To(Equal(
+			"v\n1\n2\n10\nInf\nNaN\nNaN\n"))
+	})
+
+	It("should render nothing for an empty table", func() {
+		Expect(serialize(&CSVSerializer{Columns: []string{"id"}, SortBy: []string{"id"}}, [][]string{})).To(BeEmpty())
+	})
+
+	It("should fail on unknown columns", func() {
+		_, err := serialize(&CSVSerializer{Columns: []string{"foo"}}, records)
+		Expect(err).To(MatchError(`unknown column "foo"`))
+
+		_, err = serialize(&CSVSerializer{SortBy: []string{"foo"}}, records)
+		Expect(err).To(MatchError(`unknown sort column "foo"`))
+	})
+
+	It("should fail on unsupported input", func() {
+		_, err := serialize(&CSVSerializer{}, []int{1})
+		Expect(err).To(MatchError("unsupported CSV input type []int"))
+	})
+})
@@ -0,0 +1,54 @@
+package goldga
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+)
+
+const debugEnv = "GOLDGA_DEBUG"
+
+// Logger receives debug messages. *log.Logger implements it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// nolint: gochecknoglobals
+var (
+	debugLoggerMu sync.RWMutex
+	debugLogger   = getDefaultDebugLogger()
+)
+
+// SetDebugLogger sets the logger tracing which golden files and snapshots are read and written,
+// suite cache hits, update decisions and lock acquisition. It defaults to a logger writing to
+// stderr when GOLDGA_DEBUG=1. Pass nil to disable tracing.
+func SetDebugLogger(logger Logger) {
+	debugLoggerMu.Lock()
+	defer debugLoggerMu.Unlock()
+
+	debugLogger = logger
+}
+
+func getDefaultDebugLogger() Logger {
+	if debug, _ := strconv.ParseBool(os.Getenv(debugEnv)); debug {
+		return log.New(os.Stderr, "goldga: ", log.Ltime|log.Lmicroseconds)
+	}
+
+	return nil
+}
+
+// debugf logs a message about the snapshot of the matcher.
+func (m *Matcher) debugf(format string, v ...interface{}) {
+	debugf("%q in %s: "+format, append([]interface{}{getStorageName(m.Storage), getStoragePath(m.Storage)}, v...)...)
+}
+
+func debugf(format string, v ...interface{}) {
+	debugLoggerMu.RLock()
+	logger := debugLogger
+	debugLoggerMu.RUnlock()
+
+	if logger != nil {
+		logger.Printf(format, v...)
+	}
+}
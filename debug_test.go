@@ -0,0 +1,80 @@
+package goldga
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type fakeLogger struct {
+	messages []string
+}
+
+func (f *fakeLogger) Printf(format string, v ...interface{}) {
+	f.messages = append(f.messages, fmt.Sprintf(format, v...))
+}
+
+var _ = Describe("SetDebugLogger", func() {
+	var (
+		logger *fakeLogger
+		suite  *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		logger = &fakeLogger{}
+		suite = &SuiteStorage{Path: "testdata/debug.golden", Fs: afero.NewMemMapFs()}
+		SetDebugLogger(logger)
+	})
+
+	AfterEach(func() {
+		SetDebugLogger(nil)
+	})
+
+	newDebugMatcher := func() *Matcher {
+		return newMatcher("foo", "foo",
+			WithStorage(suite.Named("foo")),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+		)
+	}
+
+	It("should trace reads, writes, locks and decisions", func() {
+		Expect(newDebugMatcher().Match("a")).To(BeTrue())
+		Expect(logger.messages).To(HaveLen(7))
+		Expect(logger.messages[4]).To(HavePrefix("acquired lock testdata/debug.golden.lock after "))
+
+		logger.messages[4] = "acquired lock"
+		Expect(logger.messages).To(Equal([]string{
+			`"foo" in testdata/debug.golden: match with update policy create`,
+			`snapshot "foo" not found, testdata/debug.golden does not exist`,
+			`write "foo" to testdata/debug.golden`,
+			"acquire lock testdata/debug.golden.lock",
+			"acquired lock",
+			"released lock testdata/debug.golden.lock",
+			`"foo" in testdata/debug.golden: created`,
+		}))
+	})
+
+	It("should trace cache hits and mismatches", func() {
+		Expect(suite.Named("foo").Write([]byte("a"))).To(Succeed())
+		_, err := suite.Named("foo").Read()
+		Expect(err).NotTo(HaveOccurred())
+
+		logger.messages = nil
+		Expect(newDebugMatcher().Match("b")).To(BeFalse())
+		Expect(logger.messages).To(Equal([]string{
+			`"foo" in testdata/debug.golden: match with update policy create`,
+			"suite cache hit testdata/debug.golden",
+			`read "foo" from testdata/debug.golden`,
+			`"foo" in testdata/debug.golden: mismatched`,
+		}))
+	})
+
+	It("should not log when disabled", func() {
+		SetDebugLogger(nil)
+		Expect(newDebugMatcher().Match("a")).To(BeTrue())
+		Expect(logger.messages).To(BeEmpty())
+	})
+})
@@ -1,19 +1,24 @@
 package goldga
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"strings"
 
 	"github.com/andreyvit/diff"
 	aurora "github.com/logrusorgru/aurora/v3"
 	isatty "github.com/mattn/go-isatty"
+	"github.com/spf13/afero"
 )
 
 // nolint: gochecknoglobals
 var (
 	DefaultDiffer Differ = &ColorDiffer{}
 
-	colorSupported = isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	colorSupported = os.Getenv("NO_COLOR") == "" &&
+		(isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()))
 )
 
 type Differ interface {
@@ -32,22 +37,191 @@ func (ColorDiffer) Diff(snapshot, received []byte) []byte {
 	}
 	lines = append(lines, diff.LineDiffAsLines(string(snapshot), string(received))...)
 
-	if colorSupported {
-		for i, line := range lines {
-			if len(line) == 0 {
-				continue
-			}
+	return []byte(strings.Join(colorizeDiffLines(lines), "\n"))
+}
 
-			switch line[0] {
-			case '+':
-				lines[i] = aurora.BrightGreen(line).String()
-			case '-':
-				lines[i] = aurora.BrightRed(line).String()
-			default:
-				lines[i] = aurora.BrightBlack(line).String()
-			}
-		}
+var _ Differ = (*UnifiedDiffer)(nil)
+
+// UnifiedDiffer renders a unified diff which only shows changed lines and the unchanged lines
+// around them.
+type UnifiedDiffer struct {
+	// Context is the number of unchanged lines shown around changes. Negative values are treated
+	// as 0.
+	Context int
+	// DisableColor disables colors even if the terminal supports them.
+	DisableColor bool
+}
+
+func (u *UnifiedDiffer) Diff(snapshot, received []byte) []byte {
+	lines := []string{
+		"--- Snapshot",
+		"+++ Received",
+	}
+
+	for _, hunk := range buildDiffHunks(diff.LineDiffAsLines(string(snapshot), string(received)), u.Context) {
+		lines = append(lines, fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines))
+		lines = append(lines, hunk.Lines...)
+	}
+
+	if !u.DisableColor {
+		lines = colorizeDiffLines(lines)
 	}
 
 	return []byte(strings.Join(lines, "\n"))
 }
+
+func colorizeDiffLines(lines []string) []string {
+	if !colorSupported {
+		return lines
+	}
+
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		switch line[0] {
+		case '@':
+			lines[i] = aurora.Cyan(line).String()
+		case '+':
+			lines[i] = aurora.BrightGreen(line).String()
+		case '-':
+			lines[i] = aurora.BrightRed(line).String()
+		default:
+			lines[i] = aurora.BrightBlack(line).String()
+		}
+	}
+
+	return lines
+}
+
+const diffContextLines = 3
+
+// DiffResult is the structured result of comparing a value against a storage.
+type DiffResult struct {
+	// Match is true when the stored value is equal to the actual value.
+	Match bool
+	// Created is true when the storage did not contain a value yet.
+	Created bool
+	Hunks   []DiffHunk
+	Stats   DiffStats
+}
+
+// DiffHunk is a group of changed lines with surrounding context, similar to a unified diff hunk.
+// Lines are prefixed with " ", "-" or "+". Line numbers start at 1.
+type DiffHunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []string
+}
+
+type DiffStats struct {
+	Added   int
+	Removed int
+}
+
+// StorageDiff compares actual against the value in the storage without writing anything.
+func StorageDiff(s Storage, actual []byte) (*DiffResult, error) {
+	result := new(DiffResult)
+
+	expected, err := s.Read()
+	if err != nil {
+		if !errors.Is(err, afero.ErrFileNotFound) {
+			return nil, fmt.Errorf("failed to read storage: %w", err)
+		}
+
+		result.Created = true
+	}
+
+	if !result.Created && bytes.Equal(expected, actual) {
+		result.Match = true
+
+		return result, nil
+	}
+
+	var lines []string
+
+	if result.Created {
+		for _, line := range strings.Split(string(actual), "\n") {
+			lines = append(lines, "+"+line)
+		}
+	} else {
+		lines = diff.LineDiffAsLines(string(expected), string(actual))
+	}
+
+	result.Hunks = buildDiffHunks(lines, diffContextLines)
+
+	for _, line := range lines {
+		switch line[0] {
+		case '+':
+			result.Stats.Added++
+		case '-':
+			result.Stats.Removed++
+		}
+	}
+
+	return result, nil
+}
+
+func buildDiffHunks(lines []string, context int) []DiffHunk {
+	var (
+		hunks   []DiffHunk
+		changed []int
+	)
+
+	if context < 0 {
+		context = 0
+	}
+
+	for i, line := range lines {
+		if line[0] != ' ' {
+			changed = append(changed, i)
+		}
+	}
+
+	// oldLines[i] and newLines[i] are the numbers of lines preceding lines[i].
+	oldLines := make([]int, len(lines)+1)
+	newLines := make([]int, len(lines)+1)
+
+	for i, line := range lines {
+		oldLines[i+1], newLines[i+1] = oldLines[i], newLines[i]
+
+		if line[0] != '+' {
+			oldLines[i+1]++
+		}
+
+		if line[0] != '-' {
+			newLines[i+1]++
+		}
+	}
+
+	for len(changed) > 0 {
+		start := changed[0] - context
+		if start < 0 {
+			start = 0
+		}
+
+		last := 0
+		for last+1 < len(changed) && changed[last+1]-changed[last] <= 2*context+1 {
+			last++
+		}
+
+		end := changed[last] + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		hunks = append(hunks, DiffHunk{
+			OldStart: oldLines[start] + 1,
+			OldLines: oldLines[end] - oldLines[start],
+			NewStart: newLines[start] + 1,
+			NewLines: newLines[end] - newLines[start],
+			Lines:    lines[start:end],
+		})
+		changed = changed[last+1:]
+	}
+
+	return hunks
+}
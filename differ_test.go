@@ -0,0 +1,129 @@
+package goldga
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("StorageDiff", func() {
+	var (
+		mockCtrl *gomock.Controller
+		storage  *MockStorage
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		storage = NewMockStorage(mockCtrl)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	lines := func(from, to int) string {
+		var s []string
+
+		for i := from; i <= to; i++ {
+			s = append(s, strings.Repeat("x", i))
+		}
+
+		return strings.Join(s, "\n")
+	}
+
+	It("should match", func() {
+		storage.EXPECT().Read().Return([]byte("a\nb"), nil)
+		Expect(StorageDiff(storage, []byte("a\nb"))).To(Equal(&DiffResult{Match: true}))
+	})
+
+	It("should report created", func() {
+		storage.EXPECT().Read().Return(nil, afero.ErrFileNotFound)
+		result, err := StorageDiff(storage, []byte("a\nb"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Match).To(BeFalse())
+		Expect(result.Created).To(BeTrue())
+		Expect(result.Stats).To(Equal(DiffStats{Added: 2}))
+		Expect(result.Hunks).To(Equal([]DiffHunk{
+			{OldStart: 1, NewStart: 1, NewLines: 2, Lines: []string{"+a", "+b"}},
+		}))
+	})
+
+	It("should return hunks", func() {
+		expected := lines(1, 20)
+		actual := strings.Replace(expected, "xx\n", "changed\n", 1)
+		actual = strings.Replace(actual, strings.Repeat("x", 15)+"\n", "", 1)
+		storage.EXPECT().Read().Return([]byte(expected), nil)
+
+		result, err := StorageDiff(storage, []byte(actual))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Match).To(BeFalse())
+		Expect(result.Created).To(BeFalse())
+		Expect(result.Stats).To(Equal(DiffStats{Added: 1, Removed: 2}))
+		Expect(result.Hunks).To(Equal([]DiffHunk{
+			{
+				OldStart: 1, OldLines: 5, NewStart: 1, NewLines: 5,
+				Lines: []string{" x", "-xx", "+changed", " xxx", " xxxx", " xxxxx"},
+			},
+			{
+				OldStart: 12, OldLines: 7, NewStart: 12, NewLines: 6,
+				Lines: []string{
+					" " + strings.Repeat("x", 12),
+					" " + strings.Repeat("x", 13),
+					" " + strings.Repeat("x", 14),
+					"-" + strings.Repeat("x", 15),
+					" " + strings.Repeat("x", 16),
+					" " + strings.Repeat("x", 17),
+					" " + strings.Repeat("x", 18),
+				},
+			},
+		}))
+	})
+
+	It("should return error when failed to read", func() {
+		storage.EXPECT().Read().Return(nil, errors.New("error"))
+		_, err := StorageDiff(storage, []byte("a"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("UnifiedDiffer", func() {
+	snapshot := []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj")
+	received := []byte("a\nB\nc\nd\ne\nf\ng\nh\ni\nJ")
+
+	It("should show changes with context", func() {
+		differ := &UnifiedDiffer{Context: 1, DisableColor: true}
+		Expect(string(differ.Diff(snapshot, received))).To(Equal(`--- Snapshot
++++ Received
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+@@ -9,2 +9,2 @@
+ i
+-j
++J`))
+	})
+
+	It("should merge hunks when context overlaps", func() {
+		differ := &UnifiedDiffer{Context: 4, DisableColor: true}
+		Expect(string(differ.Diff(snapshot, received))).To(HavePrefix(`--- Snapshot
++++ Received
+@@ -1,10 +1,10 @@
+`))
+	})
+
+	It("should treat negative context as 0", func() {
+		differ := &UnifiedDiffer{Context: -1, DisableColor: true}
+		Expect(string(differ.Diff(snapshot, received))).To(Equal(string((&UnifiedDiffer{DisableColor: true}).Diff(snapshot, received))))
+	})
+
+	It("should show nothing when equal", func() {
+		differ := &UnifiedDiffer{DisableColor: true}
+		Expect(string(differ.Diff(snapshot, snapshot))).To(Equal("--- Snapshot\n+++ Received"))
+	})
+})
@@ -0,0 +1,148 @@
+package goldga
+
+import (
+	"crypto/sha1" // nolint: gosec
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	goldenExt           = ".golden"
+	maxFileNameLength   = 120
+	fileNameHashLength  = 8
+	fileNameSafeSymbols = "._-"
+	fileNameReplacement = '_'
+)
+
+var (
+	_ NamedStorage     = (*DirStorage)(nil)
+	_ DeletableStorage = (*DirStorage)(nil)
+)
+
+// DirStorage stores each snapshot in its own file under Dir. File names are derived from
+// snapshot names by sanitizeFileName, and names which differ from their file name are kept in a
+// ".names.toml" file, so Names returns the original names.
+type DirStorage struct {
+	Dir  string
+	Name string
+	Fs   afero.Fs
+
+	// Variant is inserted before the file extension, see Variant.
+	Variant string
+
+	// KeepPrevious keeps the previous content of overwritten snapshots in ".orig" files, see
+	// WithPreviousVersions.
+	KeepPrevious bool
+
+	// exact disables the fallback to less specific variants, see exactStorage.
+	exact bool
+}
+
+func (d *DirStorage) Named(name string) Storage {
+	named := *d
+	named.Name = name
+
+	return &named
+}
+
+func (d *DirStorage) fileName() string {
+	return sanitizeFileName(d.Name) + goldenExt
+}
+
+func (d *DirStorage) single() *SingleStorage {
+	return &SingleStorage{
+		Path:         filepath.Join(d.Dir, d.fileName()),
+		Fs:           d.Fs,
+		Locale:       sanitizeFileName(d.Variant),
+		KeepPrevious: d.KeepPrevious,
+		exact:        d.exact,
+	}
+}
+
+func (d *DirStorage) Read() ([]byte, error) {
+	return d.single().Read()
+}
+
+func (d *DirStorage) Write(data []byte) error {
+	if err := d.single().Write(data); err != nil {
+		return err
+	}
+
+	return d.recordName()
+}
+
+func (d *DirStorage) Delete() error {
+	if err := d.single().Delete(); err != nil {
+		return err
+	}
+
+	// Other variants may still use the name.
+	if d.Variant != "" {
+		return nil
+	}
+
+	return d.removeNames(strings.TrimSuffix(d.fileName(), goldenExt))
+}
+
+// sanitizeFileName converts a snapshot name into a file name. Spaces and unsafe characters are
+// replaced with underscores. A hash of the name is appended if any character other than spaces
+// was replaced, the name contains underscores, which are indistinguishable from replaced spaces,
+// or the name is too long, so different names do not share the same file.
+func sanitizeFileName(name string) string {
+	var (
+		sb    strings.Builder
+		lossy bool
+	)
+
+	for _, r := range name {
+		switch {
+		case r == fileNameReplacement:
+			sb.WriteRune(r)
+
+			lossy = true
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune(fileNameSafeSymbols, r):
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteRune(fileNameReplacement)
+		default:
+			sb.WriteRune(fileNameReplacement)
+
+			lossy = true
+		}
+	}
+
+	result := sb.String()
+
+	if len(result) > maxFileNameLength {
+		result = result[:maxFileNameLength]
+		lossy = true
+	}
+
+	if lossy {
+		sum := sha1.Sum([]byte(name)) // nolint: gosec
+		result += "-" + hex.EncodeToString(sum[:])[:fileNameHashLength]
+	}
+
+	return result
+}
+
+// WithDirStorage stores each snapshot in its own file under "testdata/<test file>/" instead of
+// a single suite file, which avoids merge conflicts when different tests are updated.
+func WithDirStorage() Option {
+	return func(matcher *Matcher) {
+		matcher.Storage = mapStorage(matcher.Storage, func(inner Storage) Storage {
+			if s, ok := inner.(*SuiteStorage); ok {
+				return &DirStorage{
+					Dir:  strings.TrimSuffix(s.Path, goldenExt),
+					Name: s.Name,
+					Fs:   s.Fs,
+				}
+			}
+
+			return inner
+		})
+	}
+}
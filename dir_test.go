@@ -0,0 +1,109 @@
+package goldga
+
+import (
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("sanitizeFileName", func() {
+	DescribeTable("file names", func(name, expected string) {
+		Expect(sanitizeFileName(name)).To(Equal(expected))
+	},
+		Entry("safe", "foo-bar.1.2", "foo-bar.1.2"),
+		Entry("underscores", "foo_bar", "foo_bar-5d5f20e7"),
+		Entry("spaces", "Examples map works", "Examples_map_works"),
+		Entry("unsafe characters", "a/b: c", "a_b__c-fb204b01"),
+	)
+
+	It("should not share file names between different names", func() {
+		Expect(sanitizeFileName("a/b")).NotTo(Equal(sanitizeFileName("a:b")))
+		Expect(sanitizeFileName("a b")).NotTo(Equal(sanitizeFileName("a_b")))
+	})
+
+	It("should limit the length", func() {
+		name := sanitizeFileName(strings.Repeat("a", 500))
+		Expect(name).To(HaveLen(maxFileNameLength + 1 + fileNameHashLength))
+	})
+})
+
+var _ = Describe("DirStorage", func() {
+	var (
+		fs      afero.Fs
+		storage *DirStorage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		storage = &DirStorage{
+			Dir:  filepath.Join("testdata", "dir"),
+			Name: "Suite test",
+			Fs:   fs,
+		}
+	})
+
+	AfterEach(func() {
+		resetSnapshotUsages()
+	})
+
+	It("should write each snapshot to its own file", func() {
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(storage.Named("other").Write([]byte("bar"))).To(Succeed())
+		Expect(afero.ReadFile(fs, filepath.Join("testdata", "dir", "Suite_test.golden"))).To(Equal([]byte("foo")))
+		Expect(afero.ReadFile(fs, filepath.Join("testdata", "dir", "other.golden"))).To(Equal([]byte("bar")))
+	})
+
+	It("should read the snapshot", func() {
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should delete the snapshot", func() {
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(storage.Delete()).To(Succeed())
+		Expect(afero.Exists(fs, filepath.Join("testdata", "dir", "Suite_test.golden"))).To(BeFalse())
+	})
+
+	It("should store names differing in spaces and underscores in different files", func() {
+		Expect(storage.Named("a b").Write([]byte("space"))).To(Succeed())
+		Expect(storage.Named("a_b").Write([]byte("underscore"))).To(Succeed())
+		Expect(storage.Named("a b").Read()).To(Equal([]byte("space")))
+		Expect(storage.Named("a_b").Read()).To(Equal([]byte("underscore")))
+	})
+
+	It("should prune files of unused snapshots", func() {
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(storage.Named("other").Write([]byte("bar"))).To(Succeed())
+		resetSnapshotUsages()
+		isPartialRun = func() bool { return false }
+		defer func() { isPartialRun = detectPartialRun }()
+
+		recordMatchUsage(storage)
+		Expect(PruneSnapshots()).To(Equal(map[string][]string{
+			filepath.Join("testdata", "dir"): {"other.golden"},
+		}))
+		Expect(afero.Exists(fs, filepath.Join("testdata", "dir", "other.golden"))).To(BeFalse())
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+})
+
+var _ = Describe("WithDirStorage", func() {
+	It("should replace SuiteStorage with DirStorage", func() {
+		fs := afero.NewMemMapFs()
+		matcher := &Matcher{Storage: &SuiteStorage{
+			Path: filepath.Join("testdata", "foo.golden"),
+			Name: "bar",
+			Fs:   fs,
+		}}
+		WithDirStorage()(matcher)
+		Expect(matcher.Storage).To(Equal(&DirStorage{
+			Dir:  filepath.Join("testdata", "foo"),
+			Name: "bar",
+			Fs:   fs,
+		}))
+	})
+})
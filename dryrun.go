@@ -0,0 +1,133 @@
+package goldga
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+const updateModeDryRun = "dry-run"
+
+var errGoldenFileWouldBeCreated = errors.New("golden file does not exist and would be created")
+
+// DryRunAction is what an update would do to a snapshot.
+type DryRunAction string
+
+const (
+	DryRunCreate DryRunAction = "create"
+	DryRunModify DryRunAction = "modify"
+	DryRunDelete DryRunAction = "delete"
+)
+
+// DryRunChange is a snapshot which an update would change.
+type DryRunChange struct {
+	Action DryRunAction `json:"action"`
+	Path   string       `json:"path"`
+	Name   string       `json:"name"`
+}
+
+// nolint: gochecknoglobals
+var (
+	dryRunMu      sync.Mutex
+	dryRunChanges = map[DryRunChange]struct{}{}
+)
+
+// WithDryRun compares snapshots without writing any file, and records the snapshots an update
+// would create or modify, which are returned by DryRunChanges. Missing and mismatched snapshots
+// fail the match. It is enabled when GOLDGA_UPDATE is set to "dry-run". Nothing is printed
+// automatically, call WriteDryRun or WriteDryRunJSON once all tests ran, e.g. in AfterSuite.
+func WithDryRun() Option {
+	return func(matcher *Matcher) {
+		matcher.DryRun = true
+	}
+}
+
+func recordDryRunChange(storage Storage, action DryRunAction) {
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+
+	dryRunChanges[DryRunChange{
+		Action: action,
+		Path:   getStoragePath(storage),
+		Name:   getStorageName(storage),
+	}] = struct{}{}
+}
+
+func resetDryRunChanges() {
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+
+	dryRunChanges = map[DryRunChange]struct{}{}
+}
+
+// DryRunChanges returns the snapshots an update would create or modify in this run, and the
+// snapshots returned by OrphanedSnapshots, which PruneSnapshots would delete. Changes are sorted
+// by path and name. Call it after all tests are done, e.g. in Ginkgo's AfterSuite.
+func DryRunChanges() ([]DryRunChange, error) {
+	orphans, err := OrphanedSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	dryRunMu.Lock()
+	changes := make([]DryRunChange, 0, len(dryRunChanges))
+
+	for change := range dryRunChanges {
+		changes = append(changes, change)
+	}
+	dryRunMu.Unlock()
+
+	for path, names := range orphans {
+		for _, name := range names {
+			changes = append(changes, DryRunChange{Action: DryRunDelete, Path: path, Name: name})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		return a.Name < b.Name
+	})
+
+	return changes, nil
+}
+
+// WriteDryRun writes a line for each change returned by DryRunChanges.
+func WriteDryRun(w io.Writer) error {
+	changes, err := DryRunChanges()
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if _, err := fmt.Fprintf(w, "%s %s: %s\n", change.Action, change.Path, change.Name); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteDryRunJSON writes the changes returned by DryRunChanges as a JSON manifest.
+func WriteDryRunJSON(w io.Writer) error {
+	changes, err := DryRunChanges()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(changes); err != nil {
+		return fmt.Errorf("json encode error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package goldga
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("WithDryRun", func() {
+	var (
+		fs    afero.Fs
+		suite *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		suite = &SuiteStorage{Path: "dryrun.golden", Fs: fs}
+
+		for _, name := range []string{"same", "changed", "orphan"} {
+			Expect(suite.Named(name).Write([]byte(name))).To(Succeed())
+		}
+
+		resetSnapshotUsages()
+		resetDryRunChanges()
+		resetStats()
+		resetFailures()
+	})
+
+	AfterEach(func() {
+		resetSnapshotUsages()
+		resetDryRunChanges()
+		resetStats()
+		resetFailures()
+	})
+
+	newDryRunMatcher := func(name string) *Matcher {
+		return newMatcher("foo", name,
+			WithStorage(suite.Named(name)),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyAlways),
+			WithDryRun(),
+		)
+	}
+
+	It("should record changes without writing files", func() {
+		before := mustReadFile(fs, "dryrun.golden")
+
+		Expect(newDryRunMatcher("same").Match("same")).To(BeTrue())
+		Expect(newDryRunMatcher("changed").Match("new")).To(BeFalse())
+
+		_, err := newDryRunMatcher("new").Match("new")
+		Expect(err).To(MatchError(errGoldenFileWouldBeCreated))
+
+		Expect(mustReadFile(fs, "dryrun.golden")).To(Equal(before))
+		Expect(DryRunChanges()).To(Equal([]DryRunChange{
+			{Action: DryRunModify, Path: "dryrun.golden", Name: "changed"},
+			{Action: DryRunCreate, Path: "dryrun.golden", Name: "new"},
+			{Action: DryRunDelete, Path: "dryrun.golden", Name: "orphan"},
+		}))
+	})
+
+	It("should write the changes", func() {
+		Expect(newDryRunMatcher("changed").Match("new")).To(BeFalse())
+
+		var buf bytes.Buffer
+		Expect(WriteDryRun(&buf)).To(Succeed())
+		Expect(buf.String()).To(Equal("modify dryrun.golden: changed\ndelete dryrun.golden: orphan\ndelete dryrun.golden: same\n"))
+	})
+
+	It("should write a JSON manifest", func() {
+		Expect(newDryRunMatcher("same").Match("same")).To(BeTrue())
+		Expect(newDryRunMatcher("changed").Match("new")).To(BeFalse())
+
+		var buf bytes.Buffer
+		Expect(WriteDryRunJSON(&buf)).To(Succeed())
+		Expect(buf.String()).To(MatchJSON(`[
+			{"action": "modify", "path": "dryrun.golden", "name": "changed"},
+			{"action": "delete", "path": "dryrun.golden", "name": "orphan"}
+		]`))
+	})
+})
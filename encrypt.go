@@ -0,0 +1,208 @@
+package goldga
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const encryptedPrefix = "goldga:aes-gcm:"
+
+// ErrEncryptionKeyMissing is returned when the environment variable holding an encryption key is
+// not set.
+var ErrEncryptionKeyMissing = errors.New("encryption key is not set")
+
+// nolint: gochecknoglobals
+var encryptionRand io.Reader = rand.Reader
+
+// KeyFunc returns the key used to encrypt snapshots. It can fetch the key from a KMS.
+type KeyFunc func() ([]byte, error)
+
+// EncryptionKeyFromEnv returns a KeyFunc reading a base64 encoded AES key from the environment
+// variable name.
+func EncryptionKeyFromEnv(name string) KeyFunc {
+	return func() ([]byte, error) {
+		value := os.Getenv(name)
+		if value == "" {
+			return nil, fmt.Errorf("%w: %s", ErrEncryptionKeyMissing, name)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode encryption key %s: %w", name, err)
+		}
+
+		return key, nil
+	}
+}
+
+// CacheKey returns a KeyFunc which calls key once and returns the same key afterwards, so a key
+// fetched from a KMS is fetched once per run. Errors are not cached.
+//
+//	var snapshotKey = goldga.CacheKey(fetchKeyFromKMS)
+func CacheKey(key KeyFunc) KeyFunc {
+	var (
+		mu     sync.Mutex
+		cached []byte
+	)
+
+	return func() ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if cached != nil {
+			return cached, nil
+		}
+
+		k, err := key()
+		if err != nil {
+			return nil, err
+		}
+
+		cached = k
+
+		return k, nil
+	}
+}
+
+var _ NamedStorage = (*EncryptedStorage)(nil)
+
+// EncryptedStorage encrypts snapshots with AES-GCM before writing them to the inner storage.
+// Encrypted snapshots are stored as a single base64 line, so suite files stay valid TOML and
+// snapshot names remain readable. The snapshot name is authenticated as additional data, so an
+// encrypted snapshot cannot be moved to another name. Unencrypted snapshots are still read as is.
+type EncryptedStorage struct {
+	Inner Storage
+
+	// Key returns a 16, 24 or 32 byte AES key.
+	Key KeyFunc
+}
+
+// WithEncryption encrypts snapshots with the key returned by key. The key is fetched at most once
+// per matcher, wrap key with CacheKey to share it between matchers.
+//
+//	goldga.Match(goldga.WithEncryption(goldga.EncryptionKeyFromEnv("GOLDGA_KEY")))
+func WithEncryption(key KeyFunc) Option {
+	return func(matcher *Matcher) {
+		matcher.Storage = &EncryptedStorage{Inner: matcher.Storage, Key: CacheKey(key)}
+	}
+}
+
+// Named returns an EncryptedStorage for the named snapshot. It returns e if Inner is not a
+// NamedStorage.
+func (e *EncryptedStorage) Named(name string) Storage {
+	return namedInnerStorage(e, name)
+}
+
+func (e *EncryptedStorage) Unwrap() Storage {
+	return e.Inner
+}
+
+func (e *EncryptedStorage) Wrap(inner Storage) Storage {
+	wrapped := *e
+	wrapped.Inner = inner
+
+	return &wrapped
+}
+
+// isEncrypted reports whether storage is or wraps an EncryptedStorage, whose content must not be
+// written anywhere in plain text.
+func isEncrypted(storage Storage) bool {
+	for {
+		switch s := storage.(type) {
+		case *EncryptedStorage:
+			return true
+		case StorageWrapper:
+			storage = s.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+func (e *EncryptedStorage) Read() ([]byte, error) {
+	data, err := e.Inner.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, []byte(encryptedPrefix)) {
+		return data, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data[len(encryptedPrefix):])))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	aead, err := e.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted snapshot is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, e.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func (e *EncryptedStorage) Write(data []byte) error {
+	aead, err := e.cipher()
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := io.ReadFull(encryptionRand, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, data, e.additionalData())
+	encoded := encryptedPrefix + base64.StdEncoding.EncodeToString(sealed) + "\n"
+
+	return e.Inner.Write([]byte(encoded))
+}
+
+// additionalData returns the snapshot name, which binds the ciphertext to the snapshot.
+func (e *EncryptedStorage) additionalData() []byte {
+	return []byte(getStorageName(e.Inner))
+}
+
+func (e *EncryptedStorage) cipher() (cipher.AEAD, error) {
+	if e.Key == nil {
+		return nil, ErrEncryptionKeyMissing
+	}
+
+	key, err := e.Key()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,158 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("EncryptedStorage", func() {
+	var (
+		fs      afero.Fs
+		key     []byte
+		storage *EncryptedStorage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		key = bytes.Repeat([]byte{1}, 32)
+		storage = &EncryptedStorage{
+			Inner: &SuiteStorage{Path: "foo.golden", Fs: fs},
+			Key:   func() ([]byte, error) { return key, nil },
+		}
+	})
+
+	It("should encrypt on write", func() {
+		named := storage.Named("secret")
+		Expect(named.Write([]byte("customer data"))).To(Succeed())
+
+		content := string(mustReadFile(fs, "foo.golden"))
+		Expect(content).To(ContainSubstring(`"secret" = '''`))
+		Expect(content).To(ContainSubstring(encryptedPrefix))
+		Expect(content).NotTo(ContainSubstring("customer data"))
+		Expect(named.Read()).To(Equal([]byte("customer data")))
+	})
+
+	It("should use a random nonce", func() {
+		inner := &SingleStorage{Path: "foo.golden", Fs: fs}
+		storage.Inner = inner
+
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		first, err := inner.Read()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(inner.Read()).NotTo(Equal(first))
+	})
+
+	It("should read unencrypted snapshots", func() {
+		Expect(storage.Inner.(NamedStorage).Named("foo").Write([]byte("plain"))).To(Succeed())
+		Expect(storage.Named("foo").Read()).To(Equal([]byte("plain")))
+	})
+
+	It("should return error if the key is wrong", func() {
+		named := storage.Named("foo")
+		Expect(named.Write([]byte("foo"))).To(Succeed())
+
+		key = bytes.Repeat([]byte{2}, 32)
+		_, err := named.Read()
+		Expect(err).To(MatchError(ContainSubstring("failed to decrypt snapshot")))
+	})
+
+	It("should not decrypt snapshots moved to another name", func() {
+		Expect(storage.Named("a").Write([]byte("foo"))).To(Succeed())
+
+		encrypted, err := storage.Inner.(NamedStorage).Named("a").Read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storage.Inner.(NamedStorage).Named("b").Write(encrypted)).To(Succeed())
+
+		_, err = storage.Named("b").Read()
+		Expect(err).To(MatchError(ContainSubstring("failed to decrypt snapshot")))
+	})
+
+	It("should return error if the key func fails", func() {
+		keyErr := errors.New("kms unavailable")
+		storage.Key = func() ([]byte, error) { return nil, keyErr }
+		Expect(storage.Named("foo").Write([]byte("foo"))).To(MatchError(keyErr))
+	})
+
+	It("should return error if the key is not set", func() {
+		storage.Key = nil
+		Expect(storage.Named("foo").Write([]byte("foo"))).To(MatchError(ErrEncryptionKeyMissing))
+	})
+
+	It("should return itself if the inner storage does not support names", func() {
+		storage.Inner = &SingleStorage{Path: "foo.golden", Fs: fs}
+		Expect(storage.Named("bar")).To(BeIdenticalTo(storage))
+	})
+})
+
+var _ = Describe("EncryptionKeyFromEnv", func() {
+	const env = "GOLDGA_TEST_KEY"
+
+	AfterEach(func() {
+		os.Unsetenv(env)
+	})
+
+	It("should decode the key", func() {
+		os.Setenv(env, base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+		Expect(EncryptionKeyFromEnv(env)()).To(Equal([]byte("0123456789abcdef")))
+	})
+
+	It("should return error if the variable is not set", func() {
+		_, err := EncryptionKeyFromEnv(env)()
+		Expect(err).To(MatchError(ErrEncryptionKeyMissing))
+	})
+
+	It("should return error if the key is not base64", func() {
+		os.Setenv(env, "!")
+		_, err := EncryptionKeyFromEnv(env)()
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("CacheKey", func() {
+	It("should call the key func once", func() {
+		calls := 0
+		key := CacheKey(func() ([]byte, error) {
+			calls++
+
+			return []byte("key"), nil
+		})
+
+		Expect(key()).To(Equal([]byte("key")))
+		Expect(key()).To(Equal([]byte("key")))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should not cache errors", func() {
+		calls := 0
+		key := CacheKey(func() ([]byte, error) {
+			calls++
+
+			if calls == 1 {
+				return nil, errors.New("kms unavailable")
+			}
+
+			return []byte("key"), nil
+		})
+
+		_, err := key()
+		Expect(err).To(HaveOccurred())
+		Expect(key()).To(Equal([]byte("key")))
+	})
+})
+
+var _ = Describe("WithEncryption", func() {
+	It("should wrap the storage", func() {
+		key := EncryptionKeyFromEnv("GOLDGA_TEST_KEY")
+		m := newMatcher("testdata/foo.golden", "foo", WithEncryption(key))
+		Expect(m.Storage).To(BeAssignableToTypeOf(&EncryptedStorage{}))
+		Expect(m.Storage.(*EncryptedStorage).Inner).To(BeAssignableToTypeOf(&SuiteStorage{}))
+	})
+})
@@ -32,9 +32,22 @@ var _ = Describe("Examples", func() {
 		}).To(goldga.Match())
 	})
 
+	It("yaml", func() {
+		Expect(map[string]interface{}{
+			"name": "example",
+			"tags": []string{"a", "b"},
+		}).To(goldga.Match(goldga.WithSerializer(&goldga.YAMLSerializer{})))
+	})
+
 	It("multiple gold files in the same test", func() {
 		Expect("foo").To(goldga.Match(goldga.WithDescription("first gold file")))
 		Expect("bar").To(goldga.Match(goldga.WithDescription("second gold file")))
 		Expect("foobar").To(goldga.Match(goldga.WithDescription("third gold file")))
 	})
 })
+
+func TestPlain(t *testing.T) {
+	g := goldga.New(t)
+	g.Assert(map[string]int{"a": 1, "b": 2})
+	g.AssertString("plain string", goldga.WithDescription("string"))
+}
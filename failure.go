@@ -0,0 +1,59 @@
+package goldga
+
+import "fmt"
+
+// FailureInfo describes a mismatch for a FailureFormatter.
+type FailureInfo struct {
+	// Negated is true for NegatedFailureMessage, i.e. when the content was expected not to match.
+	Negated bool
+	// Name is the snapshot name.
+	Name string
+	// Path is the golden file, or directory for DirStorage.
+	Path string
+	// Diff is the output of the Differ.
+	Diff []byte
+	// ReceivedPath is the file the actual content was written to, see WithReceivedFile.
+	ReceivedPath string
+}
+
+// DefaultMessage returns the message displayed without a FailureFormatter.
+func (f FailureInfo) DefaultMessage() string {
+	message := "to"
+	if f.Negated {
+		message = "not to"
+	}
+
+	msg := fmt.Sprintf("Expected %s match the golden file\n%s", message, f.Diff)
+
+	if f.ReceivedPath != "" {
+		msg += "\nReceived content written to " + f.ReceivedPath
+	}
+
+	return msg
+}
+
+// FailureFormatter builds the failure message displayed by Gomega on mismatch.
+type FailureFormatter interface {
+	FormatFailure(info FailureInfo) string
+}
+
+var _ FailureFormatter = FailureFormatterFunc(nil)
+
+// FailureFormatterFunc is a function implementing FailureFormatter.
+type FailureFormatterFunc func(info FailureInfo) string
+
+func (f FailureFormatterFunc) FormatFailure(info FailureInfo) string {
+	return f(info)
+}
+
+// WithFailureFormatter overrides the failure message, e.g. to add instructions for updating
+// golden files.
+//
+//	goldga.WithFailureFormatter(goldga.FailureFormatterFunc(func(info goldga.FailureInfo) string {
+//		return info.DefaultMessage() + "\nRun UPDATE_GOLDEN=1 go test ./... to update " + info.Path
+//	}))
+func WithFailureFormatter(formatter FailureFormatter) Option {
+	return func(matcher *Matcher) {
+		matcher.FailureFormatter = formatter
+	}
+}
@@ -0,0 +1,59 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("FailureInfo", func() {
+	It("should build the default message", func() {
+		info := FailureInfo{Diff: []byte("diff")}
+		Expect(info.DefaultMessage()).To(Equal("Expected to match the golden file\ndiff"))
+
+		info.Negated = true
+		info.ReceivedPath = "foo.received"
+		Expect(info.DefaultMessage()).To(Equal("Expected not to match the golden file\ndiff\nReceived content written to foo.received"))
+	})
+})
+
+var _ = Describe("WithFailureFormatter", func() {
+	var (
+		infos   []FailureInfo
+		matcher *Matcher
+	)
+
+	BeforeEach(func() {
+		infos = nil
+		suite := &SuiteStorage{Path: "testdata/foo.golden", Fs: afero.NewMemMapFs()}
+		Expect(suite.Named("foo").Write([]byte("a\n"))).To(Succeed())
+
+		matcher = newMatcher("foo", "foo",
+			WithStorage(suite.Named("foo")),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+			WithUnifiedDiff(0),
+			WithFailureFormatter(FailureFormatterFunc(func(info FailureInfo) string {
+				infos = append(infos, info)
+
+				return "run UPDATE_GOLDEN=1 to update " + info.Path
+			})),
+		)
+	})
+
+	It("should format the failure message", func() {
+		Expect(matcher.Match("b\n")).To(BeFalse())
+		Expect(matcher.FailureMessage("b\n")).To(Equal("run UPDATE_GOLDEN=1 to update testdata/foo.golden"))
+		Expect(infos).To(Equal([]FailureInfo{{
+			Name: "foo",
+			Path: "testdata/foo.golden",
+			Diff: (&UnifiedDiffer{}).Diff([]byte("a\n"), []byte("b\n")),
+		}}))
+	})
+
+	It("should format the negated failure message", func() {
+		matcher.NegatedFailureMessage("a\n")
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].Negated).To(BeTrue())
+	})
+})
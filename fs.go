@@ -0,0 +1,76 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/spf13/afero"
+)
+
+// ErrReadOnlyStorage is returned when writing to a ReadOnlyStorage.
+var ErrReadOnlyStorage = errors.New("golden files are read-only, " +
+	"run update mode against the real file system to write them")
+
+var _ NamedStorage = (*ReadOnlyStorage)(nil)
+
+// ReadOnlyStorage is a Storage which rejects all writes, such as golden files embedded in the
+// test binary.
+type ReadOnlyStorage struct {
+	Inner Storage
+}
+
+// WithFS reads golden files from fsys, e.g. an embed.FS, instead of the real file system. The
+// storage becomes read-only, so new or updated snapshots fail with ErrReadOnlyStorage. Use it
+// after other storage options.
+func WithFS(fsys fs.FS) Option {
+	return func(matcher *Matcher) {
+		afs := afero.FromIOFS{FS: fsys}
+
+		storage := mapStorage(matcher.Storage, func(inner Storage) Storage {
+			switch s := inner.(type) {
+			case *SingleStorage:
+				copied := *s
+				copied.Fs = afs
+
+				return &copied
+			case *SuiteStorage:
+				copied := *s
+				copied.Fs = afs
+
+				return &copied
+			case *DirStorage:
+				copied := *s
+				copied.Fs = afs
+
+				return &copied
+			default:
+				return inner
+			}
+		})
+
+		matcher.Storage = &ReadOnlyStorage{Inner: storage}
+	}
+}
+
+// Named returns a ReadOnlyStorage for the named snapshot. It returns r if Inner is not a
+// NamedStorage.
+func (r *ReadOnlyStorage) Named(name string) Storage {
+	return namedInnerStorage(r, name)
+}
+
+func (r *ReadOnlyStorage) Unwrap() Storage {
+	return r.Inner
+}
+
+func (r *ReadOnlyStorage) Wrap(inner Storage) Storage {
+	return &ReadOnlyStorage{Inner: inner}
+}
+
+func (r *ReadOnlyStorage) Read() ([]byte, error) {
+	return r.Inner.Read()
+}
+
+func (r *ReadOnlyStorage) Write(data []byte) error {
+	return fmt.Errorf("%w: %s", ErrReadOnlyStorage, getStorageName(r.Inner))
+}
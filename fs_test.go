@@ -0,0 +1,52 @@
+package goldga
+
+import (
+	"testing/fstest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("WithFS", func() {
+	fsys := fstest.MapFS{
+		"testdata/foo.golden":     &fstest.MapFile{Data: []byte("[snapshots]\n\"foo\" = '''\nbar\n'''\n")},
+		"testdata/foo/baz.golden": &fstest.MapFile{Data: []byte("qux\n")},
+	}
+
+	It("should read suite files", func() {
+		m := newMatcher("testdata/foo.golden", "foo", WithFS(fsys))
+		Expect(m.Storage.Read()).To(Equal([]byte("bar\n")))
+	})
+
+	It("should read DirStorage files", func() {
+		m := newMatcher("testdata/foo.golden", "baz", WithDirStorage(), WithFS(fsys))
+		Expect(m.Storage.Read()).To(Equal([]byte("qux\n")))
+	})
+
+	It("should return not found for missing snapshots", func() {
+		m := newMatcher("testdata/foo.golden", "missing", WithFS(fsys))
+		_, err := m.Storage.Read()
+		Expect(err).To(MatchError(afero.ErrFileNotFound))
+	})
+
+	It("should fail to write", func() {
+		m := newMatcher("testdata/foo.golden", "missing", WithFS(fsys), WithUpdatePolicy(UpdatePolicyCreateOnly))
+		_, err := m.Match("foo")
+		Expect(err).To(MatchError(ErrReadOnlyStorage))
+		Expect(err).To(MatchError(ContainSubstring("missing")))
+	})
+
+	It("should support names", func() {
+		m := newMatcher("testdata/foo.golden", "baz", WithFS(fsys))
+		Expect(m.Storage.(NamedStorage).Named("foo").Read()).To(Equal([]byte("bar\n")))
+	})
+
+	It("should keep storages without names", func() {
+		m := newMatcher("testdata/foo.golden", "baz",
+			WithStorage(&SingleStorage{Path: "testdata/foo.golden", Fs: afero.NewMemMapFs()}),
+			WithFS(fsys),
+		)
+		Expect(m.Storage.(NamedStorage).Named("foo")).To(BeIdenticalTo(m.Storage))
+	})
+})
@@ -1,16 +1,21 @@
 package goldga
 
 import (
-	"path/filepath"
-	"strings"
-
 	"github.com/onsi/ginkgo"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
 )
 
 func getCurrentGinkgoTestDescription() ginkgo.GinkgoTestDescription {
 	return ginkgo.CurrentGinkgoTestDescription()
 }
 
+// isGinkgoPartialRun reports whether Ginkgo runs only some specs because of focus or skip flags,
+// or runs them on parallel nodes.
+func isGinkgoPartialRun() bool {
+	return len(ginkgoconfig.GinkgoConfig.FocusStrings) > 0 || len(ginkgoconfig.GinkgoConfig.SkipStrings) > 0 ||
+		ginkgoconfig.GinkgoConfig.ParallelTotal > 1
+}
+
 func getGinkgoPath() string {
 	desc := getCurrentGinkgoTestDescription()
 	path := desc.FileName
@@ -19,14 +24,7 @@ func getGinkgoPath() string {
 		panic("current file name is empty")
 	}
 
-	name := filepath.Base(path)
-
-	if ext := filepath.Ext(name); ext != "" {
-		name = strings.TrimSuffix(name, ext)
-		name = strings.TrimSuffix(name, "_test")
-	}
-
-	return filepath.Join("testdata", name+".golden")
+	return getGoldenPath(path)
 }
 
 func getGinkgoTestName() string {
@@ -38,3 +36,14 @@ func getGinkgoTestName() string {
 
 	return testName
 }
+
+func getGinkgoNameInfo() NameInfo {
+	desc := getCurrentGinkgoTestDescription()
+
+	return NameInfo{
+		File:     desc.FileName,
+		Line:     desc.LineNumber,
+		Texts:    desc.ComponentTexts,
+		FullText: getGinkgoTestName(),
+	}
+}
@@ -0,0 +1,176 @@
+package goldga
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	updateModeGit = "git"
+	gitBaseEnv    = "GOLDGA_GIT_BASE"
+)
+
+// nolint: gochecknoglobals
+var (
+	runGit = func(dir string, args ...string) ([]byte, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+		}
+
+		return out, nil
+	}
+
+	gitChangesMu sync.Mutex
+	gitChanges   = map[string]bool{}
+)
+
+// WithGitAwareUpdate only updates snapshots of tests whose package has changed according to git,
+// so a blanket update cannot absorb unrelated regressions. A package has changed if it contains
+// uncommitted files, or files changed since base if base is not empty. Changes of golden files
+// and other files written by goldga are ignored. Snapshots of other packages are compared as in a
+// normal run. It is enabled when GOLDGA_UPDATE is set to "git", with GOLDGA_GIT_BASE as base.
+//
+// The package is the directory of the test file. Testers created by NewAt do not know the test
+// file and use the working directory instead, which go test sets to the package directory.
+func WithGitAwareUpdate(base string) Option {
+	return func(matcher *Matcher) {
+		matcher.GitAwareUpdate = true
+		matcher.GitBase = base
+	}
+}
+
+// restrictUpdateToChanges turns off updates if the package of the test has not changed.
+func (m *Matcher) restrictUpdateToChanges() error {
+	if !m.GitAwareUpdate || !(m.UpdateFile || m.UpdatePolicy == UpdatePolicyAlways) {
+		return nil
+	}
+
+	dir := "."
+	if m.nameInfo.File != "" {
+		dir = filepath.Dir(m.nameInfo.File)
+	}
+
+	changed, err := hasGitChanges(dir, m.GitBase)
+	if err != nil {
+		return fmt.Errorf("failed to get git changes: %w", err)
+	}
+
+	if !changed {
+		m.debugf("no git changes in %s, not updating", dir)
+		m.UpdateFile = false
+		m.UpdatePolicy = UpdatePolicyCreateOnly
+	}
+
+	return nil
+}
+
+func hasGitChanges(dir, base string) (bool, error) {
+	gitChangesMu.Lock()
+	defer gitChangesMu.Unlock()
+
+	key := dir + "\x00" + base
+
+	if changed, ok := gitChanges[key]; ok {
+		return changed, nil
+	}
+
+	out, err := runGit(dir, "status", "--porcelain", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return false, err
+	}
+
+	changed := containsSourceChanges(parseGitStatus(out))
+
+	if !changed && base != "" {
+		out, err := runGit(dir, "diff", "--name-only", base, "--", ".")
+		if err != nil {
+			return false, err
+		}
+
+		changed = containsSourceChanges(strings.Split(string(out), "\n"))
+	}
+
+	gitChanges[key] = changed
+
+	return changed, nil
+}
+
+// parseGitStatus returns the paths in git status --porcelain output. Both paths of renames are
+// returned.
+func parseGitStatus(out []byte) []string {
+	var paths []string
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(line) <= 3 {
+			continue
+		}
+
+		paths = append(paths, strings.Split(line[3:], " -> ")...)
+	}
+
+	return paths
+}
+
+// nolint: gochecknoglobals
+var goldgaArtifactExts = []string{
+	receivedExt, diffImageExt, pendingExt, origExt, backupExt, lockExt, tempExt,
+}
+
+// isGoldgaArtifact reports whether path is a golden file or a file goldga writes next to golden
+// files.
+func isGoldgaArtifact(path string) bool {
+	base := filepath.Base(path)
+
+	if strings.Contains(base, goldenExt) || base == dirNamesFile {
+		return true
+	}
+
+	for _, ext := range goldgaArtifactExts {
+		if strings.HasSuffix(base, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsSourceChanges reports whether paths contain files other than golden files and the
+// files goldga writes next to them.
+func containsSourceChanges(paths []string) bool {
+	for _, path := range paths {
+		path = strings.Trim(strings.TrimSpace(path), `"`)
+
+		if path != "" && !isGoldgaArtifact(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getGitAwareUpdate() (bool, string) {
+	if getUpdateMode() != updateModeGit {
+		return false, ""
+	}
+
+	return true, os.Getenv(gitBaseEnv)
+}
+
+func resetGitChanges() {
+	gitChangesMu.Lock()
+	defer gitChangesMu.Unlock()
+
+	gitChanges = map[string]bool{}
+}
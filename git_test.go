@@ -0,0 +1,123 @@
+package goldga
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("WithGitAwareUpdate", func() {
+	var (
+		origRunGit func(dir string, args ...string) ([]byte, error)
+		outputs    map[string]string
+		calls      []string
+		storage    Storage
+	)
+
+	BeforeEach(func() {
+		origRunGit = runGit
+		outputs = map[string]string{}
+		calls = nil
+		runGit = func(dir string, args ...string) ([]byte, error) {
+			call := dir + ": " + strings.Join(args, " ")
+			calls = append(calls, call)
+
+			if out, ok := outputs[args[0]]; ok {
+				return []byte(out), nil
+			}
+
+			return nil, errors.New("not a git repository")
+		}
+		resetGitChanges()
+
+		storage = (&SuiteStorage{Path: "git.golden", Fs: afero.NewMemMapFs()}).Named("foo")
+		Expect(storage.Write([]byte("old"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		runGit = origRunGit
+		resetGitChanges()
+	})
+
+	newGitMatcher := func(base string) *Matcher {
+		return newMatcherWithInfo("git.golden", NameInfo{File: "/src/pkg/foo_test.go", FullText: "foo"},
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyAlways),
+			WithGitAwareUpdate(base),
+		)
+	}
+
+	It("should update snapshots of changed packages", func() {
+		outputs["status"] = " M foo.go\n"
+		Expect(newGitMatcher("").Match("new")).To(BeTrue())
+		Expect(storage.Read()).To(Equal([]byte("new")))
+		Expect(calls).To(Equal([]string{"/src/pkg: status --porcelain --untracked-files=all -- ."}))
+	})
+
+	It("should not update snapshots of unchanged packages", func() {
+		outputs["status"] = ""
+		Expect(newGitMatcher("").Match("new")).To(BeFalse())
+		Expect(storage.Read()).To(Equal([]byte("old")))
+	})
+
+	It("should ignore changes of golden files", func() {
+		outputs["status"] = " M testdata/foo.golden\n?? testdata/foo.golden.received\n"
+		Expect(newGitMatcher("").Match("new")).To(BeFalse())
+	})
+
+	It("should ignore files written by goldga", func() {
+		outputs["status"] = "?? testdata/foo.png.diff.png\n?? testdata/foo.png.orig\n?? testdata/foo.golden.lock\n" +
+			"?? testdata/suite.bak\n?? testdata/foo.png.tmp\n?? testdata/foo.pending\n?? testdata/.names.toml\n"
+		Expect(newGitMatcher("").Match("new")).To(BeFalse())
+	})
+
+	It("should use the working directory without a test file", func() {
+		outputs["status"] = " M foo.go\n"
+		Expect(newMatcher("git.golden", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyAlways),
+			WithGitAwareUpdate(""),
+		).Match("new")).To(BeTrue())
+		Expect(calls).To(Equal([]string{".: status --porcelain --untracked-files=all -- ."}))
+	})
+
+	It("should compare with the base", func() {
+		outputs["status"] = ""
+		outputs["diff"] = "foo.go\n"
+		Expect(newGitMatcher("main").Match("new")).To(BeTrue())
+		Expect(calls).To(ContainElement("/src/pkg: diff --name-only main -- ."))
+	})
+
+	It("should cache git changes per package", func() {
+		outputs["status"] = " M foo.go\n"
+		Expect(newGitMatcher("").Match("new")).To(BeTrue())
+		Expect(newGitMatcher("").Match("newer")).To(BeTrue())
+		Expect(calls).To(HaveLen(1))
+	})
+
+	It("should return git errors", func() {
+		_, err := newGitMatcher("").Match("new")
+		Expect(err).To(MatchError(ContainSubstring("not a git repository")))
+	})
+
+	It("should do nothing outside update mode", func() {
+		m := newGitMatcher("")
+		WithUpdatePolicy(UpdatePolicyCreateOnly)(m)
+		Expect(m.Match("old")).To(BeTrue())
+		Expect(calls).To(BeEmpty())
+	})
+})
+
+var _ = DescribeTable("parseGitStatus", func(out string, expected []string) {
+	Expect(parseGitStatus([]byte(out))).To(Equal(expected))
+},
+	Entry("empty", "", []string(nil)),
+	Entry("modified and untracked", " M a.go\n?? b/c.go\n", []string{"a.go", "b/c.go"}),
+	Entry("renamed", "R  a.go -> b.go\n", []string{"a.go", "b.go"}),
+)
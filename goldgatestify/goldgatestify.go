@@ -0,0 +1,87 @@
+// Package goldgatestify matches golden files in tests using testify instead of Gomega.
+//
+//	func TestParse(t *testing.T) {
+//		goldgatestify.AssertGolden(t, parse(input))
+//	}
+//
+// Tests of a testify suite can share one golden file with Suite.
+package goldgatestify
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tommy351/goldga"
+)
+
+const goldenExt = ".golden"
+
+// AssertGolden asserts that actual matches its snapshot in the golden file of the calling test
+// file, named after t.Name(). Like testify assertions, it marks the test as failed on mismatch
+// and reports whether it matched.
+func AssertGolden(t testing.TB, actual interface{}, options ...goldga.Option) bool {
+	t.Helper()
+
+	_, file, _, ok := runtime.Caller(1)
+	if !ok {
+		t.Fatal("goldga: unable to get the test file name")
+
+		return false
+	}
+
+	return goldga.NewAt(t, goldenPath(file), "", options...).Assert(actual)
+}
+
+// Suite shares one golden file between the tests of a testify suite. Create it in SetupSuite:
+//
+//	func (s *ParserSuite) SetupSuite() {
+//		s.golden = goldgatestify.NewSuite(s.T())
+//	}
+//
+//	func (s *ParserSuite) TestParse() {
+//		s.golden.AssertGolden(s.T(), parse(input))
+//	}
+//
+// Snapshots are stored in testdata/<suite test name>.golden and named after the test name
+// relative to the suite, such as "TestParse" or "TestParse/empty".
+type Suite struct {
+	name    string
+	path    string
+	options []goldga.Option
+}
+
+// NewSuite returns a Suite for the suite test t, which is suite.T() in SetupSuite. Options are
+// applied to every assertion of the suite.
+func NewSuite(t testing.TB, options ...goldga.Option) *Suite {
+	return &Suite{
+		name:    t.Name(),
+		path:    filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+goldenExt),
+		options: options,
+	}
+}
+
+// AssertGolden asserts that actual matches the snapshot of the suite test t, which is suite.T().
+func (s *Suite) AssertGolden(t testing.TB, actual interface{}, options ...goldga.Option) bool {
+	t.Helper()
+
+	options = append(append([]goldga.Option{}, s.options...), options...)
+
+	return goldga.NewAt(t, s.path, s.snapshotName(t), options...).Assert(actual)
+}
+
+func (s *Suite) snapshotName(t testing.TB) string {
+	if name := strings.TrimPrefix(t.Name(), s.name+"/"); name != "" && name != t.Name() {
+		return name
+	}
+
+	return t.Name()
+}
+
+// goldenPath returns the golden file of a test file, like goldga.New.
+func goldenPath(file string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+	return filepath.Join("testdata", strings.TrimSuffix(name, "_test")+goldenExt)
+}
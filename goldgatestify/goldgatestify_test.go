@@ -0,0 +1,123 @@
+package goldgatestify
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+	"github.com/tommy351/goldga"
+)
+
+func TestGoldgaTestify(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "goldgatestify")
+}
+
+type fakeT struct {
+	testing.TB
+
+	name   string
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Name() string {
+	return f.name
+}
+
+func (f *fakeT) Error(args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func (f *fakeT) Fatal(args ...interface{}) {
+	f.Error(args...)
+}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.Fatal(fmt.Sprintf(format, args...))
+}
+
+func withFs(fs afero.Fs) goldga.Option {
+	return func(m *goldga.Matcher) {
+		m.Storage.(*goldga.SuiteStorage).Fs = fs
+	}
+}
+
+func readSuite(fs afero.Fs, path string) map[string]string {
+	data, err := afero.ReadFile(fs, path)
+	Expect(err).NotTo(HaveOccurred())
+
+	suite, err := goldga.ParseSuite(data)
+	Expect(err).NotTo(HaveOccurred())
+
+	return suite
+}
+
+var _ = Describe("AssertGolden", func() {
+	var fs afero.Fs
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+	})
+
+	It("should use the golden file of the caller", func() {
+		t := &fakeT{name: "TestFoo"}
+		Expect(AssertGolden(t, "foo", withFs(fs), goldga.WithSerializer(&goldga.StringSerializer{}))).To(BeTrue())
+		Expect(readSuite(fs, filepath.Join("testdata", "goldgatestify.golden"))).To(Equal(map[string]string{
+			"TestFoo": "foo",
+		}))
+	})
+
+	It("should fail when not matched", func() {
+		t := &fakeT{name: "TestFoo"}
+		AssertGolden(t, "foo", withFs(fs))
+		Expect(AssertGolden(t, "bar", withFs(fs))).To(BeFalse())
+		Expect(t.errors).To(HaveLen(1))
+		Expect(t.errors[0]).To(HavePrefix("Expected to match the golden file"))
+	})
+})
+
+var _ = Describe("Suite", func() {
+	var (
+		fs    afero.Fs
+		suite *Suite
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		suite = NewSuite(&fakeT{name: "TestParserSuite"}, withFs(fs), goldga.WithSerializer(&goldga.StringSerializer{}))
+	})
+
+	It("should share a golden file named after the suite", func() {
+		Expect(suite.AssertGolden(&fakeT{name: "TestParserSuite/TestParse"}, "a")).To(BeTrue())
+		Expect(suite.AssertGolden(&fakeT{name: "TestParserSuite/TestParse/empty"}, "b")).To(BeTrue())
+		Expect(suite.AssertGolden(&fakeT{name: "TestParserSuite/TestFormat"}, "c")).To(BeTrue())
+		Expect(readSuite(fs, filepath.Join("testdata", "TestParserSuite.golden"))).To(Equal(map[string]string{
+			"TestParse":       "a",
+			"TestParse/empty": "b",
+			"TestFormat":      "c",
+		}))
+	})
+
+	It("should keep names of tests outside the suite", func() {
+		Expect(suite.AssertGolden(&fakeT{name: "TestOther"}, "a")).To(BeTrue())
+		Expect(readSuite(fs, filepath.Join("testdata", "TestParserSuite.golden"))).To(HaveKey("TestOther"))
+	})
+
+	It("should fail when not matched", func() {
+		t := &fakeT{name: "TestParserSuite/TestParse"}
+		suite.AssertGolden(t, "a")
+		Expect(suite.AssertGolden(t, "b")).To(BeFalse())
+		Expect(t.errors).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("goldenPath", func() {
+	It("should strip the _test suffix", func() {
+		Expect(goldenPath("/src/foo/parser_test.go")).To(Equal(filepath.Join("testdata", "parser.golden")))
+	})
+})
@@ -0,0 +1,135 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const scrubbedHeaderValue = "<SCRUBBED>"
+
+// nolint: gochecknoglobals
+var defaultScrubbedHeaders = []string{"Date", "Set-Cookie"}
+
+var _ Serializer = (*HTTPSerializer)(nil)
+
+// httpResponseResult is implemented by *httptest.ResponseRecorder.
+type httpResponseResult interface {
+	Result() *http.Response
+}
+
+// HTTPSerializer renders an *http.Response, or a value with a Result method returning one such
+// as *httptest.ResponseRecorder, as the status line,
+// headers and body. JSON bodies are pretty-printed. Values of volatile headers such as Date and
+// Set-Cookie are scrubbed.
+type HTTPSerializer struct {
+	// IncludeHeaders limits rendered headers to the given ones. All headers are rendered if empty.
+	IncludeHeaders []string
+	// ExcludeHeaders are headers which are not rendered.
+	ExcludeHeaders []string
+	// ScrubHeaders are headers whose values are scrubbed in addition to Date and Set-Cookie.
+	ScrubHeaders []string
+}
+
+func (h *HTTPSerializer) Serialize(w io.Writer, input interface{}) error {
+	var res *http.Response
+
+	switch input := input.(type) {
+	case *http.Response:
+		res = input
+	case httpResponseResult:
+		res = input.Result()
+	default:
+		return fmt.Errorf("unsupported HTTP input type %T", input)
+	}
+
+	var body []byte
+
+	if res.Body != nil {
+		var err error
+
+		if body, err = ioutil.ReadAll(res.Body); err != nil {
+			return fmt.Errorf("failed to read body: %w", err)
+		}
+
+		// Restore the body so it can still be read after serialization.
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s %s\n", res.Proto, res.Status)
+
+	for _, key := range h.headerKeys(res.Header) {
+		for _, value := range res.Header[key] {
+			if containsHeader(defaultScrubbedHeaders, key) || containsHeader(h.ScrubHeaders, key) {
+				value = scrubbedHeaderValue
+			}
+
+			fmt.Fprintf(&buf, "%s: %s\n", key, value)
+		}
+	}
+
+	buf.WriteString("\n")
+	buf.Write(formatHTTPBody(res.Header.Get("Content-Type"), body))
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
+	return nil
+}
+
+func (h *HTTPSerializer) headerKeys(header http.Header) []string {
+	keys := make([]string, 0, len(header))
+
+	for key := range header {
+		if len(h.IncludeHeaders) > 0 && !containsHeader(h.IncludeHeaders, key) {
+			continue
+		}
+
+		if containsHeader(h.ExcludeHeaders, key) {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func containsHeader(headers []string, key string) bool {
+	for _, h := range headers {
+		if http.CanonicalHeaderKey(h) == http.CanonicalHeaderKey(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatHTTPBody(contentType string, body []byte) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return body
+	}
+
+	var buf bytes.Buffer
+
+	if err := json.Indent(&buf, body, "", "  "); err != nil {
+		return body
+	}
+
+	buf.WriteString("\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,88 @@
+package goldga
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPSerializer", func() {
+	var rec *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		rec = httptest.NewRecorder()
+		rec.Header().Set("Content-Type", "application/json; charset=utf-8")
+		rec.Header().Set("Date", "Wed, 01 Sep 2021 12:34:56 GMT")
+		rec.Header().Add("Set-Cookie", "a=1")
+		rec.Header().Add("Set-Cookie", "b=2")
+		rec.Header().Set("X-Request-Id", "abc")
+		rec.WriteHeader(http.StatusCreated)
+		_, _ = rec.WriteString(`{"b":[1,2],"a":"x"}`)
+	})
+
+	serialize := func(serializer *HTTPSerializer, input interface{}) string {
+		var buf bytes.Buffer
+		Expect(serializer.Serialize(&buf, input)).To(Succeed())
+
+		return buf.String()
+	}
+
+	It("should render the response", func() {
+		Expect(serialize(&HTTPSerializer{}, rec)).To(Equal(`HTTP/1.1 201 Created
+Content-Type: application/json; charset=utf-8
+Date: <SCRUBBED>
+Set-Cookie: <SCRUBBED>
+Set-Cookie: <SCRUBBED>
+X-Request-Id: abc
+
+{
+  "b": [
+    1,
+    2
+  ],
+  "a": "x"
+}
+`))
+	})
+
+	It("should filter headers", func() {
+		serializer := &HTTPSerializer{
+			IncludeHeaders: []string{"content-type", "x-request-id", "date"},
+			ExcludeHeaders: []string{"Date"},
+			ScrubHeaders:   []string{"X-Request-ID"},
+		}
+		Expect(serialize(serializer, rec)).To(HavePrefix(`HTTP/1.1 201 Created
+Content-Type: application/json; charset=utf-8
+X-Request-Id: <SCRUBBED>
+
+`))
+	})
+
+	It("should keep non-JSON bodies as is", func() {
+		res := &http.Response{
+			Proto:  "HTTP/1.1",
+			Status: "200 OK",
+			Header: http.Header{"Content-Type": {"text/plain"}},
+			Body:   ioutil.NopCloser(bytes.NewBufferString(`{"a": 1}`)),
+		}
+		Expect(serialize(&HTTPSerializer{}, res)).To(Equal("HTTP/1.1 200 OK\nContent-Type: text/plain\n\n{\"a\": 1}"))
+
+		body, err := ioutil.ReadAll(res.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(`{"a": 1}`))
+	})
+
+	It("should render responses without body", func() {
+		res := &http.Response{Proto: "HTTP/1.1", Status: "204 No Content"}
+		Expect(serialize(&HTTPSerializer{}, res)).To(Equal("HTTP/1.1 204 No Content\n\n"))
+		Expect(res.Body).To(BeNil())
+	})
+
+	It("should return error on unsupported input", func() {
+		Expect((&HTTPSerializer{}).Serialize(&bytes.Buffer{}, "foo")).NotTo(Succeed())
+	})
+})
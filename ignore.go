@@ -0,0 +1,115 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const ignoredJSONValue = "<ignored>"
+
+// IgnoringPaths masks the values at the given paths in JSON or YAML content before comparison,
+// such as "metadata.createdAt" or "items[*].id". A "*" segment matches every key of a map and
+// "[*]" matches every element of an array. Content which is neither JSON nor YAML is compared
+// as is.
+func IgnoringPaths(paths ...string) Option {
+	parsed, err := parseJSONPaths(paths)
+	if err != nil {
+		panic(fmt.Sprintf("goldga: %v", err))
+	}
+
+	return WithFilter(func(content []byte) []byte {
+		return maskIgnoredPaths(content, parsed)
+	})
+}
+
+// maskIgnoredPaths replaces the values at paths in JSON or YAML content with "<ignored>".
+func maskIgnoredPaths(content []byte, paths [][]jsonPathSegment) []byte {
+	var value interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+
+	if err := dec.Decode(&value); err == nil && !dec.More() {
+		if !maskJSONPaths(value, paths) {
+			return content
+		}
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(value); err != nil {
+			return content
+		}
+
+		return buf.Bytes()
+	}
+
+	if err := yaml.Unmarshal(content, &value); err != nil || !maskJSONPaths(value, paths) {
+		return content
+	}
+
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return content
+	}
+
+	return out
+}
+
+// maskJSONPaths replaces the values at paths in place and reports whether any value was replaced.
+func maskJSONPaths(value interface{}, paths [][]jsonPathSegment) bool {
+	masked := false
+
+	for _, path := range paths {
+		if maskJSONPath(value, path) {
+			masked = true
+		}
+	}
+
+	return masked
+}
+
+func maskJSONPath(value interface{}, path []jsonPathSegment) bool {
+	seg, rest := path[0], path[1:]
+	masked := false
+
+	visit := func(child interface{}, set func(interface{})) {
+		if len(rest) == 0 {
+			set(ignoredJSONValue)
+			masked = true
+		} else if maskJSONPath(child, rest) {
+			masked = true
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if seg.matchKey(k) {
+				k := k
+				visit(child, func(x interface{}) { v[k] = x })
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, child := range v {
+			if seg.matchKey(fmt.Sprint(k)) {
+				k := k
+				visit(child, func(x interface{}) { v[k] = x })
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if seg.matchIndex(i) {
+				i := i
+				visit(child, func(x interface{}) { v[i] = x })
+			}
+		}
+	}
+
+	return masked
+}
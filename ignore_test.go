@@ -0,0 +1,111 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("parseJSONPath", func() {
+	DescribeTable("valid paths", func(path string, expected []jsonPathSegment) {
+		Expect(parseJSONPath(path)).To(Equal(expected))
+	},
+		Entry("key", "a", []jsonPathSegment{{key: "a"}}),
+		Entry("nested", "a.b", []jsonPathSegment{{key: "a"}, {key: "b"}}),
+		Entry("wildcard key", "a.*", []jsonPathSegment{{key: "a"}, {key: "*", wildcard: true}}),
+		Entry("index", "a[1].b", []jsonPathSegment{{key: "a"}, {isIndex: true, index: 1}, {key: "b"}}),
+		Entry("wildcard index", "a[*][*]", []jsonPathSegment{{key: "a"}, {isIndex: true, wildcard: true}, {isIndex: true, wildcard: true}}),
+		Entry("root index", "[0].a", []jsonPathSegment{{isIndex: true, index: 0}, {key: "a"}}),
+	)
+
+	DescribeTable("invalid paths", func(path string) {
+		_, err := parseJSONPath(path)
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("empty", ""),
+		Entry("empty segment", "a..b"),
+		Entry("unclosed index", "a[1"),
+		Entry("negative index", "a[-1]"),
+		Entry("non-numeric index", "a[x]"),
+		Entry("trailing characters", "a[1]x"),
+	)
+})
+
+var _ = Describe("maskIgnoredPaths", func() {
+	ignore := func(content string, paths ...string) string {
+		var parsed [][]jsonPathSegment
+
+		for _, p := range paths {
+			segments, err := parseJSONPath(p)
+			Expect(err).NotTo(HaveOccurred())
+			parsed = append(parsed, segments)
+		}
+
+		return string(maskIgnoredPaths([]byte(content), parsed))
+	}
+
+	It("should mask JSON paths", func() {
+		Expect(ignore(`{"metadata":{"createdAt":"2021-09-01","name":"foo"},"items":[{"id":1,"v":1.50},{"id":2}]}`,
+			"metadata.createdAt", "items[*].id")).To(Equal(`{
+  "items": [
+    {
+      "id": "<ignored>",
+      "v": 1.50
+    },
+    {
+      "id": "<ignored>"
+    }
+  ],
+  "metadata": {
+    "createdAt": "<ignored>",
+    "name": "foo"
+  }
+}
+`))
+	})
+
+	It("should mask YAML paths", func() {
+		Expect(ignore("items:\n- id: 1\n  name: a\n- id: 2\n  name: b\n", "items[1].id", "*.x")).To(Equal(
+			"items:\n- id: 1\n  name: a\n- id: <ignored>\n  name: b\n"))
+	})
+
+	It("should match map keys with wildcards", func() {
+		Expect(ignore(`{"a":{"id":1},"b":{"id":2}}`, "*.id")).To(MatchJSON(`{"a":{"id":"<ignored>"},"b":{"id":"<ignored>"}}`))
+	})
+
+	It("should keep content if no path matches", func() {
+		Expect(ignore(`{"a":1}`, "b")).To(Equal(`{"a":1}`))
+		Expect(ignore("plain text", "a")).To(Equal("plain text"))
+	})
+})
+
+var _ = Describe("IgnoringPaths", func() {
+	var (
+		storage *SingleStorage
+		matcher *Matcher
+	)
+
+	BeforeEach(func() {
+		storage = &SingleStorage{Path: "foo.golden", Fs: afero.NewMemMapFs()}
+		Expect(storage.Write([]byte(`{"id":"a1","name":"foo"}`))).To(Succeed())
+		matcher = newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+			IgnoringPaths("id"),
+		)
+	})
+
+	It("should ignore the paths", func() {
+		Expect(matcher.Match(`{"id":"b2","name":"foo"}`)).To(BeTrue())
+	})
+
+	It("should fail if other values differ", func() {
+		Expect(matcher.Match(`{"id":"b2","name":"bar"}`)).To(BeFalse())
+	})
+
+	It("should panic if a path is invalid", func() {
+		Expect(func() { IgnoringPaths("a[") }).To(Panic())
+	})
+})
@@ -0,0 +1,239 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // Register the JPEG decoder.
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const diffImageExt = ".diff.png"
+
+var (
+	_ Comparer = (*ImageComparer)(nil)
+	_ Differ   = (*ImageComparer)(nil)
+)
+
+// ImageComparer compares PNG or JPEG images pixel by pixel, so harmless encoder differences do
+// not fail the match. Images serialized by BinarySerializer are decoded from base64 first.
+type ImageComparer struct {
+	// ChannelTolerance is the maximum difference of each 8-bit color channel for pixels to be
+	// considered equal.
+	ChannelTolerance uint8
+	// MaxDiffRatio is the maximum ratio of different pixels, from 0 to 1.
+	MaxDiffRatio float64
+
+	// DiffPath is where an image highlighting different pixels in red is written on mismatch.
+	// No image is written if it is empty.
+	DiffPath string
+	// Fs is the file system the diff image is written to. Defaults to the OS file system.
+	Fs afero.Fs
+}
+
+// imageComparison is the result of comparing two images.
+type imageComparison struct {
+	diffPixels  int
+	totalPixels int
+	sizeDiffers bool
+	expected    image.Rectangle
+	actual      image.Rectangle
+	diff        *image.NRGBA
+}
+
+// WithImageComparison compares images with ImageComparer and writes a diff image next to the
+// golden file on mismatch. Use it after storage options, and with BinarySerializer for suite
+// files, or StringSerializer to store raw image bytes with SingleStorage or DirStorage.
+func WithImageComparison(channelTolerance uint8, maxDiffRatio float64) Option {
+	return func(matcher *Matcher) {
+		comparer := &ImageComparer{
+			ChannelTolerance: channelTolerance,
+			MaxDiffRatio:     maxDiffRatio,
+			DiffPath:         getDiffImagePath(matcher.Storage),
+			Fs:               defaultBaseFs,
+		}
+		matcher.Comparer = comparer
+		matcher.Differ = comparer
+	}
+}
+
+func getDiffImagePath(storage Storage) string {
+	switch s := innerStorage(storage).(type) {
+	case *SingleStorage:
+		return s.Path + diffImageExt
+	case *DirStorage:
+		return filepath.Join(s.Dir, sanitizeFileName(s.Name)+diffImageExt)
+	case *SuiteStorage:
+		return filepath.Join(strings.TrimSuffix(s.Path, goldenExt), sanitizeFileName(s.Name)+diffImageExt)
+	default:
+		return ""
+	}
+}
+
+// withoutDiffImage returns a copy of c which does not write a diff image, for dry runs.
+func (c *ImageComparer) withoutDiffImage() *ImageComparer {
+	comparer := *c
+	comparer.DiffPath = ""
+
+	return &comparer
+}
+
+func (c *ImageComparer) Compare(expected, actual []byte) (bool, error) {
+	result, err := c.compare(expected, actual)
+	if err != nil {
+		return false, err
+	}
+
+	if c.match(result) {
+		return true, nil
+	}
+
+	if c.DiffPath != "" && result.diff != nil {
+		if err := c.writeDiff(result.diff); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+func (c *ImageComparer) Diff(snapshot, received []byte) []byte {
+	result, err := c.compare(snapshot, received)
+	if err != nil {
+		return []byte(err.Error())
+	}
+
+	if result.sizeDiffers {
+		return []byte(fmt.Sprintf("Image size differs: expected %dx%d, got %dx%d",
+			result.expected.Dx(), result.expected.Dy(), result.actual.Dx(), result.actual.Dy()))
+	}
+
+	msg := fmt.Sprintf("%d of %d pixels differ (%.2f%%, max %.2f%%)",
+		result.diffPixels, result.totalPixels,
+		100*float64(result.diffPixels)/float64(result.totalPixels), 100*c.MaxDiffRatio)
+
+	if c.DiffPath != "" && !c.match(result) {
+		msg += "\nDiff image: " + c.DiffPath
+	}
+
+	return []byte(msg)
+}
+
+func (c *ImageComparer) match(result *imageComparison) bool {
+	if result.sizeDiffers {
+		return false
+	}
+
+	if result.totalPixels == 0 {
+		return true
+	}
+
+	return float64(result.diffPixels)/float64(result.totalPixels) <= c.MaxDiffRatio
+}
+
+func (c *ImageComparer) compare(expected, actual []byte) (*imageComparison, error) {
+	expectedImage, err := decodeImage(expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode expected image: %w", err)
+	}
+
+	actualImage, err := decodeImage(actual)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode actual image: %w", err)
+	}
+
+	eb, ab := expectedImage.Bounds(), actualImage.Bounds()
+	result := &imageComparison{expected: eb, actual: ab}
+
+	if eb.Dx() != ab.Dx() || eb.Dy() != ab.Dy() {
+		result.sizeDiffers = true
+
+		return result, nil
+	}
+
+	result.totalPixels = eb.Dx() * eb.Dy()
+	result.diff = image.NewNRGBA(image.Rect(0, 0, eb.Dx(), eb.Dy()))
+
+	for y := 0; y < eb.Dy(); y++ {
+		for x := 0; x < eb.Dx(); x++ {
+			e := color.NRGBAModel.Convert(expectedImage.At(eb.Min.X+x, eb.Min.Y+y)).(color.NRGBA)
+			a := color.NRGBAModel.Convert(actualImage.At(ab.Min.X+x, ab.Min.Y+y)).(color.NRGBA)
+
+			if c.equalColor(e, a) {
+				// Fade equal pixels so differences stand out.
+				gray := color.GrayModel.Convert(e).(color.Gray)
+				result.diff.SetNRGBA(x, y, color.NRGBA{R: gray.Y, G: gray.Y, B: gray.Y, A: 64})
+
+				continue
+			}
+
+			result.diffPixels++
+			result.diff.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	return result, nil
+}
+
+func (c *ImageComparer) equalColor(a, b color.NRGBA) bool {
+	return channelDiff(a.R, b.R) <= c.ChannelTolerance &&
+		channelDiff(a.G, b.G) <= c.ChannelTolerance &&
+		channelDiff(a.B, b.B) <= c.ChannelTolerance &&
+		channelDiff(a.A, b.A) <= c.ChannelTolerance
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
+
+func (c *ImageComparer) writeDiff(diff draw.Image) error {
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, diff); err != nil {
+		return fmt.Errorf("failed to encode diff image: %w", err)
+	}
+
+	fs := c.Fs
+	if fs == nil {
+		fs = defaultBaseFs
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(c.DiffPath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	if err := afero.WriteFile(fs, c.DiffPath, buf.Bytes(), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write diff image: %w", err)
+	}
+
+	return nil
+}
+
+// decodeImage decodes an image, or base64 encoded image data written by BinarySerializer.
+func decodeImage(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err == nil {
+		return img, nil
+	}
+
+	decoded, decodeErr := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+	if decodeErr != nil {
+		return nil, err
+	}
+
+	img, _, err = image.Decode(bytes.NewReader(decoded))
+
+	return img, err
+}
@@ -0,0 +1,141 @@
+package goldga
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+func encodeTestImage(width, height int, fn func(x, y int) color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fn(x, y))
+		}
+	}
+
+	var buf bytes.Buffer
+	Expect(png.Encode(&buf, img)).To(Succeed())
+
+	return buf.Bytes()
+}
+
+var _ = Describe("ImageComparer", func() {
+	var (
+		fs       afero.Fs
+		comparer *ImageComparer
+	)
+
+	white := func(x, y int) color.Color { return color.White }
+	expected := encodeTestImage(10, 10, white)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		comparer = &ImageComparer{
+			ChannelTolerance: 2,
+			MaxDiffRatio:     0.05,
+			DiffPath:         "testdata/foo.diff.png",
+			Fs:               fs,
+		}
+	})
+
+	It("should match pixels within tolerance", func() {
+		actual := encodeTestImage(10, 10, func(x, y int) color.Color {
+			return color.NRGBA{R: 254, G: 253, B: 255, A: 255}
+		})
+		Expect(comparer.Compare(expected, actual)).To(BeTrue())
+	})
+
+	It("should match if few pixels differ", func() {
+		actual := encodeTestImage(10, 10, func(x, y int) color.Color {
+			if x == 0 && y < 5 {
+				return color.Black
+			}
+
+			return color.White
+		})
+		Expect(comparer.Compare(expected, actual)).To(BeTrue())
+		Expect(afero.Exists(fs, comparer.DiffPath)).To(BeFalse())
+	})
+
+	When("too many pixels differ", func() {
+		actual := encodeTestImage(10, 10, func(x, y int) color.Color {
+			if x < 2 {
+				return color.Black
+			}
+
+			return color.White
+		})
+
+		It("should not match", func() {
+			Expect(comparer.Compare(expected, actual)).To(BeFalse())
+		})
+
+		It("should write a diff image", func() {
+			_, err := comparer.Compare(expected, actual)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := afero.ReadFile(fs, comparer.DiffPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			diff, err := png.Decode(bytes.NewReader(data))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff.At(0, 0)).To(Equal(color.NRGBA{R: 255, A: 255}))
+		})
+
+		It("should describe the difference", func() {
+			Expect(string(comparer.Diff(expected, actual))).To(Equal(
+				"20 of 100 pixels differ (20.00%, max 5.00%)\nDiff image: testdata/foo.diff.png"))
+		})
+	})
+
+	It("should not match images of different sizes", func() {
+		actual := encodeTestImage(5, 10, white)
+		Expect(comparer.Compare(expected, actual)).To(BeFalse())
+		Expect(string(comparer.Diff(expected, actual))).To(Equal("Image size differs: expected 10x10, got 5x10"))
+	})
+
+	It("should decode images serialized by BinarySerializer", func() {
+		var buf bytes.Buffer
+		Expect((&BinarySerializer{}).Serialize(&buf, expected)).To(Succeed())
+		Expect(comparer.Compare(buf.Bytes(), expected)).To(BeTrue())
+	})
+
+	It("should return error on invalid images", func() {
+		_, err := comparer.Compare([]byte("foo"), expected)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WithImageComparison", func() {
+	It("should write the diff image next to the golden file", func() {
+		m := newMatcher("testdata/foo.golden", "a b", WithDirStorage(), WithImageComparison(0, 0))
+		Expect(m.Comparer.(*ImageComparer).DiffPath).To(Equal("testdata/foo/a_b.diff.png"))
+		Expect(m.Differ).To(BeIdenticalTo(m.Comparer))
+	})
+
+	It("should not write the diff image in a dry run", func() {
+		fs := afero.NewMemMapFs()
+		storage := &SingleStorage{Path: "testdata/foo.png", Fs: fs}
+		Expect(storage.Write(encodeTestImage(2, 2, func(x, y int) color.Color { return color.White }))).To(Succeed())
+
+		m := newMatcher("testdata/foo.golden", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithImageComparison(0, 0),
+			WithDryRun(),
+		)
+		m.Comparer.(*ImageComparer).Fs = fs
+		actual := string(encodeTestImage(2, 2, func(x, y int) color.Color { return color.Black }))
+
+		Expect(m.Match(actual)).To(BeFalse())
+		Expect(afero.Exists(fs, "testdata/foo.png.diff.png")).To(BeFalse())
+		Expect(m.FailureMessage(actual)).NotTo(ContainSubstring("Diff image"))
+	})
+})
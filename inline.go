@@ -0,0 +1,169 @@
+package goldga
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+const inlineFuncName = "MatchInline"
+
+// nolint: gochecknoglobals
+var (
+	inlineEditsMu sync.Mutex
+	inlineEdits   = map[string][]inlineEdit{}
+)
+
+// inlineEdit records the number of lines added at a line of a source file, so later call sites
+// in the same file can still be found after the file was rewritten.
+type inlineEdit struct {
+	line  int
+	delta int
+}
+
+// MatchInline matches against the snapshot embedded in the test source. When the snapshot is
+// empty or in update mode, the first argument of the MatchInline call is rewritten with the
+// actual content.
+func MatchInline(snapshot string, options ...Option) *Matcher {
+	_, file, line, ok := runtime.Caller(1)
+	if !ok {
+		panic("unable to get the caller of MatchInline")
+	}
+
+	storage := &InlineStorage{
+		Path:     file,
+		Line:     line,
+		Snapshot: snapshot,
+		Fs:       defaultBaseFs,
+	}
+
+	return newMatcher(getGoldenPath(file), "", append([]Option{WithStorage(storage)}, options...)...)
+}
+
+var _ Storage = (*InlineStorage)(nil)
+
+// InlineStorage stores a snapshot as the first argument of the MatchInline call at Line of the
+// Go source file Path.
+type InlineStorage struct {
+	Path     string
+	Line     int
+	Snapshot string
+	Fs       afero.Fs
+}
+
+func (s *InlineStorage) Read() ([]byte, error) {
+	if s.Snapshot == "" {
+		return nil, afero.ErrFileNotFound
+	}
+
+	return []byte(s.Snapshot), nil
+}
+
+func (s *InlineStorage) Write(data []byte) error {
+	checkStrictWrite()
+
+	inlineEditsMu.Lock()
+	defer inlineEditsMu.Unlock()
+
+	line := s.Line
+
+	for _, edit := range inlineEdits[s.Path] {
+		if edit.line < s.Line {
+			line += edit.delta
+		}
+	}
+
+	src, err := afero.ReadFile(s.Fs, s.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, s.Path, src, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse source file: %w", err)
+	}
+
+	lit := findInlineSnapshot(fset, file, line)
+	if lit == nil {
+		return fmt.Errorf("%s:%d: %s call with a string literal not found", s.Path, line, inlineFuncName)
+	}
+
+	oldLines := strings.Count(lit.Value, "\n")
+	lit.Value = quoteInlineSnapshot(string(data))
+
+	var buf bytes.Buffer
+
+	if err := format.Node(&buf, fset, file); err != nil {
+		return fmt.Errorf("failed to format source file: %w", err)
+	}
+
+	if err := afero.WriteFile(s.Fs, s.Path, buf.Bytes(), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write source file: %w", err)
+	}
+
+	inlineEdits[s.Path] = append(inlineEdits[s.Path], inlineEdit{
+		line:  s.Line,
+		delta: strings.Count(lit.Value, "\n") - oldLines,
+	})
+	s.Snapshot = string(data)
+
+	return nil
+}
+
+// findInlineSnapshot returns the string literal passed to the innermost MatchInline call
+// spanning line.
+func findInlineSnapshot(fset *token.FileSet, file *ast.File, line int) *ast.BasicLit {
+	var result *ast.BasicLit
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 || !isInlineFunc(call.Fun) {
+			return true
+		}
+
+		if fset.Position(call.Pos()).Line > line || fset.Position(call.End()).Line < line {
+			return true
+		}
+
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			result = lit
+		}
+
+		return true
+	})
+
+	return result
+}
+
+func isInlineFunc(expr ast.Expr) bool {
+	switch fn := expr.(type) {
+	case *ast.Ident:
+		return fn.Name == inlineFuncName
+	case *ast.SelectorExpr:
+		return fn.Sel.Name == inlineFuncName
+	default:
+		return false
+	}
+}
+
+// quoteInlineSnapshot returns a raw string literal, or an interpreted one if the content cannot
+// be represented as a raw string.
+func quoteInlineSnapshot(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+
+	return "`" + s + "`"
+}
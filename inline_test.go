@@ -0,0 +1,81 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("InlineStorage", func() {
+	const path = "foo_test.go"
+
+	var fs afero.Fs
+
+	source := "package foo\n\n" +
+		"func TestFoo() {\n" +
+		"\tExpect(a).To(goldga.MatchInline(\"\"))\n" +
+		"\tExpect(b).To(goldga.MatchInline(\"old\"))\n" +
+		"}\n"
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		Expect(afero.WriteFile(fs, path, []byte(source), 0o644)).To(Succeed())
+
+		inlineEditsMu.Lock()
+		inlineEdits = map[string][]inlineEdit{}
+		inlineEditsMu.Unlock()
+	})
+
+	readSource := func() string {
+		content, err := afero.ReadFile(fs, path)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(content)
+	}
+
+	Describe("Read", func() {
+		It("should return not found for an empty snapshot", func() {
+			_, err := (&InlineStorage{}).Read()
+			Expect(err).To(MatchError(afero.ErrFileNotFound))
+		})
+
+		It("should return the snapshot", func() {
+			Expect((&InlineStorage{Snapshot: "foo"}).Read()).To(Equal([]byte("foo")))
+		})
+	})
+
+	Describe("Write", func() {
+		It("should rewrite the call site", func() {
+			storage := &InlineStorage{Path: path, Line: 5, Fs: fs}
+			Expect(storage.Write([]byte("new"))).To(Succeed())
+			Expect(readSource()).To(ContainSubstring("\tExpect(b).To(goldga.MatchInline(`new`))\n"))
+			Expect(storage.Read()).To(Equal([]byte("new")))
+		})
+
+		It("should find later call sites after lines were added", func() {
+			Expect((&InlineStorage{Path: path, Line: 4, Fs: fs}).Write([]byte("a\nb\n"))).To(Succeed())
+			Expect((&InlineStorage{Path: path, Line: 5, Fs: fs}).Write([]byte("c"))).To(Succeed())
+			Expect(readSource()).To(Equal("package foo\n\n" +
+				"func TestFoo() {\n" +
+				"\tExpect(a).To(goldga.MatchInline(`a\nb\n`))\n" +
+				"\tExpect(b).To(goldga.MatchInline(`c`))\n" +
+				"}\n"))
+		})
+
+		It("should quote content containing backquotes", func() {
+			Expect((&InlineStorage{Path: path, Line: 4, Fs: fs}).Write([]byte("`a`"))).To(Succeed())
+			Expect(readSource()).To(ContainSubstring("goldga.MatchInline(\"`a`\")"))
+		})
+
+		It("should fail if the call is not found", func() {
+			Expect((&InlineStorage{Path: path, Line: 1, Fs: fs}).Write([]byte("a"))).To(MatchError(ContainSubstring("MatchInline call")))
+		})
+	})
+})
+
+var _ = Describe("MatchInline", func() {
+	It("should match the inline snapshot", func() {
+		Expect("foo").To(MatchInline(`(string) (len=3) "foo"
+`))
+	})
+})
@@ -0,0 +1,158 @@
+package goldga
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const jsonPathWildcard = "*"
+
+// jsonPathSegment is an object key or an array index of a path such as "items[0].id", used by
+// IgnoringPaths and JSONComparer. A wildcard matches every key or every index.
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func jsonPathKey(key string) jsonPathSegment {
+	return jsonPathSegment{key: key}
+}
+
+func jsonPathIndex(index int) jsonPathSegment {
+	return jsonPathSegment{isIndex: true, index: index}
+}
+
+func (s jsonPathSegment) matchKey(key string) bool {
+	return !s.isIndex && (s.wildcard || s.key == key)
+}
+
+func (s jsonPathSegment) matchIndex(index int) bool {
+	return s.isIndex && (s.wildcard || s.index == index)
+}
+
+func (s jsonPathSegment) match(other jsonPathSegment) bool {
+	if other.isIndex {
+		return s.matchIndex(other.index)
+	}
+
+	return s.matchKey(other.key)
+}
+
+// parseJSONPath parses a path like "items[*].id". A "*" segment matches every key of an object
+// and "[*]" matches every element of an array.
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	var segments []jsonPathSegment
+
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			key = part[:i]
+			part = part[i:]
+		} else {
+			part = ""
+		}
+
+		if key == "" && len(segments) > 0 {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+
+		if key != "" {
+			segments = append(segments, jsonPathSegment{key: key, wildcard: key == jsonPathWildcard})
+		}
+
+		for part != "" {
+			end := strings.IndexByte(part, ']')
+			if part[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+
+			index := part[1:end]
+			part = part[end+1:]
+
+			if index == jsonPathWildcard {
+				segments = append(segments, jsonPathSegment{isIndex: true, wildcard: true})
+
+				continue
+			}
+
+			n, err := strconv.Atoi(index)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", index, path)
+			}
+
+			segments = append(segments, jsonPathIndex(n))
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid path %q", path)
+	}
+
+	return segments, nil
+}
+
+func parseJSONPaths(paths []string) ([][]jsonPathSegment, error) {
+	parsed := make([][]jsonPathSegment, len(paths))
+
+	for i, path := range paths {
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed[i] = segments
+	}
+
+	return parsed, nil
+}
+
+func formatJSONPath(segments []jsonPathSegment) string {
+	var sb strings.Builder
+
+	for i, segment := range segments {
+		if segment.isIndex {
+			fmt.Fprintf(&sb, "[%d]", segment.index)
+
+			continue
+		}
+
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+
+		sb.WriteString(segment.key)
+	}
+
+	if sb.Len() == 0 {
+		return "$"
+	}
+
+	return sb.String()
+}
+
+func matchJSONPath(pattern, segments []jsonPathSegment) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+
+	for i, p := range pattern {
+		if !p.match(segments[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isJSONPathIgnored(ignores [][]jsonPathSegment, segments []jsonPathSegment) bool {
+	for _, pattern := range ignores {
+		if matchJSONPath(pattern, segments) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// ErrSnapshotTooLarge is returned when a snapshot exceeds the size set by WithMaxSize.
+	ErrSnapshotTooLarge = errors.New("snapshot is too large")
+	// ErrForbiddenPattern is returned when a snapshot matches a pattern set by
+	// WithForbiddenPatterns.
+	ErrForbiddenPattern = errors.New("snapshot contains forbidden pattern")
+)
+
+// DefaultForbiddenPatterns match common secrets and machine-specific paths.
+// nolint: gochecknoglobals
+var DefaultForbiddenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`\b(gh[pousr]_[0-9A-Za-z]{36}|sk_live_[0-9A-Za-z]{24,})\b`),
+	regexp.MustCompile(`(?i)\bbearer\s+[0-9A-Za-z\-._~+/]{16,}=*`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`(/home/[^/\s]+/|/Users/[^/\s]+/|[A-Za-z]:\\Users\\[^\\\s]+\\)`),
+}
+
+// Linter checks a snapshot before it is written to a golden file.
+type Linter func(content []byte) error
+
+// WithLinter adds a check run before a golden file is written. The match fails with the error
+// of the linter, unless WithLintWarnings is used.
+func WithLinter(linter Linter) Option {
+	return func(matcher *Matcher) {
+		matcher.Linters = append(matcher.Linters, linter)
+	}
+}
+
+// WithLintWarnings calls warn with lint errors instead of failing the match.
+func WithLintWarnings(warn func(err error)) Option {
+	return func(matcher *Matcher) {
+		matcher.OnLintWarning = warn
+	}
+}
+
+// WithMaxSize fails when a snapshot larger than size bytes is written.
+func WithMaxSize(size int) Option {
+	return WithLinter(func(content []byte) error {
+		if len(content) > size {
+			return fmt.Errorf("%w: %d bytes, maximum is %d", ErrSnapshotTooLarge, len(content), size)
+		}
+
+		return nil
+	})
+}
+
+// WithForbiddenPatterns fails when a snapshot matching any of patterns is written. Use
+// DefaultForbiddenPatterns to detect API keys, bearer tokens, private keys and home directories.
+func WithForbiddenPatterns(patterns ...*regexp.Regexp) Option {
+	return WithLinter(func(content []byte) error {
+		for _, pattern := range patterns {
+			if loc := pattern.FindIndex(content); loc != nil {
+				line := 1 + bytes.Count(content[:loc[0]], []byte("\n"))
+
+				return fmt.Errorf("%w %q at line %d", ErrForbiddenPattern, pattern.String(), line)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (m *Matcher) lint(content []byte) error {
+	for _, linter := range m.Linters {
+		if err := linter(content); err != nil {
+			if m.OnLintWarning == nil {
+				return err
+			}
+
+			m.OnLintWarning(err)
+		}
+	}
+
+	return nil
+}
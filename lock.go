@@ -0,0 +1,90 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	lockExt           = ".lock"
+	lockRetryInterval = 10 * time.Millisecond
+	lockTimeout       = 30 * time.Second
+	lockStaleAge      = time.Minute
+)
+
+// lockFile acquires a lock by creating a lock file exclusively, which works across processes such
+// as parallel Ginkgo workers. A lock file older than lockStaleAge is considered abandoned by a
+// crashed process and removed. The lock file contains the PID of its owner and the time it was
+// acquired, so a lock is only removed by the owner or while it is still the stale one.
+func lockFile(fs afero.Fs, path string) (func(), error) {
+	if err := fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	start := time.Now()
+	deadline := start.Add(lockTimeout)
+
+	debugf("acquire lock %s", path)
+
+	for {
+		file, err := fs.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			token := []byte(fmt.Sprintf("%d %d\n", os.Getpid(), time.Now().UnixNano()))
+			_, _ = file.Write(token)
+			_ = file.Close()
+
+			debugf("acquired lock %s after %s", path, time.Since(start))
+
+			return func() {
+				// The lock may have been taken over as stale, keep the lock of the new owner.
+				if current, err := afero.ReadFile(fs, path); err == nil && bytes.Equal(current, token) {
+					_ = fs.Remove(path)
+				}
+
+				debugf("released lock %s", path)
+			}, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if removeStaleLock(fs, path) {
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock file %s", path)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// removeStaleLock removes the lock file at path if it is older than lockStaleAge. Another process
+// may remove the same stale lock and acquire a new one in the meantime, so the content is read
+// again after the age check and the file is only removed if it is still the stale lock.
+func removeStaleLock(fs afero.Fs, path string) bool {
+	stale, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return false
+	}
+
+	if info, err := fs.Stat(path); err != nil || time.Since(info.ModTime()) <= lockStaleAge {
+		return false
+	}
+
+	if current, err := afero.ReadFile(fs, path); err != nil || !bytes.Equal(current, stale) {
+		return false
+	}
+
+	debugf("remove stale lock %s", path)
+
+	return fs.Remove(path) == nil
+}
@@ -0,0 +1,145 @@
+package goldga
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("lockFile", func() {
+	var fs afero.Fs
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+	})
+
+	It("should wait until the lock is released", func() {
+		unlock, err := lockFile(fs, "foo.lock")
+		Expect(err).NotTo(HaveOccurred())
+
+		acquired := make(chan struct{})
+
+		go func() {
+			defer GinkgoRecover()
+
+			unlock, err := lockFile(fs, "foo.lock")
+			Expect(err).NotTo(HaveOccurred())
+			close(acquired)
+			unlock()
+		}()
+
+		Consistently(acquired, 50*time.Millisecond).ShouldNot(BeClosed())
+		unlock()
+		Eventually(acquired).Should(BeClosed())
+	})
+
+	It("should remove stale lock files", func() {
+		Expect(afero.WriteFile(fs, "foo.lock", []byte("1"), os.ModePerm)).To(Succeed())
+		stale := time.Now().Add(-2 * lockStaleAge)
+		Expect(fs.Chtimes("foo.lock", stale, stale)).To(Succeed())
+
+		unlock, err := lockFile(fs, "foo.lock")
+		Expect(err).NotTo(HaveOccurred())
+		unlock()
+		Expect(afero.Exists(fs, "foo.lock")).To(BeFalse())
+	})
+
+	It("should not remove a lock acquired after the staleness check", func() {
+		Expect(afero.WriteFile(fs, "foo.lock", []byte("1 1\n"), os.ModePerm)).To(Succeed())
+		stale := time.Now().Add(-2 * lockStaleAge)
+		Expect(fs.Chtimes("foo.lock", stale, stale)).To(Succeed())
+
+		replacing := &replacingFs{Fs: fs, path: "foo.lock", content: []byte("2 2\n")}
+		Expect(removeStaleLock(replacing, "foo.lock")).To(BeFalse())
+		Expect(afero.ReadFile(fs, "foo.lock")).To(Equal([]byte("2 2\n")))
+	})
+
+	It("should not release a lock taken over by another process", func() {
+		unlock, err := lockFile(fs, "foo.lock")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(afero.WriteFile(fs, "foo.lock", []byte("2 2\n"), os.ModePerm)).To(Succeed())
+		unlock()
+		Expect(afero.ReadFile(fs, "foo.lock")).To(Equal([]byte("2 2\n")))
+	})
+})
+
+// replacingFs replaces the file at path after it is stat, as if another process removed the
+// stale lock and acquired a new one.
+type replacingFs struct {
+	afero.Fs
+	path    string
+	content []byte
+}
+
+func (r *replacingFs) Stat(name string) (os.FileInfo, error) {
+	info, err := r.Fs.Stat(name)
+
+	if name == r.path {
+		_ = afero.WriteFile(r.Fs, r.path, r.content, os.ModePerm)
+	}
+
+	return info, err
+}
+
+// slowFs widens the window between reading and writing a file to make races observable.
+type slowFs struct {
+	afero.Fs
+}
+
+func (s *slowFs) Create(name string) (afero.File, error) {
+	time.Sleep(time.Millisecond)
+
+	return s.Fs.Create(name)
+}
+
+var _ = Describe("SuiteStorage concurrent writes", func() {
+	var (
+		temp *tempFs
+		fs   *slowFs
+	)
+
+	BeforeEach(func() {
+		temp = newTempFs()
+		fs = &slowFs{Fs: temp}
+	})
+
+	AfterEach(func() {
+		temp.Teardown()
+	})
+
+	It("should keep snapshots written in parallel", func() {
+		var wg sync.WaitGroup
+
+		path := filepath.Join(temp.path, "suite.golden")
+		expected := map[string]string{}
+
+		for i := 0; i < 20; i++ {
+			name := fmt.Sprintf("snapshot %d", i)
+			expected[name] = name
+
+			wg.Add(1)
+
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				storage := &SuiteStorage{
+					Path: path,
+					Name: name,
+					Fs:   fs,
+				}
+				Expect(storage.Write([]byte(name))).To(Succeed())
+			}()
+		}
+
+		wg.Wait()
+		Expect(ParseSuite(mustReadFile(fs, path))).To(Equal(expected))
+	})
+})
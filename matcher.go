@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/onsi/gomega/types"
 	"github.com/spf13/afero"
@@ -13,15 +17,30 @@ import (
 
 type Option func(*Matcher)
 
+const subNameSeparator = " - "
+
 // WithDescription adds an optional description to the golden file, allowing multiple gold files per test.
 func WithDescription(description string) Option {
 	return func(matcher *Matcher) {
-		if s, ok := matcher.Storage.(*SuiteStorage); ok {
-			s.Name = fmt.Sprintf("%s (%s)", s.Name, description)
-		}
+		matcher.Storage = renameStorage(matcher.Storage, func(name string) string {
+			return fmt.Sprintf("%s (%s)", name, description)
+		})
 	}
 }
 
+// Named appends a sub-name to the snapshot name, so a single test can own multiple snapshots.
+func Named(name string) Option {
+	return func(matcher *Matcher) {
+		matcher.Storage = withSubName(matcher.Storage, name)
+	}
+}
+
+func withSubName(storage Storage, sub string) Storage {
+	return renameStorage(storage, func(name string) string {
+		return name + subNameSeparator + sub
+	})
+}
+
 // WithSerializer overrides the default serializer.
 func WithSerializer(serializer Serializer) Option {
 	return func(matcher *Matcher) {
@@ -29,6 +48,14 @@ func WithSerializer(serializer Serializer) Option {
 	}
 }
 
+// WithSerializeOptions sets the options passed to the serializer, such as the indent width or the
+// time format.
+func WithSerializeOptions(options SerializeOptions) Option {
+	return func(matcher *Matcher) {
+		matcher.SerializeOptions = options
+	}
+}
+
 // WithTransformer overrides the default transformer.
 func WithTransformer(transformer Transformer) Option {
 	return func(matcher *Matcher) {
@@ -43,6 +70,38 @@ func WithStorage(storage Storage) Option {
 	}
 }
 
+// WithComparer overrides the default comparer.
+func WithComparer(comparer Comparer) Option {
+	return func(matcher *Matcher) {
+		matcher.Comparer = comparer
+	}
+}
+
+// WithJSONComparison compares JSON content structurally and shows only the differing paths on mismatch.
+// Values at ignorePaths (e.g. "items[*].id") are excluded from comparison.
+func WithJSONComparison(ignorePaths ...string) Option {
+	return func(matcher *Matcher) {
+		comparer := &JSONComparer{IgnorePaths: ignorePaths}
+
+		// Keep the tolerance of a previous WithFloatTolerance.
+		if numeric, ok := matcher.Comparer.(*NumericComparer); ok {
+			comparer.Tolerance = numeric.Tolerance
+		}
+
+		matcher.Comparer = comparer
+		matcher.Differ = comparer
+	}
+}
+
+// WithUnifiedDiff shows a unified diff with the given number of context lines on mismatch.
+func WithUnifiedDiff(context int) Option {
+	if context < 0 {
+		context = 0
+	}
+
+	return WithDiffer(&UnifiedDiffer{Context: context})
+}
+
 // WithDiffer overrides the default differ.
 func WithDiffer(differ Differ) Option {
 	return func(matcher *Matcher) {
@@ -50,25 +109,114 @@ func WithDiffer(differ Differ) Option {
 	}
 }
 
+// WithNormalizedJSONNumbers rewrites numbers in JSON content in a canonical form before comparing and storing.
+// Numbers are rounded to precision decimals, or kept in their shortest representation if precision is 0.
+func WithNormalizedJSONNumbers(precision int) Option {
+	return func(matcher *Matcher) {
+		matcher.NormalizeJSONNumbers = true
+		matcher.JSONNumberPrecision = precision
+	}
+}
+
+// WithNormalizedLineEndings converts CRLF line endings to LF before comparing and storing, so
+// golden files are always written with "\n".
+func WithNormalizedLineEndings() Option {
+	return func(matcher *Matcher) {
+		matcher.NormalizeLineEndings = true
+	}
+}
+
+// WithNormalizedPathSeparators converts OS-specific path separators to "/" before comparing and
+// storing. It only has an effect on platforms where the separator is not "/". On Windows, only
+// absolute paths (e.g. "C:\foo\bar") are converted, so other backslashes are kept.
+func WithNormalizedPathSeparators() Option {
+	return func(matcher *Matcher) {
+		matcher.NormalizePathSeparators = true
+	}
+}
+
+// WithMaxLineWidth calls warn for every line wider than width when a golden file is written.
+// It is only a warning and does not fail the match.
+func WithMaxLineWidth(width int, warn func(line, width int)) Option {
+	return func(matcher *Matcher) {
+		matcher.MaxLineWidth = width
+		matcher.OnLongLine = warn
+	}
+}
+
+// WithApprover asks the approver whether to update the golden file on mismatch.
+func WithApprover(approver Approver) Option {
+	return func(matcher *Matcher) {
+		matcher.Approver = approver
+	}
+}
+
+func getUpdateMode() string {
+	return strings.ToLower(os.Getenv("GOLDGA_UPDATE"))
+}
+
 func getUpdateFile() bool {
 	update, _ := strconv.ParseBool(os.Getenv("UPDATE_GOLDEN"))
 
 	return update
 }
 
+// getGoldenPath returns the path of the golden file for the given test file.
+func getGoldenPath(path string) string {
+	name := filepath.Base(path)
+
+	if ext := filepath.Ext(name); ext != "" {
+		name = strings.TrimSuffix(name, ext)
+		name = strings.TrimSuffix(name, "_test")
+	}
+
+	return filepath.Join("testdata", name+".golden")
+}
+
 func Match(options ...Option) *Matcher {
+	return newMatcherWithInfo(getGinkgoPath(), getGinkgoNameInfo(), options...)
+}
+
+func newMatcher(path, name string, options ...Option) *Matcher {
+	return newMatcherWithInfo(path, NameInfo{FullText: name, Texts: []string{name}}, options...)
+}
+
+func newMatcherWithInfo(path string, info NameInfo, options ...Option) *Matcher {
+	name := info.FullText
 	m := &Matcher{
 		Serializer:  DefaultSerializer,
 		Transformer: DefaultTransformer,
+		Comparer:    DefaultComparer,
 		Storage: &SuiteStorage{
-			Path: getGinkgoPath(),
-			Name: getGinkgoTestName(),
-			Fs:   defaultFs,
+			Path:          path,
+			Name:          name,
+			Fs:            defaultFs,
+			DecodeRetries: defaultDecodeRetries,
 		},
-		Differ:     DefaultDiffer,
-		UpdateFile: getUpdateFile(),
+		Differ:       DefaultDiffer,
+		UpdateFile:   getUpdateFile(),
+		UpdatePolicy: getUpdatePolicy(),
+		Pending:      getUpdateMode() == updateModePending,
+		ReceivedDir:  os.Getenv(receivedDirEnv),
+		nameInfo:     info,
+	}
+
+	m.WriteReceived = m.ReceivedDir != ""
+	m.GitAwareUpdate, m.GitBase = getGitAwareUpdate()
+	m.DryRun = getUpdateMode() == updateModeDryRun
+
+	if getUpdateMode() == updateModeInteractive {
+		m.Approver = stdinApprover
 	}
-	for _, option := range options {
+
+	// An invalid configuration is returned by Match, so only the affected tests fail.
+	configOptions, err := getConfigOptions(filepath.Dir(path))
+	if err != nil {
+		m.configErr = err
+	}
+
+	// Options passed to the matcher override the project configuration.
+	for _, option := range append(append([]Option{}, configOptions...), options...) {
 		option(m)
 	}
 
@@ -80,51 +228,335 @@ var _ types.GomegaMatcher = (*Matcher)(nil)
 type Matcher struct {
 	Serializer  Serializer
 	Transformer Transformer
+	Comparer    Comparer
 	Storage     Storage
 	Differ      Differ
 	UpdateFile  bool
+
+	// SerializeOptions are passed to serializers implementing SerializerV2.
+	SerializeOptions SerializeOptions
+
+	// UpdatePolicy controls when golden files are written. UpdateFile = true is the same as
+	// UpdatePolicyAlways.
+	UpdatePolicy UpdatePolicy
+
+	// GitAwareUpdate only updates snapshots of packages with changes according to git, see
+	// WithGitAwareUpdate.
+	GitAwareUpdate bool
+	GitBase        string
+
+	// DryRun compares snapshots without writing any file, see WithDryRun.
+	DryRun bool
+
+	// Namespace prefixes snapshots and enables collision detection, see WithNamespace.
+	Namespace string
+
+	NormalizeJSONNumbers    bool
+	JSONNumberPrecision     int
+	NormalizeLineEndings    bool
+	NormalizePathSeparators bool
+	Scrubbers               []Scrubber
+
+	MaxLineWidth int
+	OnLongLine   func(line, width int)
+
+	// Linters check snapshots before they are written. Errors fail the match, or are passed to
+	// OnLintWarning if set.
+	Linters       []Linter
+	OnLintWarning func(err error)
+
+	// Approver is asked whether to update the golden file when it does not match.
+	Approver Approver
+
+	// Pending writes snapshots to the pending storage of a StagingStorage instead.
+	Pending bool
+
+	// Filters select the part of the content to compare. The whole content is still written.
+	Filters []func(content []byte) []byte
+
+	// Streaming compares content chunk by chunk with a StreamStorage.
+	Streaming      bool
+	streamMismatch int64
+	streamReceived []byte
+	streamSize     int64
+
+	// WriteReceived writes the actual content to a ".received" file on mismatch, in ReceivedDir
+	// if set or next to the golden file otherwise.
+	WriteReceived bool
+	ReceivedDir   string
+	receivedPath  string
+
+	// FailureFormatter builds the failure message if set.
+	FailureFormatter FailureFormatter
+
+	// TrackVerification records the date the snapshot last matched in suite file metadata.
+	TrackVerification bool
+
+	nameInfo     NameInfo
+	nameProvider NameProvider
+	nameErr      error
+	configErr    error
+
+	// The content compared by the last Match, reused for the failure message because the
+	// actual value may not be serializable twice, e.g. *sql.Rows.
+	matched         bool
+	expectedContent []byte
+	actualContent   []byte
 }
 
 func (m *Matcher) Match(actual interface{}) (bool, error) {
+	if m.configErr != nil {
+		return false, m.configErr
+	}
+
+	if m.nameErr != nil {
+		return false, m.nameErr
+	}
+
+	if err := m.restrictUpdateToChanges(); err != nil {
+		return false, err
+	}
+
+	if err := m.claimSnapshot(); err != nil {
+		return false, err
+	}
+
+	recordMatchUsage(m.Storage)
+
+	m.debugf("match with update policy %s", m.effectiveUpdatePolicy())
+
+	m.matched, m.expectedContent, m.actualContent = false, nil, nil
+
+	if m.Streaming {
+		return m.matchStream(actual)
+	}
+
 	actualContent, err := m.getActualContent(actual)
 	if err != nil {
 		return false, fmt.Errorf("failed to get actual content: %w", err)
 	}
 
+	m.matched, m.actualContent = true, actualContent
+
 	expected, err := m.getExpectedContent()
 	if err != nil {
 		if !errors.Is(err, afero.ErrFileNotFound) {
 			return false, fmt.Errorf("failed to get expected content: %w", err)
 		}
 
-		if err := m.Storage.Write(actualContent); err != nil {
-			return false, fmt.Errorf("faield to write file: %w", err)
+		if m.DryRun {
+			m.debugf("missing, would be created")
+			recordDryRunChange(m.Storage, DryRunCreate)
+			recordStat(m.Storage, statMismatched, false, actualContent)
+			recordFailure(m.Storage, nil, m.filter(actualContent))
+
+			return false, fmt.Errorf("dry run: %w", errGoldenFileWouldBeCreated)
+		}
+
+		if m.UpdatePolicy == UpdatePolicyNever {
+			m.debugf("missing and not created")
+			recordStat(m.Storage, statMismatched, false, actualContent)
+			recordFailure(m.Storage, nil, m.filter(actualContent))
+
+			if err := m.writeReceived(actualContent); err != nil {
+				return false, err
+			}
+
+			return false, m.missingGoldenFileError()
+		}
+
+		if err := m.checkStrictNoWrite(); err != nil {
+			return false, err
+		}
+
+		if err := m.write(actualContent); err != nil {
+			return false, err
+		}
+
+		if m.Pending {
+			m.debugf("staged pending snapshot")
+			recordStat(m.Storage, statMismatched, false, actualContent)
+			recordFailure(m.Storage, nil, m.filter(actualContent))
+
+			return false, ErrSnapshotPending
+		}
+
+		if m.UpdateFile || m.UpdatePolicy == UpdatePolicyAlways {
+			m.debugf("updated")
+			recordStat(m.Storage, statUpdated, false, actualContent)
+		} else {
+			m.debugf("created")
+			recordStat(m.Storage, statCreated, false, actualContent)
 		}
 
 		return true, nil
 	}
 
-	return bytes.Equal(expected, actualContent), nil
+	m.expectedContent = expected
+
+	equal, err := m.comparer().Compare(m.filter(expected), m.filter(actualContent))
+	if err != nil {
+		return false, fmt.Errorf("compare error: %w", err)
+	}
+
+	if equal {
+		m.debugf("matched")
+		recordStat(m.Storage, statMatched, true, actualContent)
+
+		if m.DryRun {
+			return true, nil
+		}
+
+		return true, m.markVerified()
+	}
+
+	if m.DryRun {
+		m.debugf("would be modified")
+		recordDryRunChange(m.Storage, DryRunModify)
+		recordStat(m.Storage, statMismatched, true, actualContent)
+		recordFailure(m.Storage, m.filter(expected), m.filter(actualContent))
+
+		return false, nil
+	}
+
+	if m.UpdatePolicy != UpdatePolicyNever {
+		if m.Pending {
+			if err := m.write(actualContent); err != nil {
+				return false, err
+			}
+
+			m.debugf("staged pending update")
+		} else if m.Approver != nil {
+			if success, err := m.approve(expected, actualContent); success || err != nil {
+				return success, err
+			}
+		}
+	}
+
+	m.debugf("mismatched")
+	recordStat(m.Storage, statMismatched, true, actualContent)
+	recordFailure(m.Storage, m.filter(expected), m.filter(actualContent))
+
+	return false, m.writeReceived(actualContent)
 }
 
-func (m *Matcher) getMessage(actual interface{}, message string) string {
-	expectedContent, err := m.getExpectedContent()
+// Sub returns a copy of the matcher whose snapshot name is suffixed with name.
+func (m *Matcher) Sub(name string) *Matcher {
+	sub := *m
+	sub.Storage = withSubName(m.Storage, name)
+
+	return &sub
+}
+
+func (m *Matcher) write(content []byte) error {
+	m.checkLineWidth(content)
+
+	if err := m.lint(content); err != nil {
+		return err
+	}
+
+	storage := m.Storage
+
+	if m.Pending {
+		if _, ok := innerStorage(storage).(StagingStorage); !ok {
+			return fmt.Errorf("storage %T does not support pending updates", innerStorage(storage))
+		}
+
+		storage = pendingStorage(storage)
+	}
+
+	defer allowStrictWrites()()
+
+	if err := storage.Write(content); err != nil {
+		return fmt.Errorf("faield to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Matcher) approve(expected, actual []byte) (bool, error) {
+	decision, err := m.Approver.Approve(getStorageName(m.Storage), m.Differ.Diff(m.filter(expected), m.filter(actual)))
 	if err != nil {
-		panic(err)
+		return false, fmt.Errorf("approve error: %w", err)
+	}
+
+	m.debugf("approver decided to %s", decision)
+
+	switch decision {
+	case DecisionAccept:
+		if err := m.write(actual); err != nil {
+			return false, err
+		}
+
+		recordStat(m.Storage, statUpdated, true, actual)
+
+		return true, nil
+	case DecisionSkip:
+		recordStat(m.Storage, statSkipped, true, actual)
+		recordFailure(m.Storage, m.filter(expected), m.filter(actual))
+
+		return false, ErrSnapshotSkipped
+	default:
+		return false, nil
+	}
+}
+
+func (m *Matcher) getMessage(actual interface{}, message string) string {
+	var diff []byte
+
+	if m.Streaming {
+		streamDiff, err := m.getStreamDiff()
+		if err != nil {
+			return fmt.Sprintf("Expected %s match the golden file\n%v", message, err)
+		}
+
+		diff = streamDiff
+	} else {
+		expectedContent, actualContent, err := m.getMessageContent(actual)
+		if err != nil {
+			return fmt.Sprintf("Expected %s match the golden file\n%v", message, err)
+		}
+
+		diff = m.differ().Diff(m.filter(expectedContent), m.filter(actualContent))
+	}
+
+	info := FailureInfo{
+		Negated:      message != "to",
+		Name:         getStorageName(m.Storage),
+		Path:         getStoragePath(m.Storage),
+		Diff:         diff,
+		ReceivedPath: m.receivedPath,
+	}
+
+	if m.FailureFormatter != nil {
+		return m.FailureFormatter.FormatFailure(info)
+	}
+
+	return info.DefaultMessage()
+}
+
+// getMessageContent returns the content compared by the last Match, or reads and serializes it
+// again if Match was not called.
+func (m *Matcher) getMessageContent(actual interface{}) ([]byte, []byte, error) {
+	if m.matched {
+		return m.expectedContent, m.actualContent, nil
+	}
+
+	expected, err := m.getExpectedContent()
+	if err != nil && !errors.Is(err, afero.ErrFileNotFound) {
+		return nil, nil, fmt.Errorf("failed to get expected content: %w", err)
 	}
 
 	actualContent, err := m.getActualContent(actual)
 	if err != nil {
-		panic(err)
+		return nil, nil, fmt.Errorf("failed to get actual content: %w", err)
 	}
 
-	return fmt.Sprintf("Expected %s match the golden file\n%s",
-		message,
-		m.Differ.Diff(expectedContent, actualContent))
+	return expected, actualContent, nil
 }
 
 func (m *Matcher) getExpectedContent() ([]byte, error) {
-	if m.UpdateFile {
+	if !m.DryRun && (m.UpdateFile || m.UpdatePolicy == UpdatePolicyAlways) {
 		return nil, afero.ErrFileNotFound
 	}
 
@@ -133,21 +565,105 @@ func (m *Matcher) getExpectedContent() ([]byte, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return data, nil
+	return m.normalize(data), nil
 }
 
 func (m *Matcher) getActualContent(actual interface{}) ([]byte, error) {
 	var buf bytes.Buffer
+
+	if err := m.serialize(&buf, actual); err != nil {
+		return nil, err
+	}
+
+	return m.normalize(buf.Bytes()), nil
+}
+
+// serialize transforms and serializes actual into w.
+func (m *Matcher) serialize(w io.Writer, actual interface{}) error {
+	if r, ok := actual.(*Recorder); ok {
+		return m.serializeRecorder(w, r)
+	}
+
 	transformed, err := m.Transformer.Transform(actual)
 	if err != nil {
-		return nil, fmt.Errorf("transform error: %w", err)
+		return fmt.Errorf("transform error: %w", err)
 	}
 
-	if err := m.Serializer.Serialize(&buf, transformed); err != nil {
-		return nil, fmt.Errorf("serialize error: %w", err)
+	serializer := m.Serializer
+
+	if serializer == DefaultSerializer {
+		if registered, ok := lookupSerializer(transformed); ok {
+			serializer = registered
+		}
+	}
+
+	if err := AdaptSerializer(serializer).SerializeWithOptions(w, transformed, m.SerializeOptions); err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Matcher) checkLineWidth(content []byte) {
+	if m.MaxLineWidth <= 0 || m.OnLongLine == nil {
+		return
+	}
+
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		if width := utf8.RuneCount(line); width > m.MaxLineWidth {
+			m.OnLongLine(i+1, width)
+		}
+	}
+}
+
+func (m *Matcher) normalize(content []byte) []byte {
+	if m.NormalizeLineEndings {
+		content = normalizeLineEndings(content)
+	}
+
+	if m.NormalizePathSeparators {
+		content = normalizePathSeparators(content, os.PathSeparator)
+	}
+
+	if m.NormalizeJSONNumbers {
+		content = normalizeJSONNumbers(content, m.JSONNumberPrecision)
+	}
+
+	for _, scrubber := range m.Scrubbers {
+		content = scrubber.Scrub(content)
+	}
+
+	return content
+}
+
+// comparer returns the Comparer of the matcher, or DefaultComparer if it is not set, e.g. when
+// the matcher was created as a struct literal.
+func (m *Matcher) comparer() Comparer {
+	if m.Comparer == nil {
+		return DefaultComparer
+	}
+
+	if c, ok := m.Comparer.(*ImageComparer); ok && m.DryRun {
+		return c.withoutDiffImage()
+	}
+
+	return m.Comparer
+}
+
+func (m *Matcher) differ() Differ {
+	if c, ok := m.Differ.(*ImageComparer); ok && m.DryRun {
+		return c.withoutDiffImage()
+	}
+
+	return m.Differ
+}
+
+func (m *Matcher) filter(content []byte) []byte {
+	for _, filter := range m.Filters {
+		content = filter(content)
 	}
 
-	return buf.Bytes(), nil
+	return content
 }
 
 func (m *Matcher) FailureMessage(actual interface{}) string {
@@ -3,6 +3,7 @@ package goldga
 import (
 	"bytes"
 	"errors"
+	"strings"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
@@ -120,11 +121,8 @@ var _ = Describe("Matcher", func() {
 
 			testFail()
 
+			// The content compared by Match is reused, so the storage is not read again.
 			Context("failure message", func() {
-				BeforeEach(func() {
-					storage.EXPECT().Read().Return([]byte{}, nil)
-				})
-
 				It("positive", func() {
 					Expect(matcher.FailureMessage(actual)).To(HavePrefix("Expected to match the golden file"))
 				})
@@ -144,6 +142,185 @@ var _ = Describe("Matcher", func() {
 		testUpdateFile()
 	})
 
+	When("NormalizeJSONNumbers = true", func() {
+		BeforeEach(func() {
+			WithNormalizedJSONNumbers(0)(matcher)
+			matcher.Serializer = &StringSerializer{}
+			actual = `{"a": 1.0}`
+		})
+
+		When("numbers are equal", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return([]byte(`{"a": 1.00}`), nil)
+			})
+
+			testSucceed()
+		})
+
+		When("numbers are different", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return([]byte(`{"a": 1.01}`), nil)
+			})
+
+			testFail()
+		})
+
+		When("golden file does not exist", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return(nil, afero.ErrFileNotFound)
+				storage.EXPECT().Write([]byte(`{"a": 1}`)).Return(nil)
+			})
+
+			testSucceed()
+		})
+	})
+
+	When("NormalizeLineEndings = true", func() {
+		BeforeEach(func() {
+			matcher.NormalizeLineEndings = true
+			matcher.Serializer = &StringSerializer{}
+			actual = "a\r\nb\n"
+		})
+
+		When("golden file has LF line endings", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return([]byte("a\nb\n"), nil)
+			})
+
+			testSucceed()
+		})
+
+		When("golden file does not exist", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return(nil, afero.ErrFileNotFound)
+				storage.EXPECT().Write([]byte("a\nb\n")).Return(nil)
+			})
+
+			testSucceed()
+		})
+	})
+
+	When("Scrubbers are set", func() {
+		BeforeEach(func() {
+			ScrubUUIDs()(matcher)
+			matcher.Serializer = &StringSerializer{}
+			actual = "id: 123e4567-e89b-12d3-a456-426614174000"
+		})
+
+		When("golden file contains another UUID", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return([]byte("id: 00000000-0000-0000-0000-000000000000"), nil)
+			})
+
+			testSucceed()
+		})
+
+		When("golden file does not exist", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return(nil, afero.ErrFileNotFound)
+				storage.EXPECT().Write([]byte("id: <UUID>")).Return(nil)
+			})
+
+			testSucceed()
+		})
+	})
+
+	When("MaxLineWidth is set", func() {
+		var longLines [][2]int
+
+		BeforeEach(func() {
+			longLines = nil
+			WithMaxLineWidth(120, func(line, width int) {
+				longLines = append(longLines, [2]int{line, width})
+			})(matcher)
+			matcher.Serializer = &StringSerializer{}
+			actual = "short\n" + strings.Repeat("x", 500) + "\nshort"
+			storage.EXPECT().Read().Return(nil, afero.ErrFileNotFound)
+			storage.EXPECT().Write(gomock.Any()).Return(nil)
+		})
+
+		testSucceed()
+
+		It("should warn about long lines", func() {
+			Expect(longLines).To(Equal([][2]int{{2, 500}}))
+		})
+	})
+
+	When("Approver is set", func() {
+		var approver *fakeApprover
+
+		BeforeEach(func() {
+			approver = &fakeApprover{}
+			matcher.Approver = approver
+			storage.EXPECT().Read().Return([]byte{}, nil)
+		})
+
+		When("accepted", func() {
+			BeforeEach(func() {
+				approver.decision = DecisionAccept
+				storage.EXPECT().Write(getFileContent()).Return(nil)
+			})
+
+			testSucceed()
+		})
+
+		When("rejected", func() {
+			BeforeEach(func() {
+				approver.decision = DecisionReject
+			})
+
+			testFail()
+		})
+
+		When("skipped", func() {
+			BeforeEach(func() {
+				approver.decision = DecisionSkip
+			})
+
+			testError(MatchError(ErrSnapshotSkipped))
+		})
+	})
+
+	When("UpdatePolicy = Never", func() {
+		BeforeEach(func() {
+			matcher.UpdatePolicy = UpdatePolicyNever
+			matcher.Approver = &fakeApprover{decision: DecisionAccept}
+		})
+
+		When("golden file does not exist", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return(nil, afero.ErrFileNotFound)
+			})
+
+			testError(MatchError(ErrGoldenFileMissing))
+		})
+
+		When("not match", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return([]byte{}, nil)
+			})
+
+			testFail()
+		})
+	})
+
+	When("UpdatePolicy = Always", func() {
+		BeforeEach(func() {
+			matcher.UpdatePolicy = UpdatePolicyAlways
+		})
+
+		testUpdateFile()
+	})
+
+	When("Pending = true and storage does not support it", func() {
+		BeforeEach(func() {
+			matcher.Pending = true
+			storage.EXPECT().Read().Return([]byte{}, nil)
+		})
+
+		testError(MatchError(ContainSubstring("does not support pending updates")))
+	})
+
 	When("failed to read golden file", func() {
 		BeforeEach(func() {
 			storage.EXPECT().Read().Return(nil, errors.New("error"))
@@ -161,4 +338,59 @@ var _ = Describe("Options", func() {
 			Expect("foobar").To(Match(WithDescription("Third Gold File")))
 		})
 	})
+
+	Describe("Named", func() {
+		It("should append a sub-name to the test name", func() {
+			Expect("foo").To(Match(Named("first")))
+			Expect("bar").To(Match(Named("second")))
+		})
+
+		It("should not change other storages", func() {
+			storage := &BlobStorage{}
+			Expect(newMatcher("foo", "foo", WithStorage(storage), Named("first")).Storage).To(BeIdenticalTo(storage))
+		})
+	})
+
+	Describe("Sub", func() {
+		It("should not change the original matcher", func() {
+			fs := afero.NewMemMapFs()
+			m := newMatcher("foo", "foo", WithStorage(&SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}))
+			sub := m.Sub("after-parse")
+
+			Expect(sub.Storage.(*SuiteStorage).Name).To(Equal("foo - after-parse"))
+			Expect(m.Storage.(*SuiteStorage).Name).To(Equal("foo"))
+		})
+	})
+
+	Describe("struct literal", func() {
+		It("should compare bytes without a comparer", func() {
+			storage := &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: afero.NewMemMapFs()}
+			Expect(storage.Write([]byte("foo"))).To(Succeed())
+
+			m := &Matcher{
+				Serializer:  &StringSerializer{},
+				Transformer: DefaultTransformer,
+				Storage:     storage,
+				Differ:      DefaultDiffer,
+			}
+
+			Expect(m.Match("foo")).To(BeTrue())
+			Expect(m.Match("bar")).To(BeFalse())
+		})
+	})
+
+	Describe("WithSerializeOptions", func() {
+		It("should pass options to the serializer", func() {
+			fs := afero.NewMemMapFs()
+			m := newMatcher("foo", "foo",
+				WithStorage(&SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}),
+				WithSerializer(&JSONSerializer{}),
+				WithSerializeOptions(SerializeOptions{Indent: 2}),
+				WithUpdatePolicy(UpdatePolicyCreateOnly),
+			)
+
+			Expect(m.Match([]int{1})).To(BeTrue())
+			Expect(m.Storage.Read()).To(Equal([]byte("[\n  1\n]\n")))
+		})
+	})
 })
@@ -0,0 +1,136 @@
+package goldga
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+)
+
+const suiteFormatVersion = 1
+
+// nolint: gochecknoglobals
+var now = time.Now
+
+type suiteMeta struct {
+	Version   int                     `toml:"version" json:"version" yaml:"version"`
+	GoVersion string                  `toml:"go_version" json:"go_version" yaml:"go_version"`
+	Snapshots map[string]snapshotMeta `toml:"snapshots" json:"snapshots" yaml:"snapshots"`
+}
+
+type snapshotMeta struct {
+	Hash     string    `toml:"hash" json:"hash" yaml:"hash"`
+	Updated  time.Time `toml:"updated" json:"updated" yaml:"updated"`
+	Verified time.Time `toml:"verified" json:"verified" yaml:"verified,omitempty"`
+}
+
+func hashSnapshot(value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// updateMeta updates the metadata of a snapshot. Nothing is changed if the snapshot content did
+// not change, so rewriting a suite file later or with another Go version does not cause churn.
+func (s *suiteData) updateMeta(name string) {
+	hash := hashSnapshot(s.Snapshots[name])
+
+	if s.Meta != nil {
+		if current, ok := s.Meta.Snapshots[name]; ok && current.Hash == hash {
+			return
+		}
+	}
+
+	if s.Meta == nil {
+		s.Meta = &suiteMeta{}
+	}
+
+	if s.Meta.Snapshots == nil {
+		s.Meta.Snapshots = map[string]snapshotMeta{}
+	}
+
+	s.Meta.Version = suiteFormatVersion
+	s.Meta.GoVersion = runtime.Version()
+	s.Meta.Snapshots[name] = snapshotMeta{
+		Hash:     hash,
+		Updated:  now().UTC().Truncate(time.Second),
+		Verified: s.Meta.Snapshots[name].Verified,
+	}
+}
+
+func writeSuiteMeta(w *bufio.Writer, meta *suiteMeta) error {
+	if meta == nil {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "[meta]\nversion = %d\ngo_version = %q\n", meta.Version, meta.GoVersion); err != nil {
+		return fmt.Errorf("meta write error: %w", err)
+	}
+
+	names := make([]string, 0, len(meta.Snapshots))
+
+	for name := range meta.Snapshots {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		m := meta.Snapshots[name]
+
+		if _, err := fmt.Fprintf(w, "[meta.snapshots.%q]\nhash = %q\nupdated = %s\n", name, m.Hash, m.Updated.Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("meta write error: %w", err)
+		}
+
+		if !m.Verified.IsZero() {
+			if _, err := fmt.Fprintf(w, "verified = %s\n", m.Verified.Format(time.RFC3339)); err != nil {
+				return fmt.Errorf("meta write error: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ModifiedSnapshots returns the sorted names of snapshots whose content does not match the hash
+// recorded in metadata, which usually means they were edited by hand. Snapshots without metadata
+// are included as well. It returns nothing if the suite file does not contain metadata.
+func (s *SuiteStorage) ModifiedSnapshots() ([]string, error) {
+	data, err := s.getSuiteData()
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Meta == nil {
+		return nil, nil
+	}
+
+	var modified []string
+
+	for _, name := range data.sortSnapshotKeys() {
+		if m, ok := data.Meta.Snapshots[name]; !ok || m.Hash != hashSnapshot(data.Snapshots[name]) {
+			modified = append(modified, name)
+		}
+	}
+
+	return modified, nil
+}
+
+// SnapshotMetadata returns the metadata of the snapshot with the given name.
+func (s *SuiteStorage) SnapshotMetadata(name string) (hash string, updated time.Time, err error) {
+	data, err := s.getSuiteData()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if data.Meta != nil {
+		if m, ok := data.Meta.Snapshots[name]; ok {
+			return m.Hash, m.Updated, nil
+		}
+	}
+
+	return "", time.Time{}, fmt.Errorf("metadata of snapshot %q not found", name)
+}
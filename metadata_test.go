@@ -0,0 +1,94 @@
+package goldga
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("SuiteStorage metadata", func() {
+	var (
+		fs      afero.Fs
+		storage *SuiteStorage
+		updated time.Time
+	)
+
+	BeforeEach(func() {
+		updated = time.Date(2021, 9, 1, 12, 34, 56, 0, time.UTC)
+		now = func() time.Time { return updated }
+		fs = afero.NewMemMapFs()
+		storage = &SuiteStorage{
+			Path:     "meta.golden",
+			Name:     "a",
+			Fs:       fs,
+			Metadata: true,
+		}
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		now = time.Now
+	})
+
+	It("should write the meta table", func() {
+		Expect(string(mustReadFile(fs, "meta.golden"))).To(Equal(`# Generated by goldga. DO NOT EDIT.
+[snapshots]
+"a" = '''
+foo'''
+[meta]
+version = 1
+go_version = "` + runtime.Version() + `"
+[meta.snapshots."a"]
+hash = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+updated = 2021-09-01T12:34:56Z
+`))
+	})
+
+	It("should keep snapshots readable", func() {
+		Expect(ParseSuite(mustReadFile(fs, "meta.golden"))).To(Equal(map[string]string{"a": "foo"}))
+	})
+
+	It("should return the metadata", func() {
+		hash, t, err := storage.SnapshotMetadata("a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hash).To(Equal(hashSnapshot("foo")))
+		Expect(t).To(BeTemporally("==", updated))
+	})
+
+	It("should detect modified snapshots", func() {
+		content := strings.Replace(string(mustReadFile(fs, "meta.golden")), "foo'''", "edited'''", 1)
+		Expect(afero.WriteFile(fs, "meta.golden", []byte(content), os.ModePerm)).To(Succeed())
+		Expect(storage.ModifiedSnapshots()).To(Equal([]string{"a"}))
+	})
+
+	It("should only update metadata when the content changes", func() {
+		content := strings.Replace(string(mustReadFile(fs, "meta.golden")), runtime.Version(), "go1.0", 1)
+		Expect(afero.WriteFile(fs, "meta.golden", []byte(content), os.ModePerm)).To(Succeed())
+
+		updated = updated.Add(time.Hour)
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(string(mustReadFile(fs, "meta.golden"))).To(Equal(content))
+
+		Expect(storage.Write([]byte("bar"))).To(Succeed())
+		Expect(string(mustReadFile(fs, "meta.golden"))).To(ContainSubstring(
+			"go_version = \"" + runtime.Version() + "\"\n"))
+		Expect(string(mustReadFile(fs, "meta.golden"))).To(ContainSubstring("updated = 2021-09-01T13:34:56Z\n"))
+	})
+
+	It("should keep metadata up to date when disabled later", func() {
+		storage.Metadata = false
+		Expect(storage.Named("b").Write([]byte("bar"))).To(Succeed())
+		Expect(storage.ModifiedSnapshots()).To(BeEmpty())
+	})
+
+	It("should remove metadata of deleted snapshots", func() {
+		Expect(storage.Delete()).To(Succeed())
+		_, _, err := storage.SnapshotMetadata("a")
+		Expect(err).To(HaveOccurred())
+	})
+})
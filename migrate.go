@@ -0,0 +1,373 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+)
+
+// dirNamesFile maps the file names in a DirStorage directory to snapshot names which differ from
+// them, e.g. "Foo_should_bar" = "Foo should bar".
+const dirNamesFile = ".names.toml"
+
+// ListableStorage is a NamedStorage which can list the names of its snapshots.
+type ListableStorage interface {
+	NamedStorage
+
+	Names() ([]string, error)
+}
+
+var (
+	_ ListableStorage = (*SuiteStorage)(nil)
+	_ ListableStorage = (*DirStorage)(nil)
+)
+
+// Names returns the sorted names of snapshots in the suite file, including those which only have
+// variants.
+func (s *SuiteStorage) Names() ([]string, error) {
+	data, err := s.getSuiteData()
+	if err != nil {
+		if errors.Is(err, afero.ErrFileNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return data.sortSnapshotAndVariantKeys(), nil
+}
+
+// Names returns the sorted names of snapshots in Dir. Names which differ from their file name are
+// looked up in the names file written by Write, other files are named after the file. Files of
+// variants ("foo.linux.golden") are not listed if the file without variant exists.
+func (d *DirStorage) Names() ([]string, error) {
+	infos, err := afero.ReadDir(d.Fs, d.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	fileNames, err := d.readNames()
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{}
+
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), goldenExt) {
+			files[info.Name()] = true
+		}
+	}
+
+	var names []string
+
+	for fileName := range files {
+		if base := trimVariant(fileName); base != fileName && files[base] {
+			continue
+		}
+
+		file := strings.TrimSuffix(fileName, goldenExt)
+
+		if name, ok := fileNames[file]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, file)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (d *DirStorage) namesPath() string {
+	return filepath.Join(d.Dir, dirNamesFile)
+}
+
+func (d *DirStorage) readNames() (map[string]string, error) {
+	names := map[string]string{}
+
+	content, err := afero.ReadFile(d.Fs, d.namesPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return names, nil
+		}
+
+		return nil, fmt.Errorf("failed to read names file: %w", err)
+	}
+
+	if _, err := toml.Decode(string(content), &names); err != nil {
+		return nil, fmt.Errorf("failed to decode names file: %w", err)
+	}
+
+	return names, nil
+}
+
+// recordName adds the snapshot name to the names file if it differs from the file name. The file
+// is only written when a name is missing, so updating snapshots does not change it.
+func (d *DirStorage) recordName() error {
+	file := strings.TrimSuffix(d.fileName(), goldenExt)
+
+	if file == d.Name {
+		return nil
+	}
+
+	names, err := d.readNames()
+	if err != nil {
+		return err
+	}
+
+	if names[file] == d.Name {
+		return nil
+	}
+
+	return d.updateNames(func(names map[string]string) {
+		names[file] = d.Name
+	})
+}
+
+// removeNames removes the given file names, without the extension, from the names file.
+func (d *DirStorage) removeNames(files ...string) error {
+	names, err := d.readNames()
+	if err != nil {
+		return err
+	}
+
+	found := false
+
+	for _, file := range files {
+		_, ok := names[file]
+		found = found || ok
+	}
+
+	if !found {
+		return nil
+	}
+
+	return d.updateNames(func(names map[string]string) {
+		for _, file := range files {
+			delete(names, file)
+		}
+	})
+}
+
+func (d *DirStorage) updateNames(fn func(names map[string]string)) error {
+	path := d.namesPath()
+
+	unlock, err := lockFile(d.Fs, path+lockExt)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	names, err := d.readNames()
+	if err != nil {
+		return err
+	}
+
+	fn(names)
+
+	if len(names) == 0 {
+		if err := d.Fs.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove names file: %w", err)
+		}
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	if err := toml.NewEncoder(&buf).Encode(names); err != nil {
+		return fmt.Errorf("failed to encode names file: %w", err)
+	}
+
+	if err := afero.WriteFile(d.Fs, path, buf.Bytes(), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write names file: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotExtras are the variants, previous version and signature of a snapshot, which Migrate
+// copies along with it.
+type snapshotExtras struct {
+	variants    map[string]string
+	previous    string
+	hasPrevious bool
+	signature   string
+}
+
+// Migrate copies all snapshots from one storage to another, e.g. from a suite file to
+// DirStorage, and verifies that every snapshot reads back unchanged. Variants and previous
+// versions are copied as well, and signatures if both storages are suite files. It returns the
+// names of migrated snapshots. The source is left untouched.
+func Migrate(from ListableStorage, to NamedStorage) ([]string, error) {
+	names, err := from.Names()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	for _, name := range names {
+		extras, err := readSnapshotExtras(from, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %q: %w", name, err)
+		}
+
+		if err := migrateSnapshot(from, to, name); err != nil {
+			// Snapshots in suite files may only have variants.
+			if !errors.Is(err, afero.ErrFileNotFound) || len(extras.variants) == 0 {
+				return nil, err
+			}
+		}
+
+		if err := writeSnapshotExtras(to, name, extras); err != nil {
+			return nil, fmt.Errorf("failed to write snapshot %q: %w", name, err)
+		}
+	}
+
+	return names, nil
+}
+
+func migrateSnapshot(from ListableStorage, to NamedStorage, name string) error {
+	data, err := from.Named(name).Read()
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %q: %w", name, err)
+	}
+
+	dest := to.Named(name)
+
+	if err := dest.Write(data); err != nil {
+		return fmt.Errorf("failed to write snapshot %q: %w", name, err)
+	}
+
+	written, err := dest.Read()
+	if err != nil {
+		return fmt.Errorf("failed to verify snapshot %q: %w", name, err)
+	}
+
+	if !bytes.Equal(written, data) {
+		return fmt.Errorf("snapshot %q changed after migration", name)
+	}
+
+	return nil
+}
+
+func readSnapshotExtras(storage ListableStorage, name string) (snapshotExtras, error) {
+	var extras snapshotExtras
+
+	switch s := storage.(type) {
+	case *SuiteStorage:
+		data, err := s.getSuiteData()
+		if err != nil {
+			return extras, err
+		}
+
+		extras.variants = data.Variants[name]
+		extras.previous, extras.hasPrevious = data.Previous[name]
+		extras.signature = data.Signatures[name]
+	case *DirStorage:
+		named := s.Named(name).(*DirStorage)
+		fileName := named.fileName()
+
+		infos, err := afero.ReadDir(s.Fs, s.Dir)
+		if err != nil {
+			return extras, fmt.Errorf("failed to read directory: %w", err)
+		}
+
+		for _, info := range infos {
+			variantFile := info.Name()
+
+			if info.IsDir() || variantFile == fileName || trimVariant(variantFile) != fileName {
+				continue
+			}
+
+			content, err := afero.ReadFile(s.Fs, filepath.Join(s.Dir, variantFile))
+			if err != nil {
+				return extras, fmt.Errorf("failed to read variant: %w", err)
+			}
+
+			if extras.variants == nil {
+				extras.variants = map[string]string{}
+			}
+
+			variant := strings.TrimSuffix(strings.TrimPrefix(variantFile, strings.TrimSuffix(fileName, goldenExt)+"."), goldenExt)
+			extras.variants[variant] = string(content)
+		}
+
+		previous, err := afero.ReadFile(s.Fs, filepath.Join(s.Dir, fileName+origExt))
+		if err == nil {
+			extras.previous, extras.hasPrevious = string(previous), true
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return extras, fmt.Errorf("failed to read previous version: %w", err)
+		}
+	}
+
+	return extras, nil
+}
+
+func writeSnapshotExtras(storage NamedStorage, name string, extras snapshotExtras) error {
+	switch s := storage.(type) {
+	case *SuiteStorage:
+		if len(extras.variants) == 0 && !extras.hasPrevious && extras.signature == "" {
+			return nil
+		}
+
+		return s.updateSuiteData(func(data *suiteData) error {
+			for variant, value := range extras.variants {
+				data.setVariant(name, variant, value)
+			}
+
+			if extras.hasPrevious {
+				data.setPrevious(name, extras.previous)
+			}
+
+			if extras.signature != "" {
+				data.Signatures[name] = extras.signature
+			}
+
+			return nil
+		})
+	case *DirStorage:
+		if extras.signature != "" {
+			return errors.New("signatures are only supported in suite files")
+		}
+
+		named := s.Named(name).(*DirStorage)
+
+		for _, variant := range sortKeys(extras.variants) {
+			variantStorage := *named
+			variantStorage.Variant = variant
+
+			if err := variantStorage.Write([]byte(extras.variants[variant])); err != nil {
+				return err
+			}
+		}
+
+		if extras.hasPrevious {
+			path := filepath.Join(s.Dir, named.fileName()+origExt)
+
+			if err := afero.WriteFile(s.Fs, path, []byte(extras.previous), os.ModePerm); err != nil {
+				return fmt.Errorf("failed to write previous version: %w", err)
+			}
+		}
+
+		return nil
+	default:
+		if len(extras.variants) > 0 || extras.hasPrevious || extras.signature != "" {
+			return fmt.Errorf("storage %T does not support variants, previous versions or signatures", storage)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,152 @@
+package goldga
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type corruptingStorage struct {
+	*DirStorage
+}
+
+func (c *corruptingStorage) Named(name string) Storage {
+	return &corruptingStorage{DirStorage: c.DirStorage.Named(name).(*DirStorage)}
+}
+
+func (c *corruptingStorage) Write(data []byte) error {
+	return c.DirStorage.Write(append(data, '!'))
+}
+
+var _ = Describe("Migrate", func() {
+	var (
+		fs    afero.Fs
+		suite *SuiteStorage
+		dir   *DirStorage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		suite = &SuiteStorage{Path: "foo.golden", Fs: fs}
+		dir = &DirStorage{Dir: "foo", Fs: fs}
+
+		Expect(suite.Named("b").Write([]byte("2"))).To(Succeed())
+		Expect(suite.Named("a").Write([]byte("1"))).To(Succeed())
+	})
+
+	It("should copy snapshots from a suite file to a directory", func() {
+		Expect(Migrate(suite, dir)).To(Equal([]string{"a", "b"}))
+		Expect(dir.Names()).To(Equal([]string{"a", "b"}))
+		Expect(dir.Named("a").Read()).To(Equal([]byte("1")))
+	})
+
+	It("should copy snapshots from a directory to a suite file", func() {
+		Expect(Migrate(suite, dir)).To(HaveLen(2))
+
+		copied := &SuiteStorage{Path: "copy.golden", Fs: fs}
+		Expect(Migrate(dir, copied)).To(Equal([]string{"a", "b"}))
+		Expect(copied.Named("b").Read()).To(Equal([]byte("2")))
+	})
+
+	It("should preserve names which are not valid file names", func() {
+		names := []string{"Foo should bar", "a/b: c", "plain"}
+
+		for _, name := range names {
+			Expect(suite.Named(name).Write([]byte(name))).To(Succeed())
+		}
+
+		Expect(Migrate(suite, dir)).To(HaveLen(5))
+		Expect(afero.Exists(fs, "foo/Foo_should_bar.golden")).To(BeTrue())
+
+		copied := &SuiteStorage{Path: "copy.golden", Fs: fs}
+		Expect(Migrate(dir, copied)).To(Equal([]string{"Foo should bar", "a", "a/b: c", "b", "plain"}))
+
+		for _, name := range names {
+			Expect(copied.Named(name).Read()).To(Equal([]byte(name)))
+		}
+	})
+
+	It("should only record names which differ from the file name", func() {
+		Expect(dir.Named("plain").Write([]byte("1"))).To(Succeed())
+		Expect(afero.Exists(fs, "foo/"+dirNamesFile)).To(BeFalse())
+
+		Expect(dir.Named("Foo should bar").Write([]byte("1"))).To(Succeed())
+		Expect(mustReadFile(fs, "foo/"+dirNamesFile)).To(Equal([]byte("Foo_should_bar = \"Foo should bar\"\n")))
+
+		Expect(dir.Named("Foo should bar").(*DirStorage).Delete()).To(Succeed())
+		Expect(afero.Exists(fs, "foo/"+dirNamesFile)).To(BeFalse())
+	})
+
+	Describe("variants, previous versions and signatures", func() {
+		BeforeEach(func() {
+			keep := &SuiteStorage{Path: "foo.golden", Name: "a", Fs: fs, KeepPrevious: true}
+			Expect(keep.Write([]byte("1 new"))).To(Succeed())
+			Expect((&SuiteStorage{Path: "foo.golden", Name: "a", Variant: "linux", Fs: fs}).Write([]byte("1 linux"))).To(Succeed())
+			Expect((&SuiteStorage{Path: "foo.golden", Name: "c", Variant: "linux", Fs: fs}).Write([]byte("3 linux"))).To(Succeed())
+		})
+
+		expectExtras := func(storage NamedStorage) {
+			Expect(storage.Named("a").Read()).To(Equal([]byte("1 new")))
+			Expect(withVariant(storage.Named("a"), "linux").Read()).To(Equal([]byte("1 linux")))
+			Expect(withVariant(storage.Named("c"), "linux").Read()).To(Equal([]byte("3 linux")))
+			Expect(RollbackSnapshot(storage.Named("a"))).To(Succeed())
+			Expect(storage.Named("a").Read()).To(Equal([]byte("1")))
+		}
+
+		It("should copy them between suite files", func() {
+			pub, priv, err := ed25519.GenerateKey(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suite.Sign("a", priv)).To(Succeed())
+
+			copied := &SuiteStorage{Path: "copy.golden", Fs: fs}
+			Expect(Migrate(suite, copied)).To(Equal([]string{"a", "b", "c"}))
+			Expect(copied.VerifySignatures(pub)).To(Equal([]string{"b", "c"}))
+			expectExtras(copied)
+		})
+
+		It("should copy them to a directory and back", func() {
+			Expect(Migrate(suite, dir)).To(Equal([]string{"a", "b", "c"}))
+			Expect(afero.ReadFile(fs, "foo/a.linux.golden")).To(Equal([]byte("1 linux")))
+			Expect(afero.ReadFile(fs, "foo/a.golden.orig")).To(Equal([]byte("1")))
+
+			copied := &SuiteStorage{Path: "copy.golden", Fs: fs}
+			Expect(Migrate(dir, copied)).To(Equal([]string{"a", "b", "c.linux"}))
+			Expect(withVariant(copied.Named("a"), "linux").Read()).To(Equal([]byte("1 linux")))
+			Expect(RollbackSnapshot(copied.Named("a"))).To(Succeed())
+			Expect(copied.Named("a").Read()).To(Equal([]byte("1")))
+		})
+
+		It("should fail to copy signatures to a directory", func() {
+			_, priv, err := ed25519.GenerateKey(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suite.Sign("a", priv)).To(Succeed())
+
+			_, err = Migrate(suite, dir)
+			Expect(err).To(MatchError(ContainSubstring("signatures are only supported in suite files")))
+		})
+	})
+
+	It("should not list variant files as snapshots", func() {
+		Expect(dir.Named("a").Write([]byte("1"))).To(Succeed())
+		Expect(withVariant(dir.Named("a"), "linux").Write([]byte("1 linux"))).To(Succeed())
+		Expect(dir.Names()).To(Equal([]string{"a"}))
+	})
+
+	It("should return nothing if the source does not exist", func() {
+		Expect(Migrate(&SuiteStorage{Path: "missing.golden", Fs: fs}, dir)).To(BeEmpty())
+		Expect(Migrate(&DirStorage{Dir: "missing", Fs: fs}, suite)).To(BeEmpty())
+	})
+
+	It("should fail if a snapshot changed", func() {
+		_, err := Migrate(suite, &corruptingStorage{DirStorage: dir})
+		Expect(err).To(MatchError(`snapshot "a" changed after migration`))
+	})
+
+	It("should fail if the destination cannot be written", func() {
+		_, err := Migrate(suite, &DirStorage{Dir: "foo", Fs: afero.NewReadOnlyFs(fs)})
+		Expect(errors.Unwrap(err)).To(HaveOccurred())
+	})
+})
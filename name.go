@@ -0,0 +1,172 @@
+package goldga
+
+import (
+	"bytes"
+	"crypto/sha1" // nolint: gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// NameInfo describes the test a snapshot belongs to.
+type NameInfo struct {
+	// File is the path of the test file.
+	File string
+	// Line is the line number of the test, if known.
+	Line int
+	// Texts are the descriptions of the containers and the test, or the parts of a subtest name.
+	Texts []string
+	// FullText is the default snapshot name.
+	FullText string
+	// ID identifies the test independently of its descriptions, see WithSnapshotID.
+	ID string
+}
+
+// StableHash returns a hash of the file name and ID, which does not change when descriptions
+// are edited. It returns an error if ID is empty.
+func (n NameInfo) StableHash() (string, error) {
+	if n.ID == "" {
+		return "", errors.New("StableHash requires an ID, set it with WithSnapshotID")
+	}
+
+	return hashText(filepath.Base(n.File) + "\x00" + n.ID), nil
+}
+
+// LeafText returns the description of the test itself.
+func (n NameInfo) LeafText() string {
+	if len(n.Texts) == 0 {
+		return n.FullText
+	}
+
+	return n.Texts[len(n.Texts)-1]
+}
+
+// NameProvider generates snapshot names.
+type NameProvider interface {
+	SnapshotName(info NameInfo) (string, error)
+}
+
+var _ NameProvider = (*TemplateNameProvider)(nil)
+
+// TemplateNameProvider generates snapshot names with a text/template executed with NameInfo.
+// Besides the builtin functions, the template can use:
+//
+//	base  file name without directory and extension
+//	slug  lower case text with non-alphanumeric characters replaced by "-", or the hash of the
+//	      text if it has no alphanumeric characters
+//	hash  first 8 hex characters of the SHA-1 of the text
+//
+// For example, "{{.File | base}}/{{.LeafText | slug}}". Names containing descriptions change when
+// the descriptions are edited, "{{.StableHash}}" does not, see WithSnapshotID.
+type TemplateNameProvider struct {
+	Template *template.Template
+}
+
+// nolint: gochecknoglobals
+var nameTemplateFuncs = template.FuncMap{
+	"base": func(path string) string {
+		name := filepath.Base(path)
+
+		return strings.TrimSuffix(name, filepath.Ext(name))
+	},
+	"slug": func(text string) string {
+		if slug := slugify(text); slug != "" || text == "" {
+			return slug
+		}
+
+		return hashText(text)
+	},
+	"hash": hashText,
+}
+
+func hashText(text string) string {
+	sum := sha1.Sum([]byte(text)) // nolint: gosec
+
+	return hex.EncodeToString(sum[:])[:fileNameHashLength]
+}
+
+// NewTemplateNameProvider parses text as a name template.
+func NewTemplateNameProvider(text string) (*TemplateNameProvider, error) {
+	tmpl, err := template.New("name").Funcs(nameTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse name template: %w", err)
+	}
+
+	return &TemplateNameProvider{Template: tmpl}, nil
+}
+
+func (t *TemplateNameProvider) SnapshotName(info NameInfo) (string, error) {
+	var buf bytes.Buffer
+
+	if err := t.Template.Execute(&buf, info); err != nil {
+		return "", fmt.Errorf("failed to execute name template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// WithNameProvider names the snapshot with provider instead of the full test description. Match
+// returns the error if the provider fails.
+func WithNameProvider(provider NameProvider) Option {
+	return func(matcher *Matcher) {
+		matcher.nameProvider = provider
+
+		name, err := provider.SnapshotName(matcher.nameInfo)
+		matcher.nameErr = err
+
+		if err != nil {
+			return
+		}
+
+		matcher.Storage = renameStorage(matcher.Storage, func(string) string {
+			return name
+		})
+	}
+}
+
+// WithSnapshotID sets the ID of the test used by NameInfo.StableHash, e.g. with the name template
+// "{{.File | base}}/{{.StableHash}}". If a name provider was applied before, e.g. from
+// .goldga.toml, the snapshot is named again with the ID.
+func WithSnapshotID(id string) Option {
+	return func(matcher *Matcher) {
+		matcher.nameInfo.ID = id
+
+		if matcher.nameProvider != nil {
+			WithNameProvider(matcher.nameProvider)(matcher)
+		}
+	}
+}
+
+// WithNameTemplate names the snapshot with a template, see TemplateNameProvider. It panics if
+// the template is invalid.
+func WithNameTemplate(text string) Option {
+	provider, err := NewTemplateNameProvider(text)
+	if err != nil {
+		panic(err)
+	}
+
+	return WithNameProvider(provider)
+}
+
+func slugify(text string) string {
+	var sb strings.Builder
+
+	dash := false
+
+	for _, r := range strings.ToLower(text) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "-")
+}
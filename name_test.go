@@ -0,0 +1,91 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateNameProvider", func() {
+	info := NameInfo{
+		File:     "/src/pkg/user_test.go",
+		Line:     42,
+		Texts:    []string{"User", "when created", "should have a Name!"},
+		FullText: "User when created should have a Name!",
+	}
+
+	DescribeTable("SnapshotName", func(text, expected string) {
+		provider, err := NewTemplateNameProvider(text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(provider.SnapshotName(info)).To(Equal(expected))
+	},
+		Entry("full text", "{{.FullText}}", "User when created should have a Name!"),
+		Entry("base and slug", "{{.File | base}}/{{.LeafText | slug}}", "user_test/should-have-a-name"),
+		Entry("hash", "{{.FullText | hash}}", "167080d3"),
+		Entry("slug without alphanumeric characters", "{{\"日本語\" | slug}}", hashText("日本語")),
+		Entry("line", "{{.File | base}}:{{.Line}}", "user_test:42"),
+	)
+
+	It("should return error on invalid template", func() {
+		_, err := NewTemplateNameProvider("{{")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return error on StableHash without ID", func() {
+		provider, err := NewTemplateNameProvider("{{.StableHash}}")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = provider.SnapshotName(info)
+		Expect(err).To(MatchError(ContainSubstring("StableHash requires an ID")))
+	})
+
+	It("should return error on unknown fields", func() {
+		provider, err := NewTemplateNameProvider("{{.Unknown}}")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = provider.SnapshotName(info)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WithNameTemplate", func() {
+	It("should rename the snapshot", func() {
+		m := newMatcher("foo", "Foo bar", WithNameTemplate("{{.LeafText | slug}}"))
+		Expect(m.Storage.(*SuiteStorage).Name).To(Equal("foo-bar"))
+	})
+
+	It("should use the Ginkgo description", func() {
+		m := Match(WithNameTemplate("{{index .Texts 0}}:{{.LeafText}}"))
+		Expect(m.Storage.(*SuiteStorage).Name).To(Equal("WithNameTemplate:should use the Ginkgo description"))
+	})
+
+	It("should panic on invalid template", func() {
+		Expect(func() { WithNameTemplate("{{") }).To(Panic())
+	})
+})
+
+var _ = Describe("WithSnapshotID", func() {
+	It("should keep the stable hash when descriptions change", func() {
+		a := newMatcher("foo", "Foo bar", WithSnapshotID("user-name"), WithNameTemplate("{{.StableHash}}"))
+		b := newMatcher("foo", "Foo baz", WithSnapshotID("user-name"), WithNameTemplate("{{.StableHash}}"))
+		Expect(a.Storage.(*SuiteStorage).Name).To(HaveLen(fileNameHashLength))
+		Expect(a.Storage.(*SuiteStorage).Name).To(Equal(b.Storage.(*SuiteStorage).Name))
+	})
+
+	It("should allow ID to be set after a name template which requires it", func() {
+		m := newMatcher("foo", "Foo bar", WithNameTemplate("{{.StableHash}}"), WithSnapshotID("user-name"))
+		Expect(m.Storage.(*SuiteStorage).Name).To(HaveLen(fileNameHashLength))
+	})
+
+	It("should fail if the ID is never set", func() {
+		m := newMatcher("foo", "Foo bar", WithNameTemplate("{{.StableHash}}"))
+		_, err := m.Match("foo")
+		Expect(err).To(MatchError(ContainSubstring("failed to execute name template")))
+	})
+
+	It("should rename snapshots named by an earlier name provider", func() {
+		m := newMatcher("foo", "Foo bar",
+			WithNameTemplate("{{if .ID}}{{.ID}}{{else}}{{.LeafText | slug}}{{end}}"),
+			WithSnapshotID("user-name"),
+		)
+		Expect(m.Storage.(*SuiteStorage).Name).To(Equal("user-name"))
+	})
+})
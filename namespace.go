@@ -0,0 +1,164 @@
+package goldga
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+const goModFileName = "go.mod"
+
+// ErrSnapshotCollision is returned when two tests claim the same snapshot in a run.
+var ErrSnapshotCollision = errors.New("snapshot is claimed by another test")
+
+// nolint: gochecknoglobals
+var (
+	snapshotClaimsMu sync.Mutex
+	snapshotClaims   = map[string]string{}
+)
+
+// WithNamespace prefixes snapshots with namespace, so packages sharing a golden directory do not
+// collide. Snapshot names in suite files become "<namespace>/<name>", and DirStorage and
+// SingleStorage files are stored in a namespace subdirectory. An empty namespace defaults to the
+// import path of the package under test. Use it after storage options.
+//
+// Tests of a namespace claiming the same snapshot in a run fail with ErrSnapshotCollision.
+func WithNamespace(namespace string) Option {
+	return func(matcher *Matcher) {
+		if namespace == "" {
+			namespace = getDefaultNamespace()
+		}
+
+		matcher.Namespace = namespace
+		matcher.Storage = withNamespace(matcher.Storage, namespace)
+	}
+}
+
+func withNamespace(storage Storage, namespace string) Storage {
+	return mapStorage(storage, func(inner Storage) Storage {
+		switch s := inner.(type) {
+		case *SuiteStorage:
+			return s.Named(namespace + "/" + s.Name)
+		case *DirStorage:
+			named := *s
+			named.Dir = filepath.Join(s.Dir, filepath.FromSlash(namespace))
+
+			return &named
+		case *SingleStorage:
+			named := *s
+			named.Path = filepath.Join(filepath.Dir(s.Path), filepath.FromSlash(namespace), filepath.Base(s.Path))
+
+			return &named
+		default:
+			return inner
+		}
+	})
+}
+
+// getDefaultNamespace returns the import path of the package in the working directory, which
+// is the package under test, or the name of the directory if it is not in a module.
+func getDefaultNamespace() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	if namespace, err := getImportPath(afero.NewOsFs(), dir); err == nil {
+		return namespace
+	}
+
+	return filepath.Base(dir)
+}
+
+// getImportPath returns the import path of dir according to the nearest go.mod file.
+func getImportPath(fs afero.Fs, dir string) (string, error) {
+	for current := dir; ; {
+		module, err := readModulePath(fs, filepath.Join(current, goModFileName))
+		if err == nil {
+			rel, err := filepath.Rel(current, dir)
+			if err != nil {
+				return "", fmt.Errorf("failed to get relative path: %w", err)
+			}
+
+			return path.Join(module, filepath.ToSlash(rel)), nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("%s not found in %s or its parents", goModFileName, dir)
+		}
+
+		current = parent
+	}
+}
+
+func readModulePath(fs afero.Fs, file string) (string, error) {
+	f, err := fs.Open(file)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) >= 2 && fields[0] == "module" {
+			if module, err := strconv.Unquote(fields[1]); err == nil {
+				return module, nil
+			}
+
+			return fields[1], nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	return "", fmt.Errorf("no module directive in %s", file)
+}
+
+// claimSnapshot returns ErrSnapshotCollision if the snapshot of m was claimed by another test
+// in this run.
+func (m *Matcher) claimSnapshot() error {
+	if m.Namespace == "" {
+		return nil
+	}
+
+	key := getStoragePath(m.Storage) + "\x00" + getStorageName(m.Storage)
+	owner := m.nameInfo.File + "\x00" + m.nameInfo.FullText
+
+	snapshotClaimsMu.Lock()
+	defer snapshotClaimsMu.Unlock()
+
+	if claimed, ok := snapshotClaims[key]; ok && claimed != owner {
+		return fmt.Errorf("%w: %q in %s is also used by %q", ErrSnapshotCollision,
+			getStorageName(m.Storage), getStoragePath(m.Storage), strings.Split(claimed, "\x00")[1])
+	}
+
+	snapshotClaims[key] = owner
+
+	return nil
+}
+
+func resetSnapshotClaims() {
+	snapshotClaimsMu.Lock()
+	defer snapshotClaimsMu.Unlock()
+
+	snapshotClaims = map[string]string{}
+}
@@ -0,0 +1,102 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("WithNamespace", func() {
+	var fs afero.Fs
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		resetSnapshotClaims()
+	})
+
+	AfterEach(func() {
+		resetSnapshotClaims()
+	})
+
+	It("should prefix snapshot names in suite files", func() {
+		m := newMatcher("foo", "foo",
+			WithStorage(&SuiteStorage{Path: "golden/shared.golden", Name: "foo", Fs: fs}),
+			WithSerializer(&StringSerializer{}),
+			WithNamespace("pkg/a"),
+		)
+
+		Expect(m.Match("a")).To(BeTrue())
+		Expect(ParseSuite(mustReadFile(fs, "golden/shared.golden"))).To(Equal(map[string]string{
+			"pkg/a/foo": "a",
+		}))
+	})
+
+	It("should keep snapshot names as is", func() {
+		m := newMatcher("foo", "foo",
+			WithStorage(&SuiteStorage{Path: "golden/shared.golden", Name: "../foo//bar", Fs: fs}),
+			WithSerializer(&StringSerializer{}),
+			WithNamespace("pkg/a"),
+		)
+
+		Expect(m.Match("a")).To(BeTrue())
+		Expect(ParseSuite(mustReadFile(fs, "golden/shared.golden"))).To(Equal(map[string]string{
+			"pkg/a/../foo//bar": "a",
+		}))
+	})
+
+	It("should store DirStorage files in a subdirectory", func() {
+		m := newMatcher("foo", "foo",
+			WithStorage(&DirStorage{Dir: "golden", Name: "foo", Fs: fs}),
+			WithSerializer(&StringSerializer{}),
+			WithNamespace("pkg/a"),
+		)
+
+		Expect(m.Match("a")).To(BeTrue())
+		Expect(mustReadFile(fs, filepath.Join("golden", "pkg", "a", "foo.golden"))).To(Equal([]byte("a")))
+	})
+
+	It("should store SingleStorage files in a subdirectory", func() {
+		storage := withNamespace(&SingleStorage{Path: filepath.Join("golden", "foo.golden")}, "pkg/a")
+		Expect(storage.(*SingleStorage).Path).To(Equal(filepath.Join("golden", "pkg", "a", "foo.golden")))
+	})
+
+	It("should default to the import path of the package", func() {
+		m := newMatcher("foo", "foo", WithStorage(&SuiteStorage{Name: "foo", Fs: fs}), WithNamespace(""))
+		Expect(m.Namespace).To(Equal("github.com/tommy351/goldga"))
+		Expect(m.Storage.(*SuiteStorage).Name).To(Equal("github.com/tommy351/goldga/foo"))
+	})
+
+	It("should fail when two tests claim the same snapshot", func() {
+		storage := &SuiteStorage{Path: "golden/shared.golden", Name: "foo", Fs: fs}
+		newTestMatcher := func(test string) *Matcher {
+			return newMatcherWithInfo("foo", NameInfo{FullText: test},
+				WithStorage(storage),
+				WithSerializer(&StringSerializer{}),
+				WithNamespace("pkg"),
+			)
+		}
+
+		Expect(newTestMatcher("first").Match("a")).To(BeTrue())
+		Expect(newTestMatcher("first").Match("a")).To(BeTrue())
+
+		_, err := newTestMatcher("second").Match("a")
+		Expect(err).To(MatchError(ErrSnapshotCollision))
+		Expect(err).To(MatchError(ContainSubstring(`"pkg/foo" in golden/shared.golden is also used by "first"`)))
+	})
+})
+
+var _ = Describe("getImportPath", func() {
+	It("should join the module path and the relative directory", func() {
+		fs := afero.NewMemMapFs()
+		Expect(afero.WriteFile(fs, "/src/go.mod", []byte("// comment\nmodule \"example.com/app\"\n\ngo 1.16\n"), 0o644)).To(Succeed())
+		Expect(getImportPath(fs, "/src/internal/foo")).To(Equal("example.com/app/internal/foo"))
+		Expect(getImportPath(fs, "/src")).To(Equal("example.com/app"))
+	})
+
+	It("should fail outside a module", func() {
+		_, err := getImportPath(afero.NewMemMapFs(), "/src")
+		Expect(err).To(HaveOccurred())
+	})
+})
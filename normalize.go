@@ -0,0 +1,124 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func normalizeLineEndings(content []byte) []byte {
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// windowsPathPattern matches absolute Windows paths, with separators escaped or not.
+// nolint: gochecknoglobals
+var windowsPathPattern = regexp.MustCompile(`\b[A-Za-z]:(?:\\\\?[^\\\r\n\t"'<>|*?:]+)+`)
+
+// normalizePathSeparators replaces sep with "/". On Windows, only separators of absolute paths
+// are replaced, so escape sequences such as "\n" in dumped strings are kept. If a path contains
+// escaped separators, as in quoted strings of dumped values, only those are replaced.
+func normalizePathSeparators(content []byte, sep byte) []byte {
+	if sep == '/' {
+		return content
+	}
+
+	if sep != '\\' {
+		return bytes.ReplaceAll(content, []byte{sep}, []byte("/"))
+	}
+
+	return windowsPathPattern.ReplaceAllFunc(content, func(path []byte) []byte {
+		if bytes.Contains(path, []byte(`\\`)) {
+			return bytes.ReplaceAll(path, []byte(`\\`), []byte("/"))
+		}
+
+		return bytes.ReplaceAll(path, []byte(`\`), []byte("/"))
+	})
+}
+
+func isJSONNumberChar(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+// formatJSONNumber formats a number with at most precision decimals and without trailing zeros.
+// A precision of 0 keeps the shortest representation of the number. Like in JavaScript, numbers
+// from 1e21 and, without precision, below 1e-6 use an exponent instead of expanding into a long
+// series of digits.
+func formatJSONNumber(literal string, precision int) string {
+	if !strings.ContainsAny(literal, ".eE") {
+		return literal
+	}
+
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return literal
+	}
+
+	if precision <= 0 {
+		precision = -1
+	}
+
+	if abs := math.Abs(f); abs >= 1e21 || (precision < 0 && abs != 0 && abs < 1e-6) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	s := strconv.FormatFloat(f, 'f', precision, 64)
+
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+
+	if s == "-0" {
+		s = "0"
+	}
+
+	return s
+}
+
+// normalizeJSONNumbers rewrites every number in a JSON document in a canonical form and leaves
+// the rest of the document untouched. Content which is not valid JSON is returned as is.
+func normalizeJSONNumbers(content []byte, precision int) []byte {
+	if !json.Valid(content) {
+		return content
+	}
+
+	var (
+		buf      bytes.Buffer
+		inString bool
+	)
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		switch {
+		case inString:
+			switch c {
+			case '\\':
+				buf.WriteByte(c)
+				i++
+				c = content[i]
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+
+			for i+1 < len(content) && isJSONNumberChar(content[i+1]) {
+				i++
+			}
+
+			buf.WriteString(formatJSONNumber(string(content[start:i+1]), precision))
+
+			continue
+		}
+
+		buf.WriteByte(c)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,54 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("normalizeJSONNumbers", func() {
+	DescribeTable("shortest representation", func(input, expected string) {
+		Expect(string(normalizeJSONNumbers([]byte(input), 0))).To(Equal(expected))
+	},
+		Entry("trailing zeros", `{"a": 1.0, "b": 1.00, "c": 1.50}`, `{"a": 1, "b": 1, "c": 1.5}`),
+		Entry("exponent", `[1e3, 2.5E-1]`, `[1000, 0.25]`),
+		Entry("negative", `[-1.0, -0.0]`, `[-1, 0]`),
+		Entry("large exponent", `[1e300, -1.50E+300]`, `[1e+300, -1.5e+300]`),
+		Entry("small exponent", `[1e-7, 1.50e-10, 0.000001]`, `[1e-07, 1.5e-10, 0.000001]`),
+		Entry("integer", `12345678901234567890`, `12345678901234567890`),
+		Entry("numbers in strings", `{"1.0": "2.00 \"3.0\""}`, `{"1.0": "2.00 \"3.0\""}`),
+		Entry("invalid JSON", `1.0 and 1.00`, `1.0 and 1.00`),
+	)
+
+	It("should round to precision", func() {
+		Expect(string(normalizeJSONNumbers([]byte(`[3.14159, 2.50001]`), 2))).To(Equal(`[3.14, 2.5]`))
+	})
+
+	It("should not expand large numbers with precision", func() {
+		Expect(string(normalizeJSONNumbers([]byte(`[1e300, 1.5e-10]`), 2))).To(Equal(`[1e+300, 0]`))
+	})
+
+	It("should make equal numbers compare equal", func() {
+		Expect(normalizeJSONNumbers([]byte(`{"a":1.0}`), 0)).To(Equal(normalizeJSONNumbers([]byte(`{"a":1.00}`), 0)))
+	})
+})
+
+var _ = Describe("normalizeLineEndings", func() {
+	It("should convert CRLF to LF", func() {
+		Expect(string(normalizeLineEndings([]byte("a\r\nb\r\nc\rd\n")))).To(Equal("a\nb\nc\rd\n"))
+	})
+})
+
+var _ = Describe("normalizePathSeparators", func() {
+	DescribeTable("separators", func(sep byte, input, expected string) {
+		Expect(string(normalizePathSeparators([]byte(input), sep))).To(Equal(expected))
+	},
+		Entry("slash", byte('/'), `a/b\c`, `a/b\c`),
+		Entry("backslash", byte('\\'), `C:\foo\bar`, `C:/foo/bar`),
+		Entry("escaped backslash", byte('\\'), `"C:\\foo\\bar"`, `"C:/foo/bar"`),
+		Entry("spaces", byte('\\'), `C:\Program Files\foo`, `C:/Program Files/foo`),
+		Entry("escape sequences", byte('\\'), `"a\tb\n\"c\""`, `"a\tb\n\"c\""`),
+		Entry("escape sequences after path", byte('\\'), `"C:\\foo\\bar\nbaz\t"`, `"C:/foo/bar\nbaz\t"`),
+		Entry("other separators", byte(':'), `a:b`, `a/b`),
+	)
+})
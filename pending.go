@@ -0,0 +1,104 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	updateModePending = "pending"
+	pendingExt        = ".pending"
+)
+
+// ErrSnapshotPending is returned when a new snapshot is staged in pending mode.
+var ErrSnapshotPending = errors.New("new snapshot staged as pending, accept it with AcceptPending or the goldga command")
+
+// StagingStorage is a Storage which can stage proposed snapshots next to the golden files, so
+// recording new output is separated from accepting it.
+type StagingStorage interface {
+	Storage
+
+	// Pending returns the Storage holding the proposed snapshot.
+	Pending() DeletableStorage
+}
+
+var (
+	_ StagingStorage = (*SingleStorage)(nil)
+	_ StagingStorage = (*SuiteStorage)(nil)
+	_ StagingStorage = (*DirStorage)(nil)
+)
+
+// WithPendingUpdates writes snapshots to a pending storage instead of the golden file. It is
+// enabled when GOLDGA_UPDATE is set to "pending". Mismatched and new snapshots are staged and
+// still fail the match, new snapshots with ErrSnapshotPending. Use AcceptPending or the goldga
+// command to promote them.
+func WithPendingUpdates() Option {
+	return func(matcher *Matcher) {
+		matcher.Pending = true
+	}
+}
+
+// pendingStorage returns storage with its innermost StagingStorage replaced by the pending
+// storage, so wrappers encode the staged snapshot like the golden file.
+func pendingStorage(storage Storage) Storage {
+	return mapStorage(storage, func(inner Storage) Storage {
+		return inner.(StagingStorage).Pending()
+	})
+}
+
+// Pending returns a storage for "<path>.pending".
+func (s *SingleStorage) Pending() DeletableStorage {
+	pending := *s
+	pending.Path = s.Path + pendingExt
+
+	return &pending
+}
+
+// Pending returns a storage for the same snapshot in the suite file "<path>.pending". The file is
+// removed once its last snapshot is deleted.
+func (s *SuiteStorage) Pending() DeletableStorage {
+	pending := *s
+	pending.Path = s.Path + pendingExt
+	pending.removeEmpty = true
+
+	return &pending
+}
+
+// Pending returns a storage for "<file name>.pending" in Dir.
+func (d *DirStorage) Pending() DeletableStorage {
+	return &SingleStorage{
+		Path: filepath.Join(d.Dir, d.fileName()+pendingExt),
+		Fs:   d.Fs,
+	}
+}
+
+// AcceptPending writes the pending snapshot to the golden file and removes it from the pending
+// storage.
+func AcceptPending(s StagingStorage) error {
+	pending := s.Pending()
+
+	data, err := pending.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read pending snapshot: %w", err)
+	}
+
+	if err := s.Write(data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if err := pending.Delete(); err != nil {
+		return fmt.Errorf("failed to delete pending snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// RejectPending removes the pending snapshot and keeps the golden file.
+func RejectPending(s StagingStorage) error {
+	if err := s.Pending().Delete(); err != nil {
+		return fmt.Errorf("failed to delete pending snapshot: %w", err)
+	}
+
+	return nil
+}
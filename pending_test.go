@@ -0,0 +1,143 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Pending", func() {
+	var (
+		fs      afero.Fs
+		storage *SuiteStorage
+		matcher *Matcher
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		storage = &SuiteStorage{Path: "testdata/foo.golden", Name: "foo", Fs: fs}
+		Expect(storage.Write([]byte("old\n"))).To(Succeed())
+
+		matcher = newMatcher("testdata/foo.golden", "foo", WithStorage(storage), WithPendingUpdates())
+		matcher.Serializer = &StringSerializer{}
+		matcher.UpdateFile = false
+	})
+
+	When("snapshot does not match", func() {
+		var success bool
+
+		BeforeEach(func() {
+			var err error
+			success, err = matcher.Match("new\n")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should fail", func() {
+			Expect(success).To(BeFalse())
+		})
+
+		It("should keep the golden file", func() {
+			Expect(storage.Read()).To(Equal([]byte("old\n")))
+		})
+
+		It("should stage the snapshot", func() {
+			Expect(storage.Pending().Read()).To(Equal([]byte("new\n")))
+			Expect(fs.Stat("testdata/foo.golden.pending")).NotTo(BeNil())
+		})
+
+		Describe("AcceptPending", func() {
+			BeforeEach(func() {
+				Expect(AcceptPending(storage)).To(Succeed())
+			})
+
+			It("should update the golden file", func() {
+				Expect(storage.Read()).To(Equal([]byte("new\n")))
+			})
+
+			It("should remove the pending snapshot", func() {
+				_, err := storage.Pending().Read()
+				Expect(err).To(MatchError(afero.ErrFileNotFound))
+			})
+		})
+
+		Describe("RejectPending", func() {
+			BeforeEach(func() {
+				Expect(RejectPending(storage)).To(Succeed())
+			})
+
+			It("should keep the golden file", func() {
+				Expect(storage.Read()).To(Equal([]byte("old\n")))
+			})
+
+			It("should remove the pending snapshot", func() {
+				_, err := storage.Pending().Read()
+				Expect(err).To(MatchError(afero.ErrFileNotFound))
+			})
+		})
+	})
+
+	When("snapshot matches", func() {
+		It("should not stage the snapshot", func() {
+			Expect(matcher.Match("old\n")).To(BeTrue())
+
+			_, err := storage.Pending().Read()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("snapshot does not exist", func() {
+		var newStorage StagingStorage
+
+		BeforeEach(func() {
+			newStorage = storage.Named("bar").(*SuiteStorage)
+			matcher.Storage = newStorage
+			_, err := matcher.Match("new\n")
+			Expect(err).To(MatchError(ErrSnapshotPending))
+		})
+
+		It("should not create the snapshot", func() {
+			_, err := newStorage.Read()
+			Expect(err).To(MatchError(afero.ErrFileNotFound))
+		})
+
+		It("should stage the snapshot", func() {
+			Expect(newStorage.Pending().Read()).To(Equal([]byte("new\n")))
+		})
+
+		It("should remove the pending file once accepted", func() {
+			Expect(AcceptPending(newStorage)).To(Succeed())
+			Expect(newStorage.Read()).To(Equal([]byte("new\n")))
+			Expect(afero.Exists(fs, "testdata/foo.golden.pending")).To(BeFalse())
+		})
+	})
+
+	When("storage is DirStorage", func() {
+		It("should stage the snapshot next to the golden file", func() {
+			dir := &DirStorage{Dir: "testdata/foo", Name: "foo bar", Fs: fs}
+			matcher.Storage = dir
+
+			_, err := matcher.Match("new\n")
+			Expect(err).To(MatchError(ErrSnapshotPending))
+			Expect(afero.ReadFile(fs, "testdata/foo/foo_bar.golden.pending")).To(Equal([]byte("new\n")))
+
+			Expect(AcceptPending(dir)).To(Succeed())
+			Expect(dir.Read()).To(Equal([]byte("new\n")))
+		})
+	})
+
+	When("storage is wrapped", func() {
+		It("should stage the snapshot through the wrapper", func() {
+			single := &SingleStorage{Path: "testdata/bar.golden", Fs: fs}
+			matcher = newMatcher("testdata/bar.golden", "bar", WithStorage(single), WithCompression(), WithPendingUpdates())
+			matcher.Serializer = &StringSerializer{}
+
+			_, err := matcher.Match("new\n")
+			Expect(err).To(MatchError(ErrSnapshotPending))
+			Expect(single.Pending().Read()).NotTo(Equal([]byte("new\n")))
+			Expect((&CompressedStorage{Inner: single.Pending()}).Read()).To(Equal([]byte("new\n")))
+
+			Expect(AcceptPending(single)).To(Succeed())
+			Expect((&CompressedStorage{Inner: single}).Read()).To(Equal([]byte("new\n")))
+		})
+	})
+})
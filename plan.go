@@ -0,0 +1,53 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+// NamedStorage is a Storage holding multiple snapshots distinguished by name.
+type NamedStorage interface {
+	Storage
+
+	// Named returns a Storage bound to the snapshot with the given name.
+	Named(name string) Storage
+}
+
+// PlanUpdates compares the candidates against the stored snapshots without writing anything,
+// and returns the sorted names of snapshots which would be created, updated or left unchanged.
+// The storage must support names, like SuiteStorage and DirStorage or wrappers of them.
+func PlanUpdates(s Storage, candidates map[string][]byte) (toCreate, toUpdate, unchanged []string, err error) {
+	named, ok := s.(NamedStorage)
+	if _, innerOK := innerStorage(s).(NamedStorage); !ok || !innerOK {
+		return nil, nil, nil, fmt.Errorf("storage %T does not support names", s)
+	}
+
+	for name, actual := range candidates {
+		expected, err := named.Named(name).Read()
+		if err != nil {
+			if !errors.Is(err, afero.ErrFileNotFound) {
+				return nil, nil, nil, fmt.Errorf("failed to read snapshot %q: %w", name, err)
+			}
+
+			toCreate = append(toCreate, name)
+
+			continue
+		}
+
+		if bytes.Equal(expected, actual) {
+			unchanged = append(unchanged, name)
+		} else {
+			toUpdate = append(toUpdate, name)
+		}
+	}
+
+	sort.Strings(toCreate)
+	sort.Strings(toUpdate)
+	sort.Strings(unchanged)
+
+	return toCreate, toUpdate, unchanged, nil
+}
@@ -0,0 +1,59 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("PlanUpdates", func() {
+	var storage *SuiteStorage
+
+	BeforeEach(func() {
+		storage = &SuiteStorage{
+			Path: filepath.Join("testdata", "plan.golden"),
+			Fs:   afero.NewMemMapFs(),
+		}
+		Expect(storage.Named("b").Write([]byte("b"))).To(Succeed())
+		Expect(storage.Named("c").Write([]byte("c"))).To(Succeed())
+		Expect(storage.Named("d").Write([]byte("d"))).To(Succeed())
+	})
+
+	It("should categorize candidates", func() {
+		toCreate, toUpdate, unchanged, err := PlanUpdates(storage, map[string][]byte{
+			"a": []byte("a"),
+			"e": []byte("e"),
+			"b": []byte("changed"),
+			"c": []byte("c"),
+			"d": []byte("d"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toCreate).To(Equal([]string{"a", "e"}))
+		Expect(toUpdate).To(Equal([]string{"b"}))
+		Expect(unchanged).To(Equal([]string{"c", "d"}))
+	})
+
+	It("should not write anything", func() {
+		_, _, _, err := PlanUpdates(storage, map[string][]byte{"a": []byte("a")})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = storage.Named("a").Read()
+		Expect(err).To(Equal(afero.ErrFileNotFound))
+	})
+
+	It("should plan through wrapper storages", func() {
+		toCreate, _, unchanged, err := PlanUpdates(&ReadOnlyStorage{Inner: storage}, map[string][]byte{
+			"a": []byte("a"),
+			"b": []byte("b"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toCreate).To(Equal([]string{"a"}))
+		Expect(unchanged).To(Equal([]string{"b"}))
+	})
+
+	It("should return error if the storage does not support names", func() {
+		_, _, _, err := PlanUpdates(&SingleStorage{Path: "foo.golden", Fs: afero.NewMemMapFs()}, map[string][]byte{"a": []byte("a")})
+		Expect(err).To(MatchError("storage *goldga.SingleStorage does not support names"))
+	})
+})
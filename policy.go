@@ -0,0 +1,86 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UpdatePolicy controls when golden files are written.
+type UpdatePolicy int
+
+const (
+	// UpdatePolicyCreateOnly writes golden files which do not exist yet. It is the default.
+	UpdatePolicyCreateOnly UpdatePolicy = iota
+	// UpdatePolicyNever never writes golden files and fails if one is missing, e.g. in CI.
+	UpdatePolicyNever
+	// UpdatePolicyAlways overwrites golden files with the actual content.
+	UpdatePolicyAlways
+)
+
+const (
+	updateModeNever      = "never"
+	updateModeCreateOnly = "create"
+	updateModeAlways     = "always"
+)
+
+func (p UpdatePolicy) String() string {
+	switch p {
+	case UpdatePolicyCreateOnly:
+		return updateModeCreateOnly
+	case UpdatePolicyNever:
+		return updateModeNever
+	case UpdatePolicyAlways:
+		return updateModeAlways
+	default:
+		return fmt.Sprintf("UpdatePolicy(%d)", int(p))
+	}
+}
+
+// ErrGoldenFileMissing is returned when a golden file does not exist and UpdatePolicyNever is used.
+var ErrGoldenFileMissing = errors.New("golden file does not exist and update policy is Never")
+
+// WithUpdatePolicy overrides the update policy selected by GOLDGA_UPDATE or UPDATE_GOLDEN. With
+// UpdatePolicyNever, golden files are not written even if UPDATE_GOLDEN=1 is set.
+func WithUpdatePolicy(policy UpdatePolicy) Option {
+	return func(matcher *Matcher) {
+		matcher.UpdatePolicy = policy
+		matcher.UpdateFile = policy == UpdatePolicyAlways
+	}
+}
+
+// missingGoldenFileError returns ErrGoldenFileMissing, naming the conflict if the environment
+// requests updates which the update policy of the matcher overrides.
+func (m *Matcher) missingGoldenFileError() error {
+	if getUpdatePolicy() == UpdatePolicyAlways {
+		return fmt.Errorf("%w, the update requested by UPDATE_GOLDEN or GOLDGA_UPDATE is ignored "+
+			"because the matcher sets UpdatePolicyNever", ErrGoldenFileMissing)
+	}
+
+	return ErrGoldenFileMissing
+}
+
+// effectiveUpdatePolicy returns UpdatePolicy, or UpdatePolicyAlways if UpdateFile is set.
+func (m *Matcher) effectiveUpdatePolicy() UpdatePolicy {
+	if m.UpdateFile {
+		return UpdatePolicyAlways
+	}
+
+	return m.UpdatePolicy
+}
+
+// getUpdatePolicy returns the policy selected by GOLDGA_UPDATE ("never", "create" or "always").
+// UPDATE_GOLDEN=1 is the same as "always", and "git" is "always" restricted to changed packages.
+func getUpdatePolicy() UpdatePolicy {
+	if getUpdateFile() {
+		return UpdatePolicyAlways
+	}
+
+	switch getUpdateMode() {
+	case updateModeNever:
+		return UpdatePolicyNever
+	case updateModeAlways, updateModeGit:
+		return UpdatePolicyAlways
+	default:
+		return UpdatePolicyCreateOnly
+	}
+}
@@ -0,0 +1,59 @@
+package goldga
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("getUpdatePolicy", func() {
+	var updateGolden, updateMode string
+
+	BeforeEach(func() {
+		updateGolden = os.Getenv("UPDATE_GOLDEN")
+		updateMode = os.Getenv("GOLDGA_UPDATE")
+	})
+
+	AfterEach(func() {
+		Expect(os.Setenv("UPDATE_GOLDEN", updateGolden)).To(Succeed())
+		Expect(os.Setenv("GOLDGA_UPDATE", updateMode)).To(Succeed())
+	})
+
+	DescribeTable("environment variables", func(update, mode string, expected UpdatePolicy) {
+		Expect(os.Setenv("UPDATE_GOLDEN", update)).To(Succeed())
+		Expect(os.Setenv("GOLDGA_UPDATE", mode)).To(Succeed())
+		Expect(getUpdatePolicy()).To(Equal(expected))
+	},
+		Entry("default", "", "", UpdatePolicyCreateOnly),
+		Entry("never", "", "Never", UpdatePolicyNever),
+		Entry("create", "", "create", UpdatePolicyCreateOnly),
+		Entry("always", "", "always", UpdatePolicyAlways),
+		Entry("git", "", "git", UpdatePolicyAlways),
+		Entry("UPDATE_GOLDEN", "1", "never", UpdatePolicyAlways),
+	)
+})
+
+var _ = Describe("WithUpdatePolicy", func() {
+	It("should override UpdateFile", func() {
+		m := newMatcher("foo", "foo", func(m *Matcher) { m.UpdateFile = true }, WithUpdatePolicy(UpdatePolicyNever))
+		Expect(m.UpdatePolicy).To(Equal(UpdatePolicyNever))
+		Expect(m.UpdateFile).To(BeFalse())
+	})
+
+	It("should name the conflict with UPDATE_GOLDEN when the golden file is missing", func() {
+		Expect(os.Setenv("UPDATE_GOLDEN", "1")).To(Succeed())
+		defer func() { Expect(os.Unsetenv("UPDATE_GOLDEN")).To(Succeed()) }()
+
+		m := newMatcher("foo", "foo",
+			WithStorage(&SingleStorage{Path: "foo.golden", Fs: afero.NewMemMapFs()}),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyNever),
+		)
+		_, err := m.Match("foo")
+		Expect(err).To(MatchError(ErrGoldenFileMissing))
+		Expect(err).To(MatchError(ContainSubstring("ignored because the matcher sets UpdatePolicyNever")))
+	})
+})
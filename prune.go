@@ -0,0 +1,306 @@
+package goldga
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// snapshotUsage records the snapshots used in a suite file, or the files used in a directory of
+// DirStorage.
+type snapshotUsage struct {
+	fs    afero.Fs
+	dir   bool
+	names map[string]struct{}
+}
+
+// ErrPartialRun is returned by PruneSnapshots and VerifySnapshots when only some tests ran, so
+// snapshots of the other tests would be considered orphaned.
+var ErrPartialRun = errors.New("not all tests ran, use a run without focus, skip, -run or parallel nodes")
+
+var errNothingToPrune = errors.New("nothing to prune")
+
+// nolint: gochecknoglobals
+var (
+	snapshotUsagesMu sync.Mutex
+	snapshotUsages   = map[string]*snapshotUsage{}
+	isPartialRun     = detectPartialRun
+)
+
+// detectPartialRun reports whether go test runs only some tests because of -run, or Ginkgo
+// because of focus and skip flags or parallel nodes.
+func detectPartialRun() bool {
+	if f := flag.Lookup("test.run"); f != nil && f.Value.String() != "" {
+		return true
+	}
+
+	return isGinkgoPartialRun()
+}
+
+func recordUsage(path string, fs afero.Fs, dir bool, name string) {
+	snapshotUsagesMu.Lock()
+	defer snapshotUsagesMu.Unlock()
+
+	usage, ok := snapshotUsages[path]
+	if !ok {
+		usage = &snapshotUsage{
+			fs:    fs,
+			dir:   dir,
+			names: map[string]struct{}{},
+		}
+		snapshotUsages[path] = usage
+	}
+
+	usage.names[name] = struct{}{}
+}
+
+// recordMatchUsage records that the snapshot in storage was matched during this run. Reads
+// outside Match, e.g. by PlanUpdates or a Transaction, do not count.
+func recordMatchUsage(storage Storage) {
+	switch s := innerStorage(storage).(type) {
+	case *SuiteStorage:
+		recordUsage(s.Path, s.Fs, false, s.Name)
+	case *DirStorage:
+		recordUsage(s.Dir, s.Fs, true, s.fileName())
+	}
+}
+
+func resetSnapshotUsages() {
+	snapshotUsagesMu.Lock()
+	defer snapshotUsagesMu.Unlock()
+
+	snapshotUsages = map[string]*snapshotUsage{}
+}
+
+// OrphanedSnapshots returns snapshots which were not matched during this run, in suite files and
+// DirStorage directories used during this run. The result is keyed by the path
+// of suite files or directories. For directories, file names are returned instead of snapshot
+// names. Call it after all tests are done, e.g. in Ginkgo's AfterSuite.
+func OrphanedSnapshots() (map[string][]string, error) {
+	return findOrphanedSnapshots(false)
+}
+
+// PruneSnapshots removes the snapshots returned by OrphanedSnapshots and returns them. It returns
+// ErrPartialRun when go test or Ginkgo runs only some tests. Tests excluded otherwise, e.g. by
+// FIt or build tags, cannot be detected, and their snapshots are removed as well.
+func PruneSnapshots() (map[string][]string, error) {
+	if isPartialRun() {
+		return nil, ErrPartialRun
+	}
+
+	return findOrphanedSnapshots(true)
+}
+
+func findOrphanedSnapshots(prune bool) (map[string][]string, error) {
+	snapshotUsagesMu.Lock()
+	defer snapshotUsagesMu.Unlock()
+
+	return findOrphanedSnapshotsIn(snapshotUsages, prune)
+}
+
+// usageManifestEntry is the usage of a suite file or directory in a usage manifest.
+type usageManifestEntry struct {
+	Dir   bool     `json:"dir,omitempty"`
+	Names []string `json:"names"`
+}
+
+// WriteUsageManifest writes the snapshots matched during this run as JSON, keyed by the absolute
+// path of suite files and directories. Pass the manifest to PruneFromManifest or "goldga prune"
+// to remove orphaned snapshots later. It returns ErrPartialRun when go test or Ginkgo runs only
+// some tests.
+func WriteUsageManifest(w io.Writer) error {
+	if isPartialRun() {
+		return ErrPartialRun
+	}
+
+	snapshotUsagesMu.Lock()
+	defer snapshotUsagesMu.Unlock()
+
+	manifest := map[string]usageManifestEntry{}
+
+	for path, usage := range snapshotUsages {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve path %s: %w", path, err)
+		}
+
+		entry := usageManifestEntry{Dir: usage.dir, Names: make([]string, 0, len(usage.names))}
+
+		for name := range usage.names {
+			entry.Names = append(entry.Names, name)
+		}
+
+		sort.Strings(entry.Names)
+		manifest[abs] = entry
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage manifest: %w", err)
+	}
+
+	_, err = w.Write(append(content, '\n'))
+
+	return err
+}
+
+// PruneFromManifest removes snapshots which are not listed in a manifest written by
+// WriteUsageManifest from the suite files and directories in it, and returns them like
+// PruneSnapshots.
+func PruneFromManifest(fs afero.Fs, manifest []byte) (map[string][]string, error) {
+	var entries map[string]usageManifestEntry
+
+	if err := json.Unmarshal(manifest, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode usage manifest: %w", err)
+	}
+
+	usages := make(map[string]*snapshotUsage, len(entries))
+
+	for path, entry := range entries {
+		usage := &snapshotUsage{fs: fs, dir: entry.Dir, names: map[string]struct{}{}}
+
+		for _, name := range entry.Names {
+			usage.names[name] = struct{}{}
+		}
+
+		usages[path] = usage
+	}
+
+	return findOrphanedSnapshotsIn(usages, true)
+}
+
+func findOrphanedSnapshotsIn(usages map[string]*snapshotUsage, prune bool) (map[string][]string, error) {
+	result := map[string][]string{}
+	paths := make([]string, 0, len(usages))
+
+	for path := range usages {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		usage := usages[path]
+		find := findOrphanedSuiteSnapshots
+
+		if usage.dir {
+			find = findOrphanedDirSnapshots
+		}
+
+		orphans, err := find(path, usage, prune)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(orphans) > 0 {
+			result[path] = orphans
+		}
+	}
+
+	return result, nil
+}
+
+func findOrphanedSuiteSnapshots(path string, usage *snapshotUsage, prune bool) ([]string, error) {
+	storage := &SuiteStorage{Path: path, Fs: usage.fs}
+
+	findOrphans := func(data *suiteData) []string {
+		var orphans []string
+
+		for _, name := range data.sortSnapshotAndVariantKeys() {
+			if _, ok := usage.names[name]; !ok {
+				orphans = append(orphans, name)
+				data.deleteSnapshot(name)
+			}
+		}
+
+		return orphans
+	}
+
+	if !prune {
+		data, err := storage.getSuiteData()
+		if err != nil {
+			if errors.Is(err, afero.ErrFileNotFound) {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		return findOrphans(data), nil
+	}
+
+	var orphans []string
+
+	err := storage.updateSuiteData(func(data *suiteData) error {
+		if orphans = findOrphans(data); len(orphans) == 0 {
+			return errNothingToPrune
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errNothingToPrune) {
+		return nil, err
+	}
+
+	return orphans, nil
+}
+
+func findOrphanedDirSnapshots(path string, usage *snapshotUsage, prune bool) ([]string, error) {
+	infos, err := afero.ReadDir(usage.fs, path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var (
+		orphans []string
+		removed []string
+	)
+
+	for _, info := range infos {
+		name := info.Name()
+
+		if info.IsDir() || !strings.HasSuffix(name, goldenExt) {
+			continue
+		}
+
+		if _, ok := usage.names[name]; ok {
+			continue
+		}
+
+		// Keep variants of used snapshots, which may belong to other environments.
+		if _, ok := usage.names[trimVariant(name)]; ok {
+			continue
+		}
+
+		orphans = append(orphans, name)
+
+		if prune {
+			if err := usage.fs.Remove(filepath.Join(path, name)); err != nil {
+				return nil, err
+			}
+
+			removed = append(removed, strings.TrimSuffix(name, goldenExt))
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := (&DirStorage{Dir: path, Fs: usage.fs}).removeNames(removed...); err != nil {
+			return nil, err
+		}
+	}
+
+	return orphans, nil
+}
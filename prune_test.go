@@ -0,0 +1,125 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Orphaned snapshots", func() {
+	var (
+		fs    afero.Fs
+		suite *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		suite = &SuiteStorage{Path: "prune.golden", Fs: fs}
+
+		for _, name := range []string{"a", "b", "c", "d"} {
+			Expect(suite.Named(name).Write([]byte(name))).To(Succeed())
+		}
+
+		other := &SuiteStorage{Path: "other.golden", Fs: fs}
+		Expect(other.Named("x").Write([]byte("x"))).To(Succeed())
+
+		resetSnapshotUsages()
+		isPartialRun = func() bool { return false }
+
+		for _, name := range []string{"a", "c"} {
+			Expect(newMatcher("prune.golden", name,
+				WithStorage(suite.Named(name)),
+				WithSerializer(&StringSerializer{}),
+			).Match(name)).To(BeTrue())
+		}
+
+		_, _ = suite.Named("b").Read()
+	})
+
+	AfterEach(func() {
+		resetSnapshotUsages()
+		isPartialRun = detectPartialRun
+	})
+
+	Describe("OrphanedSnapshots", func() {
+		It("should return snapshots not used in the run", func() {
+			Expect(OrphanedSnapshots()).To(Equal(map[string][]string{
+				"prune.golden": {"b", "d"},
+			}))
+		})
+
+		It("should not change the file", func() {
+			_, err := OrphanedSnapshots()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suite.Named("b").Read()).To(Equal([]byte("b")))
+		})
+	})
+
+	Describe("PruneSnapshots", func() {
+		It("should remove snapshots not used in the run", func() {
+			Expect(PruneSnapshots()).To(Equal(map[string][]string{
+				"prune.golden": {"b", "d"},
+			}))
+			Expect(ParseSuite(mustReadFile(fs, "prune.golden"))).To(Equal(map[string]string{
+				"a": "a",
+				"c": "c",
+			}))
+			Expect(ParseSuite(mustReadFile(fs, "other.golden"))).To(Equal(map[string]string{
+				"x": "x",
+			}))
+		})
+
+		It("should refuse to prune when not all tests ran", func() {
+			isPartialRun = func() bool { return true }
+			_, err := PruneSnapshots()
+			Expect(err).To(MatchError(ErrPartialRun))
+			Expect(suite.Named("b").Read()).To(Equal([]byte("b")))
+		})
+	})
+
+	Describe("WriteUsageManifest", func() {
+		It("should write matched snapshots by absolute path", func() {
+			var buf bytes.Buffer
+			Expect(WriteUsageManifest(&buf)).To(Succeed())
+
+			path, err := filepath.Abs("prune.golden")
+			Expect(err).NotTo(HaveOccurred())
+
+			var manifest map[string]usageManifestEntry
+			Expect(json.Unmarshal(buf.Bytes(), &manifest)).To(Succeed())
+			Expect(manifest).To(Equal(map[string]usageManifestEntry{
+				path: {Names: []string{"a", "c"}},
+			}))
+		})
+
+		It("should refuse to write when not all tests ran", func() {
+			isPartialRun = func() bool { return true }
+			Expect(WriteUsageManifest(&bytes.Buffer{})).To(MatchError(ErrPartialRun))
+		})
+	})
+
+	Describe("PruneFromManifest", func() {
+		It("should remove snapshots not in the manifest", func() {
+			manifest := []byte(`{"prune.golden": {"names": ["a", "b"]}}`)
+			Expect(PruneFromManifest(fs, manifest)).To(Equal(map[string][]string{
+				"prune.golden": {"c", "d"},
+			}))
+			Expect(ParseSuite(mustReadFile(fs, "prune.golden"))).To(Equal(map[string]string{
+				"a": "a",
+				"b": "b",
+			}))
+			Expect(ParseSuite(mustReadFile(fs, "other.golden"))).To(Equal(map[string]string{
+				"x": "x",
+			}))
+		})
+
+		It("should return error when the manifest is invalid", func() {
+			_, err := PruneFromManifest(fs, []byte("{"))
+			Expect(err).To(MatchError(ContainSubstring("failed to decode usage manifest")))
+		})
+	})
+})
@@ -0,0 +1,101 @@
+package goldga
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	receivedExt    = ".received"
+	receivedDirEnv = "GOLDGA_RECEIVED_DIR"
+)
+
+// nolint: gochecknoglobals
+var receivedFs = defaultBaseFs
+
+// WithReceivedFile writes the actual content to a ".received" file on mismatch, or when the golden
+// file is missing and UpdatePolicyNever is used, so it can be inspected or collected as a CI
+// artifact. The file is written to dir, or next to the golden file if dir is empty. It is enabled
+// for all matchers when GOLDGA_RECEIVED_DIR is set.
+func WithReceivedFile(dir string) Option {
+	return func(matcher *Matcher) {
+		matcher.WriteReceived = true
+		matcher.ReceivedDir = dir
+	}
+}
+
+// getReceivedPath returns the path of the received file of a snapshot, or an empty string if the
+// storage is unknown, in which case no received file is written.
+func getReceivedPath(storage Storage, dir string) string {
+	var path string
+
+	// Received content would be written in plain text.
+	if isEncrypted(storage) {
+		return ""
+	}
+
+	switch s := storage.(type) {
+	case *SingleStorage:
+		path = s.Path
+	case *DirStorage:
+		path = filepath.Join(s.Dir, s.fileName())
+	case *SuiteStorage:
+		path = filepath.Join(strings.TrimSuffix(s.Path, goldenExt), sanitizeFileName(s.Name)+goldenExt)
+	case StorageWrapper:
+		return getReceivedPath(s.Unwrap(), dir)
+	default:
+		return ""
+	}
+
+	if dir != "" {
+		path = filepath.Join(dir, path)
+	}
+
+	return path + receivedExt
+}
+
+func (m *Matcher) writeReceived(content []byte) error {
+	return m.writeReceivedWith(func(w io.Writer) error {
+		_, err := w.Write(content)
+
+		return err
+	})
+}
+
+// writeReceivedWith writes the received file with write, if received files are enabled.
+func (m *Matcher) writeReceivedWith(write func(w io.Writer) error) error {
+	if !m.WriteReceived {
+		return nil
+	}
+
+	path := getReceivedPath(m.Storage, m.ReceivedDir)
+	if path == "" {
+		return nil
+	}
+
+	if err := receivedFs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	file, err := receivedFs.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write received file: %w", err)
+	}
+
+	err = write(file)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write received file: %w", err)
+	}
+
+	m.receivedPath = path
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("getReceivedPath", func() {
+	It("should be next to a single file", func() {
+		Expect(getReceivedPath(&SingleStorage{Path: "testdata/foo.golden"}, "")).
+			To(Equal("testdata/foo.golden.received"))
+	})
+
+	It("should be in the directory of a suite file", func() {
+		Expect(getReceivedPath(&SuiteStorage{Path: "testdata/foo.golden", Name: "a b"}, "")).
+			To(Equal(filepath.Join("testdata", "foo", "a_b.golden.received")))
+	})
+
+	It("should be in the given directory", func() {
+		Expect(getReceivedPath(&DirStorage{Dir: "testdata/foo", Name: "a"}, "out")).
+			To(Equal(filepath.Join("out", "testdata", "foo", "a.golden.received")))
+	})
+
+	It("should be empty for unknown storages", func() {
+		Expect(getReceivedPath(&InlineStorage{}, "")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("WithReceivedFile", func() {
+	var (
+		fs      afero.Fs
+		storage *SingleStorage
+		matcher *Matcher
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		receivedFs = fs
+		storage = &SingleStorage{Path: "testdata/foo.golden", Fs: fs}
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		matcher = newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+			WithReceivedFile("out"),
+		)
+	})
+
+	AfterEach(func() {
+		receivedFs = defaultBaseFs
+	})
+
+	It("should write the actual content on mismatch", func() {
+		Expect(matcher.Match("bar")).To(BeFalse())
+		Expect(afero.ReadFile(fs, "out/testdata/foo.golden.received")).To(Equal([]byte("bar")))
+		Expect(matcher.FailureMessage("bar")).To(HaveSuffix("\nReceived content written to out/testdata/foo.golden.received"))
+	})
+
+	It("should not write on match", func() {
+		Expect(matcher.Match("foo")).To(BeTrue())
+		Expect(afero.Exists(fs, "out/testdata/foo.golden.received")).To(BeFalse())
+	})
+
+	It("should write when update policy is Never", func() {
+		matcher.UpdatePolicy = UpdatePolicyNever
+		Expect(matcher.Match("bar")).To(BeFalse())
+		Expect(afero.Exists(fs, "out/testdata/foo.golden.received")).To(BeTrue())
+	})
+
+	It("should write when the golden file is missing and update policy is Never", func() {
+		Expect(storage.Delete()).To(Succeed())
+		matcher.UpdatePolicy = UpdatePolicyNever
+		_, err := matcher.Match("bar")
+		Expect(err).To(MatchError(ErrGoldenFileMissing))
+		Expect(afero.ReadFile(fs, "out/testdata/foo.golden.received")).To(Equal([]byte("bar")))
+	})
+
+	It("should write streamed content when the golden file is missing and update policy is Never", func() {
+		Expect(storage.Delete()).To(Succeed())
+		matcher.UpdatePolicy = UpdatePolicyNever
+		matcher.Streaming = true
+		_, err := matcher.Match("bar")
+		Expect(err).To(MatchError(ErrGoldenFileMissing))
+		Expect(afero.ReadFile(fs, "out/testdata/foo.golden.received")).To(Equal([]byte("bar")))
+	})
+})
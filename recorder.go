@@ -0,0 +1,92 @@
+package goldga
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Recorder collects values emitted over the course of a test, such as events, so they can be
+// matched against a single snapshot in order:
+//
+//	rec := goldga.NewRecorder()
+//	rec.Add(event)
+//	rec.AddLabeled("response", res)
+//	Expect(rec).To(goldga.Match())
+//
+// Each value is transformed and serialized by the matcher on its own and stored in a section
+// named "<index>: <label>", which can be selected with WithSection. Values may have different
+// types. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []recordedValue
+}
+
+type recordedValue struct {
+	label string
+	value interface{}
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Add records value, labeled with its type.
+func (r *Recorder) Add(value interface{}) {
+	r.AddLabeled(fmt.Sprintf("%T", value), value)
+}
+
+// AddLabeled records value with the given label.
+func (r *Recorder) AddLabeled(label string, value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, recordedValue{label: label, value: value})
+}
+
+// Len returns the number of recorded values.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.entries)
+}
+
+func (r *Recorder) snapshot() []recordedValue {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]recordedValue{}, r.entries...)
+}
+
+// serializeRecorder serializes each recorded value into its own section.
+func (m *Matcher) serializeRecorder(w io.Writer, r *Recorder) error {
+	entries := r.snapshot()
+	sections := make([]Section, len(entries))
+
+	for i, entry := range entries {
+		var buf bytes.Buffer
+
+		if err := m.serialize(&buf, entry.value); err != nil {
+			return fmt.Errorf("recorded value %d (%s): %w", i, entry.label, err)
+		}
+
+		sections[i] = Section{
+			Name:    fmt.Sprintf("%d: %s", i, entry.label),
+			Content: buf.Bytes(),
+		}
+	}
+
+	data, err := JoinSections(sections...)
+	if err != nil {
+		return fmt.Errorf("recorded values: %w", err)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
+	return nil
+}
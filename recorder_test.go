@@ -0,0 +1,107 @@
+package goldga
+
+import (
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type failingTransformer struct{}
+
+func (failingTransformer) Transform(interface{}) (interface{}, error) {
+	return nil, errors.New("failed")
+}
+
+var _ = Describe("Recorder", func() {
+	var (
+		fs      afero.Fs
+		storage Storage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		storage = (&SuiteStorage{Path: "recorder.golden", Fs: fs}).Named("foo")
+	})
+
+	newRecorderMatcher := func(opts ...Option) *Matcher {
+		return newMatcher("foo", "foo", append([]Option{
+			WithStorage(storage),
+			WithSerializer(&JSONSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+		}, opts...)...)
+	}
+
+	It("should serialize each value in order with its index and label", func() {
+		rec := NewRecorder()
+		rec.Add(map[string]int{"a": 1})
+		rec.AddLabeled("done", true)
+		rec.Add("str")
+
+		Expect(rec.Len()).To(Equal(3))
+		Expect(newRecorderMatcher().Match(rec)).To(BeTrue())
+		Expect(storage.Read()).To(Equal([]byte(
+			"---0: map[string]int---\n{\"a\":1}\n\n---1: done---\ntrue\n\n---2: string---\n\"str\"\n")))
+	})
+
+	It("should fail when the sequence changes", func() {
+		rec := NewRecorder()
+		rec.Add(1)
+		rec.Add(2)
+		Expect(newRecorderMatcher().Match(rec)).To(BeTrue())
+
+		reordered := NewRecorder()
+		reordered.Add(2)
+		reordered.Add(1)
+		Expect(newRecorderMatcher().Match(reordered)).To(BeFalse())
+	})
+
+	It("should compare a single value with WithSection", func() {
+		rec := NewRecorder()
+		rec.AddLabeled("a", 1)
+		rec.AddLabeled("b", 2)
+		Expect(newRecorderMatcher().Match(rec)).To(BeTrue())
+
+		changed := NewRecorder()
+		changed.AddLabeled("a", 1)
+		changed.AddLabeled("b", 3)
+		Expect(newRecorderMatcher(WithSection("0: a")).Match(changed)).To(BeTrue())
+		Expect(newRecorderMatcher(WithSection("1: b")).Match(changed)).To(BeFalse())
+	})
+
+	It("should return the error of a value", func() {
+		rec := NewRecorder()
+		rec.AddLabeled("a", 1)
+
+		_, err := newRecorderMatcher(WithTransformer(failingTransformer{})).Match(rec)
+		Expect(err).To(MatchError(ContainSubstring("recorded value 0 (a)")))
+	})
+
+	It("should return error for labels with line breaks", func() {
+		rec := NewRecorder()
+		rec.AddLabeled("a\nb", 1)
+
+		_, err := newRecorderMatcher().Match(rec)
+		Expect(err).To(MatchError(ErrInvalidSectionName))
+	})
+
+	It("should be safe for concurrent use", func() {
+		rec := NewRecorder()
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+				rec.Add(i)
+			}(i)
+		}
+
+		wg.Wait()
+		Expect(rec.Len()).To(Equal(10))
+	})
+})
@@ -0,0 +1,270 @@
+package goldga
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	redactTagName           = "goldga"
+	redactTagRedact         = "redact"
+	redactTagOmit           = "-"
+	defaultRedactedValue    = "<REDACTED>"
+	redactTagValueSeparator = ","
+	maxRedactDepth          = 100
+)
+
+// nolint: gochecknoglobals
+var (
+	redactTypesMu sync.Mutex
+	redactTypes   = map[reflect.Type]bool{}
+)
+
+var _ Transformer = (*RedactTransformer)(nil)
+
+// RedactTransformer masks and clears struct fields according to their goldga tag, in nested
+// structs, pointers, slices, arrays, maps and interface values:
+//
+//	Password string `goldga:"redact"` // replaced with Replacement
+//	UpdatedAt time.Time `goldga:"-"` // set to the zero value
+//
+// Values are copied into their own types, so serializers see the same types, methods and
+// unexported fields as without redaction. Redacted strings and byte slices are replaced with
+// Replacement, other redacted values are set to their zero value. Fields tagged with "-" are
+// omitted by encoders if they are also tagged with omitempty.
+type RedactTransformer struct {
+	// Inner transforms the input before redaction. Defaults to NopTransformer.
+	Inner Transformer
+	// Replacement replaces redacted values. Defaults to "<REDACTED>".
+	Replacement string
+}
+
+// WithRedaction redacts struct fields according to their goldga tag, see RedactTransformer.
+func WithRedaction() Option {
+	return func(matcher *Matcher) {
+		matcher.Transformer = &RedactTransformer{Inner: matcher.Transformer}
+	}
+}
+
+func (r *RedactTransformer) Transform(input interface{}) (interface{}, error) {
+	if r.Inner != nil {
+		var err error
+
+		if input, err = r.Inner.Transform(input); err != nil {
+			return nil, err
+		}
+	}
+
+	if input == nil {
+		return nil, nil
+	}
+
+	replacement := r.Replacement
+	if replacement == "" {
+		replacement = defaultRedactedValue
+	}
+
+	redactor := &redactor{replacement: replacement, pointers: map[redactPointer]reflect.Value{}}
+
+	return redactor.value(reflect.ValueOf(input)).Interface(), nil
+}
+
+func parseRedactTag(field reflect.StructField) string {
+	tag := field.Tag.Get(redactTagName)
+
+	return strings.Split(tag, redactTagValueSeparator)[0]
+}
+
+// needsRedact reports whether values of t may contain tagged fields, either directly or through
+// interface values. Unexported fields are never redacted.
+func needsRedact(t reflect.Type) bool {
+	redactTypesMu.Lock()
+	defer redactTypesMu.Unlock()
+
+	if result, ok := redactTypes[t]; ok {
+		return result
+	}
+
+	// Results of nested types are incomplete while a recursive type is visited, so only the
+	// result of the top-level type is cached.
+	result := needsRedactVisiting(t, map[reflect.Type]bool{})
+	redactTypes[t] = result
+
+	return result
+}
+
+func needsRedactVisiting(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return needsRedactVisiting(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			if field.PkgPath != "" {
+				continue
+			}
+
+			if tag := parseRedactTag(field); tag == redactTagRedact || tag == redactTagOmit {
+				return true
+			}
+
+			if needsRedactVisiting(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// redactPointer identifies a pointer which has already been copied, so shared and cyclic
+// pointers are copied once.
+type redactPointer struct {
+	ptr uintptr
+	t   reflect.Type
+}
+
+type redactor struct {
+	replacement string
+	pointers    map[redactPointer]reflect.Value
+	depth       int
+}
+
+// value returns a redacted copy of v with the same type, or v itself if nothing has to be
+// redacted.
+func (r *redactor) value(v reflect.Value) reflect.Value {
+	// Stop at cyclic data which is not reached through pointers, e.g. a slice containing itself.
+	if r.depth > maxRedactDepth || !needsRedact(v.Type()) {
+		return v
+	}
+
+	r.depth++
+	defer func() { r.depth-- }()
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.New(v.Type()).Elem()
+		result.Set(r.value(v.Elem()))
+
+		return result
+	case reflect.Ptr:
+		return r.pointerValue(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(r.value(v.Index(i)))
+		}
+
+		return result
+	case reflect.Array:
+		result := reflect.New(v.Type()).Elem()
+
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(r.value(v.Index(i)))
+		}
+
+		return result
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), r.value(iter.Value()))
+		}
+
+		return result
+	case reflect.Struct:
+		return r.structValue(v)
+	default:
+		return v
+	}
+}
+
+func (r *redactor) pointerValue(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return v
+	}
+
+	key := redactPointer{ptr: v.Pointer(), t: v.Type()}
+
+	if result, ok := r.pointers[key]; ok {
+		return result
+	}
+
+	result := reflect.New(v.Type().Elem())
+	r.pointers[key] = result
+	result.Elem().Set(r.value(v.Elem()))
+
+	return result
+}
+
+func (r *redactor) structValue(v reflect.Value) reflect.Value {
+	t := v.Type()
+	result := reflect.New(t).Elem()
+
+	// Unexported fields are copied as is.
+	result.Set(v)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		target := result.Field(i)
+
+		switch parseRedactTag(field) {
+		case redactTagOmit:
+			target.Set(reflect.Zero(field.Type))
+		case redactTagRedact:
+			r.redact(target)
+		default:
+			target.Set(r.value(v.Field(i)))
+		}
+	}
+
+	return result
+}
+
+func (r *redactor) redact(target reflect.Value) {
+	t := target.Type()
+
+	switch {
+	case t.Kind() == reflect.String:
+		target.SetString(r.replacement)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		target.SetBytes([]byte(r.replacement))
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.String:
+		if !target.IsNil() {
+			replacement := reflect.New(t.Elem())
+			replacement.Elem().SetString(r.replacement)
+			target.Set(replacement)
+		}
+	default:
+		target.Set(reflect.Zero(t))
+	}
+}
@@ -0,0 +1,159 @@
+package goldga
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type redactAccount struct {
+	Name     string `json:"name"`
+	Password string `json:"password" goldga:"redact"`
+	Token    []byte `goldga:"redact"`
+	Updated  int64  `goldga:"-"`
+}
+
+type redactUser struct {
+	ID       int
+	Accounts []redactAccount
+	ByName   map[string]*redactAccount
+	Extra    interface{}
+	internal string
+}
+
+type redactPlain struct {
+	Name     string
+	internal string
+}
+
+type redactEvent struct {
+	time.Time
+	Secret   string `json:"secret" goldga:"redact"`
+	internal string
+}
+
+type redactNode struct {
+	Secret string `goldga:"redact"`
+	Next   *redactNode
+}
+
+var _ = Describe("RedactTransformer", func() {
+	var transformer *RedactTransformer
+
+	BeforeEach(func() {
+		transformer = &RedactTransformer{}
+	})
+
+	serialize := func(input interface{}) string {
+		output, err := transformer.Transform(input)
+		Expect(err).NotTo(HaveOccurred())
+
+		var buf bytes.Buffer
+		Expect((&JSONSerializer{}).Serialize(&buf, output)).To(Succeed())
+
+		return buf.String()
+	}
+
+	It("should redact and drop tagged fields", func() {
+		Expect(serialize(redactAccount{Name: "a", Password: "secret", Token: []byte("t"), Updated: 1})).
+			To(MatchJSON(`{"name":"a","password":"<REDACTED>","Token":"PFJFREFDVEVEPg==","Updated":0}`))
+	})
+
+	It("should redact nested structs, slices, maps, pointers and interfaces", func() {
+		account := redactAccount{Name: "a", Password: "secret"}
+		user := &redactUser{
+			ID:       1,
+			Accounts: []redactAccount{account},
+			ByName:   map[string]*redactAccount{"a": &account, "b": nil},
+			Extra:    []interface{}{account},
+			internal: "x",
+		}
+
+		redacted := `{"name":"a","password":"<REDACTED>","Token":"PFJFREFDVEVEPg==","Updated":0}`
+		Expect(serialize(user)).To(MatchJSON(`{
+			"ID": 1,
+			"Accounts": [` + redacted + `],
+			"ByName": {"a": ` + redacted + `, "b": null},
+			"Extra": [` + redacted + `]
+		}`))
+	})
+
+	It("should keep types, methods and unexported fields", func() {
+		at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+		output, err := transformer.Transform(redactEvent{Time: at, Secret: "secret", internal: "x"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal(redactEvent{Time: at, Secret: "<REDACTED>", internal: "x"}))
+
+		// time.Time's MarshalJSON is promoted to redactEvent.
+		Expect(serialize(redactEvent{Time: at, Secret: "secret"})).To(Equal("\"2021-03-04T05:06:07Z\"\n"))
+
+		var buf bytes.Buffer
+		Expect(DefaultSerializer.Serialize(&buf, output)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("(goldga.redactEvent) 2021-03-04 05:06:07 +0000 UTC"))
+	})
+
+	It("should not change the input", func() {
+		account := &redactAccount{Password: "secret"}
+		_, err := transformer.Transform(account)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(account.Password).To(Equal("secret"))
+	})
+
+	It("should copy cyclic pointers once", func() {
+		node := &redactNode{Secret: "a"}
+		node.Next = node
+
+		output, err := transformer.Transform(node)
+		Expect(err).NotTo(HaveOccurred())
+
+		redacted := output.(*redactNode)
+		Expect(redacted.Secret).To(Equal("<REDACTED>"))
+		Expect(redacted.Next).To(BeIdenticalTo(redacted))
+	})
+
+	It("should keep values without tagged fields", func() {
+		value := redactPlain{Name: "a", internal: "b"}
+		Expect(transformer.Transform(value)).To(Equal(value))
+	})
+
+	It("should use a custom replacement", func() {
+		transformer.Replacement = "***"
+		Expect(serialize(redactAccount{Password: "secret"})).To(ContainSubstring(`"password":"***"`))
+	})
+
+	It("should apply the inner transformer first", func() {
+		transformer.Inner = &fakeTransformer{output: redactAccount{Password: "secret"}}
+		Expect(serialize("foo")).To(ContainSubstring(`"password":"<REDACTED>"`))
+	})
+
+	It("should handle nil", func() {
+		Expect(transformer.Transform(nil)).To(BeNil())
+	})
+})
+
+type fakeTransformer struct {
+	output interface{}
+}
+
+func (f *fakeTransformer) Transform(interface{}) (interface{}, error) {
+	return f.output, nil
+}
+
+var _ = Describe("WithRedaction", func() {
+	It("should wrap the transformer", func() {
+		m := newMatcher("foo", "foo", WithRedaction())
+		Expect(m.Transformer).To(Equal(&RedactTransformer{Inner: DefaultTransformer}))
+	})
+
+	It("should work with the default serializer", func() {
+		m := newMatcher("foo", "foo", WithRedaction())
+
+		var buf bytes.Buffer
+		Expect(m.serialize(&buf, redactAccount{Name: "a", Password: "secret"})).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("(goldga.redactAccount) {"))
+		Expect(buf.String()).To(ContainSubstring(`Password: (string) (len=10) "<REDACTED>"`))
+		Expect(buf.String()).To(ContainSubstring("Updated: (int64) 0"))
+	})
+})
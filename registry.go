@@ -0,0 +1,72 @@
+package goldga
+
+import (
+	"reflect"
+	"sync"
+)
+
+type registeredSerializer struct {
+	typ        reflect.Type
+	serializer Serializer
+}
+
+// nolint: gochecknoglobals
+var (
+	serializerRegistryMu sync.RWMutex
+	serializerRegistry   []registeredSerializer
+)
+
+// RegisterSerializer makes matchers using the default serializer serialize values of type t
+// with serializer. If t is an interface type, it applies to all types implementing it. A
+// serializer registered for the exact type takes precedence over interfaces, which are checked
+// in registration order.
+//
+//	goldga.RegisterSerializer(reflect.TypeOf(time.Time{}), &goldga.StringSerializer{})
+//	goldga.RegisterSerializer(reflect.TypeOf((*fmt.Stringer)(nil)).Elem(), &goldga.StringSerializer{})
+func RegisterSerializer(t reflect.Type, serializer Serializer) {
+	serializerRegistryMu.Lock()
+	defer serializerRegistryMu.Unlock()
+
+	for i, r := range serializerRegistry {
+		if r.typ == t {
+			serializerRegistry[i].serializer = serializer
+
+			return
+		}
+	}
+
+	serializerRegistry = append(serializerRegistry, registeredSerializer{typ: t, serializer: serializer})
+}
+
+func resetSerializerRegistry() {
+	serializerRegistryMu.Lock()
+	defer serializerRegistryMu.Unlock()
+
+	serializerRegistry = nil
+}
+
+// lookupSerializer returns the serializer registered for the type of value.
+func lookupSerializer(value interface{}) (Serializer, bool) {
+	if value == nil {
+		return nil, false
+	}
+
+	t := reflect.TypeOf(value)
+
+	serializerRegistryMu.RLock()
+	defer serializerRegistryMu.RUnlock()
+
+	for _, r := range serializerRegistry {
+		if r.typ == t {
+			return r.serializer, true
+		}
+	}
+
+	for _, r := range serializerRegistry {
+		if r.typ.Kind() == reflect.Interface && t.Implements(r.typ) {
+			return r.serializer, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,65 @@
+package goldga
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type registryStringer struct{}
+
+func (registryStringer) String() string {
+	return "stringer"
+}
+
+var _ = Describe("RegisterSerializer", func() {
+	var (
+		matcher *Matcher
+		buf     *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		matcher = newMatcher("foo", "foo")
+		buf = new(bytes.Buffer)
+	})
+
+	AfterEach(func() {
+		resetSerializerRegistry()
+	})
+
+	It("should use the serializer registered for the type", func() {
+		RegisterSerializer(reflect.TypeOf(time.Time{}), &JSONSerializer{})
+		Expect(matcher.serialize(buf, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))).To(Succeed())
+		Expect(buf.String()).To(Equal("\"2020-01-02T03:04:05Z\"\n"))
+	})
+
+	It("should use the serializer registered for an interface", func() {
+		RegisterSerializer(reflect.TypeOf((*fmt.Stringer)(nil)).Elem(), &StringSerializer{})
+		Expect(matcher.serialize(buf, registryStringer{})).To(Succeed())
+		Expect(buf.String()).To(Equal("stringer"))
+	})
+
+	It("should prefer the exact type over interfaces", func() {
+		RegisterSerializer(reflect.TypeOf((*fmt.Stringer)(nil)).Elem(), &StringSerializer{})
+		RegisterSerializer(reflect.TypeOf(registryStringer{}), &JSONSerializer{})
+		Expect(matcher.serialize(buf, registryStringer{})).To(Succeed())
+		Expect(buf.String()).To(Equal("{}\n"))
+	})
+
+	It("should not override an explicit serializer", func() {
+		RegisterSerializer(reflect.TypeOf(""), &JSONSerializer{})
+		matcher.Serializer = &StringSerializer{}
+		Expect(matcher.serialize(buf, "foo")).To(Succeed())
+		Expect(buf.String()).To(Equal("foo"))
+	})
+
+	It("should use the default serializer for other types", func() {
+		RegisterSerializer(reflect.TypeOf(""), &StringSerializer{})
+		Expect(matcher.serialize(buf, 1)).To(Succeed())
+		Expect(buf.String()).To(Equal("(int) 1\n"))
+	})
+})
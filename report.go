@@ -0,0 +1,255 @@
+package goldga
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andreyvit/diff"
+)
+
+// SnapshotFailure is a snapshot which did not match its golden file during this run. Expected is
+// empty when the golden file is missing.
+type SnapshotFailure struct {
+	Name     string
+	Path     string
+	Expected string
+	Actual   string
+}
+
+const (
+	reportEnv = "GOLDGA_REPORT"
+
+	// redactedContent replaces the content of encrypted snapshots in reports.
+	redactedContent = "<encrypted snapshot content is not reported>\n"
+)
+
+// nolint: gochecknoglobals
+var (
+	failuresMu      sync.Mutex
+	failures        []SnapshotFailure
+	failuresEnabled bool
+)
+
+// EnableReport records the snapshots which do not match for Failures, WriteHTMLReport and
+// WriteMarkdownReport. Failures keep the whole content of both sides in memory, so they are only
+// recorded once EnableReport is called or when GOLDGA_REPORT=1 is set.
+func EnableReport() {
+	failuresMu.Lock()
+	defer failuresMu.Unlock()
+
+	failuresEnabled = true
+}
+
+func recordFailure(storage Storage, expected, actual []byte) {
+	failuresMu.Lock()
+	defer failuresMu.Unlock()
+
+	if enabled, _ := strconv.ParseBool(os.Getenv(reportEnv)); !failuresEnabled && !enabled {
+		return
+	}
+
+	// Reports are written as CI artifacts, which must not contain encrypted content in plain text.
+	if isEncrypted(storage) {
+		if expected != nil {
+			expected = []byte(redactedContent)
+		}
+
+		actual = []byte(redactedContent)
+	}
+
+	failures = append(failures, SnapshotFailure{
+		Name:     getStorageName(storage),
+		Path:     getStoragePath(storage),
+		Expected: string(expected),
+		Actual:   string(actual),
+	})
+}
+
+func resetFailures() {
+	failuresMu.Lock()
+	defer failuresMu.Unlock()
+
+	failures = nil
+	failuresEnabled = false
+}
+
+// Failures returns the snapshots which did not match during this run.
+func Failures() []SnapshotFailure {
+	failuresMu.Lock()
+	defer failuresMu.Unlock()
+
+	return append([]SnapshotFailure{}, failures...)
+}
+
+// sideBySideRow is a row of a side-by-side diff. Line numbers are 0 when the side is empty.
+type sideBySideRow struct {
+	Kind    string
+	OldLine int
+	Old     string
+	NewLine int
+	New     string
+}
+
+// sideBySide pairs removed lines with the added lines following them.
+func sideBySide(expected, actual string) []sideBySideRow {
+	var (
+		rows             []sideBySideRow
+		removed, added   []string
+		oldLine, newLine int
+	)
+
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			row := sideBySideRow{Kind: "changed"}
+
+			if i < len(removed) {
+				oldLine++
+				row.OldLine, row.Old = oldLine, removed[i]
+			} else {
+				row.Kind = "added"
+			}
+
+			if i < len(added) {
+				newLine++
+				row.NewLine, row.New = newLine, added[i]
+			} else {
+				row.Kind = "removed"
+			}
+
+			// The line diff may report unchanged lines around changes as removed and added.
+			if row.Kind == "changed" && row.Old == row.New {
+				row.Kind = "same"
+			}
+
+			rows = append(rows, row)
+		}
+
+		removed, added = nil, nil
+	}
+
+	for _, line := range diff.LineDiffAsLines(expected, actual) {
+		switch line[0] {
+		case '-':
+			removed = append(removed, line[1:])
+		case '+':
+			added = append(added, line[1:])
+		default:
+			flush()
+			oldLine++
+			newLine++
+			rows = append(rows, sideBySideRow{Kind: "same", OldLine: oldLine, Old: line[1:], NewLine: newLine, New: line[1:]})
+		}
+	}
+
+	flush()
+
+	return rows
+}
+
+// nolint: gochecknoglobals
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Snapshot report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; width: 100%; font-family: monospace; font-size: 13px; }
+td { padding: 0 6px; white-space: pre-wrap; vertical-align: top; }
+td.num { color: #999; text-align: right; width: 1%; }
+tr.removed td.old, tr.changed td.old { background: #ffebe9; }
+tr.added td.new, tr.changed td.new { background: #e6ffec; }
+</style>
+</head>
+<body>
+<h1>Snapshot report</h1>
+<p>{{len .}} snapshot(s) did not match.</p>
+{{range .}}
+<h2>{{.Name}}</h2>
+<p><code>{{.Path}}</code></p>
+<table>
+<tr><th colspan="2">Expected</th><th colspan="2">Actual</th></tr>
+{{range .Rows}}<tr class="{{.Kind}}"><td class="num">{{if .OldLine}}{{.OldLine}}{{end}}</td><td class="old">{{.Old}}</td><td class="num">{{if .NewLine}}{{.NewLine}}{{end}}</td><td class="new">{{.New}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTMLReport writes an HTML page with a side-by-side diff of every snapshot which did not
+// match during this run, e.g. to upload as a CI artifact from Ginkgo's AfterSuite.
+func WriteHTMLReport(w io.Writer) error {
+	type entry struct {
+		SnapshotFailure
+		Rows []sideBySideRow
+	}
+
+	var entries []entry
+
+	for _, f := range Failures() {
+		entries = append(entries, entry{SnapshotFailure: f, Rows: sideBySide(f.Expected, f.Actual)})
+	}
+
+	if err := htmlReportTemplate.Execute(w, entries); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// WriteMarkdownReport writes a Markdown document with a diff of every snapshot which did not
+// match during this run.
+func WriteMarkdownReport(w io.Writer) error {
+	var sb strings.Builder
+
+	all := Failures()
+
+	sb.WriteString("# Snapshot report\n\n")
+	fmt.Fprintf(&sb, "%d snapshot(s) did not match.\n", len(all))
+
+	for _, f := range all {
+		lines := diff.LineDiffAsLines(f.Expected, f.Actual)
+		fence := markdownFence(strings.Join(lines, "\n"))
+
+		fmt.Fprintf(&sb, "\n## %s\n\n`%s`\n\n%sdiff\n", f.Name, f.Path, fence)
+
+		for _, line := range lines {
+			sb.WriteString(line + "\n")
+		}
+
+		sb.WriteString(fence + "\n")
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// markdownFence returns a code fence longer than any run of backticks in content.
+func markdownFence(content string) string {
+	longest, run := 0, 0
+
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	if longest < 3 {
+		return "```"
+	}
+
+	return strings.Repeat("`", longest+1)
+}
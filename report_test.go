@@ -0,0 +1,116 @@
+package goldga
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("sideBySide", func() {
+	It("should pair removed and added lines", func() {
+		Expect(sideBySide("a\nb\nc\nd", "a\nx\nc\ny\nz")).To(Equal([]sideBySideRow{
+			{Kind: "same", OldLine: 1, Old: "a", NewLine: 1, New: "a"},
+			{Kind: "changed", OldLine: 2, Old: "b", NewLine: 2, New: "x"},
+			{Kind: "same", OldLine: 3, Old: "c", NewLine: 3, New: "c"},
+			{Kind: "changed", OldLine: 4, Old: "d", NewLine: 4, New: "y"},
+			{Kind: "added", NewLine: 5, New: "z"},
+		}))
+	})
+
+	It("should show removed lines", func() {
+		Expect(sideBySide("a\nb", "a")).To(Equal([]sideBySideRow{
+			{Kind: "same", OldLine: 1, Old: "a", NewLine: 1, New: "a"},
+			{Kind: "removed", OldLine: 2, Old: "b"},
+		}))
+	})
+})
+
+var _ = Describe("markdownFence", func() {
+	It("should be longer than backticks in content", func() {
+		Expect(markdownFence("foo")).To(Equal("```"))
+		Expect(markdownFence("a ``` b")).To(Equal("````"))
+	})
+})
+
+var _ = Describe("Reports", func() {
+	BeforeEach(func() {
+		resetFailures()
+		EnableReport()
+
+		storage := &SuiteStorage{Path: "testdata/foo.golden", Fs: afero.NewMemMapFs()}
+		Expect(storage.Named("foo").Write([]byte("a\n<b>\n"))).To(Succeed())
+
+		for _, name := range []string{"foo", "bar"} {
+			m := newMatcher("foo", name,
+				WithStorage(storage.Named(name)),
+				WithSerializer(&StringSerializer{}),
+				WithUpdatePolicy(UpdatePolicyNever),
+			)
+			_, _ = m.Match("a\n<c>\n")
+		}
+
+		Expect(newMatcher("foo", "foo",
+			WithStorage(storage.Named("foo")),
+			WithSerializer(&StringSerializer{}),
+		).Match("a\n<b>\n")).To(BeTrue())
+	})
+
+	AfterEach(func() {
+		resetFailures()
+	})
+
+	It("should not record failures unless enabled", func() {
+		resetFailures()
+
+		_, _ = newMatcher("foo", "foo",
+			WithStorage(&SingleStorage{Path: "foo.golden", Fs: afero.NewMemMapFs()}),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyNever),
+		).Match("foo")
+		Expect(Failures()).To(BeEmpty())
+	})
+
+	It("should not record the content of encrypted snapshots", func() {
+		resetFailures()
+		EnableReport()
+
+		storage := &SingleStorage{Path: "secret.golden", Fs: afero.NewMemMapFs()}
+		key := func() ([]byte, error) { return bytes.Repeat([]byte{1}, 32), nil }
+		Expect((&EncryptedStorage{Inner: storage, Key: key}).Write([]byte("old secret"))).To(Succeed())
+
+		_, _ = newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithEncryption(key),
+			WithCompression(),
+		).Match("new secret")
+		Expect(Failures()).To(Equal([]SnapshotFailure{
+			{Name: "secret.golden", Path: "secret.golden", Expected: redactedContent, Actual: redactedContent},
+		}))
+	})
+
+	It("should record failures", func() {
+		Expect(Failures()).To(Equal([]SnapshotFailure{
+			{Name: "foo", Path: "testdata/foo.golden", Expected: "a\n<b>\n", Actual: "a\n<c>\n"},
+			{Name: "bar", Path: "testdata/foo.golden", Actual: "a\n<c>\n"},
+		}))
+	})
+
+	It("should write an HTML report", func() {
+		var buf bytes.Buffer
+		Expect(WriteHTMLReport(&buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("<p>2 snapshot(s) did not match.</p>"))
+		Expect(buf.String()).To(ContainSubstring("<h2>foo</h2>\n<p><code>testdata/foo.golden</code></p>"))
+		Expect(buf.String()).To(ContainSubstring(`<tr class="changed"><td class="num">2</td><td class="old">&lt;b&gt;</td><td class="num">2</td><td class="new">&lt;c&gt;</td></tr>`))
+		Expect(buf.String()).To(ContainSubstring(`<tr class="added"><td class="num"></td><td class="old"></td><td class="num">1</td><td class="new">a</td></tr>`))
+	})
+
+	It("should write a Markdown report", func() {
+		var buf bytes.Buffer
+		Expect(WriteMarkdownReport(&buf)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("# Snapshot report\n\n2 snapshot(s) did not match.\n\n## foo\n\n`testdata/foo.golden`\n\n```diff\n a\n-<b>\n+<c>\n"))
+		Expect(buf.String()).To(ContainSubstring("## bar\n\n`testdata/foo.golden`\n\n```diff\n+a\n+<c>\n"))
+	})
+})
@@ -0,0 +1,168 @@
+package goldga
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+const origExt = ".orig"
+
+// ErrNoPreviousVersion is returned when a snapshot to roll back has no previous version.
+var ErrNoPreviousVersion = errors.New("snapshot has no previous version")
+
+// RollbackStorage is a Storage which keeps the previous version of overwritten snapshots.
+type RollbackStorage interface {
+	Storage
+
+	// Rollback restores the previous version of the snapshot and discards it.
+	Rollback() error
+}
+
+var (
+	_ RollbackStorage = (*SuiteStorage)(nil)
+	_ RollbackStorage = (*DirStorage)(nil)
+	_ RollbackStorage = (*SingleStorage)(nil)
+)
+
+// WithPreviousVersions keeps the previous content of snapshots overwritten in update mode, so
+// the last update can be rolled back with RollbackSnapshot or "goldga rollback". Suite files keep
+// it in a [previous] table, and other storages in an ".orig" file next to the golden file. Only
+// one generation is kept, and variants in suite files are not kept. Use it after storage options.
+func WithPreviousVersions() Option {
+	return func(matcher *Matcher) {
+		matcher.Storage = mapStorage(matcher.Storage, func(inner Storage) Storage {
+			switch s := inner.(type) {
+			case *SuiteStorage:
+				copied := *s
+				copied.KeepPrevious = true
+
+				return &copied
+			case *DirStorage:
+				copied := *s
+				copied.KeepPrevious = true
+
+				return &copied
+			case *SingleStorage:
+				copied := *s
+				copied.KeepPrevious = true
+
+				return &copied
+			default:
+				return inner
+			}
+		})
+	}
+}
+
+// RollbackSnapshot restores the version of the snapshot in storage before its last update. It
+// returns ErrNoPreviousVersion if no previous version was kept.
+func RollbackSnapshot(storage Storage) error {
+	s, ok := innerStorage(storage).(RollbackStorage)
+	if !ok {
+		return fmt.Errorf("storage %T does not support rollback", storage)
+	}
+
+	return s.Rollback()
+}
+
+func (s *suiteData) setPrevious(name, value string) {
+	if s.Previous == nil {
+		s.Previous = map[string]string{}
+	}
+
+	s.Previous[name] = value
+}
+
+func writeSuitePrevious(w *bufio.Writer, previous map[string]string) error {
+	if len(previous) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "[previous]"); err != nil {
+		return fmt.Errorf("header write error: %w", err)
+	}
+
+	for _, k := range sortKeys(previous) {
+		if _, err := fmt.Fprintf(w, "%q = %s\n", k, tomlMultilineString(previous[k])); err != nil {
+			return fmt.Errorf("snapshot write error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SuiteStorage) Rollback() error {
+	if s.Variant != "" {
+		return fmt.Errorf("rollback of variant %q is not supported", s.Variant)
+	}
+
+	return s.updateSuiteData(func(data *suiteData) error {
+		previous, ok := data.Previous[s.Name]
+		if !ok {
+			return ErrNoPreviousVersion
+		}
+
+		data.Snapshots[s.Name] = previous
+		delete(data.Previous, s.Name)
+
+		if s.Metadata || data.Meta != nil {
+			data.updateMeta(s.Name)
+		}
+
+		return nil
+	})
+}
+
+func (d *DirStorage) Rollback() error {
+	return d.single().Rollback()
+}
+
+// writePrevious copies the current content of path to its ".orig" file if it differs from data.
+func (s *SingleStorage) writePrevious(path string, data []byte) error {
+	current, err := afero.ReadFile(s.Fs, path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if bytes.Equal(current, data) {
+		return nil
+	}
+
+	if err := afero.WriteFile(s.Fs, path+origExt, current, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write previous version: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SingleStorage) Rollback() error {
+	path := s.localePaths()[0]
+
+	previous, err := afero.ReadFile(s.Fs, path+origExt)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNoPreviousVersion
+		}
+
+		return fmt.Errorf("failed to read previous version: %w", err)
+	}
+
+	if err := afero.WriteFile(s.Fs, path, previous, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := s.Fs.Remove(path + origExt); err != nil {
+		return fmt.Errorf("failed to remove previous version: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Rollback", func() {
+	var fs afero.Fs
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+	})
+
+	When("storage is SuiteStorage", func() {
+		var storage *SuiteStorage
+
+		BeforeEach(func() {
+			storage = &SuiteStorage{Path: "golden/suite.golden", Name: "foo", Fs: fs, KeepPrevious: true}
+			Expect(storage.Write([]byte("a\n"))).To(Succeed())
+			Expect(storage.Write([]byte("b\n"))).To(Succeed())
+			Expect(storage.Write([]byte("c\n"))).To(Succeed())
+		})
+
+		It("should keep one previous version", func() {
+			Expect(string(mustReadFile(fs, storage.Path))).To(ContainSubstring("[previous]\n\"foo\" = '''\nb\n'''\n"))
+		})
+
+		It("should restore the previous version", func() {
+			Expect(RollbackSnapshot(storage)).To(Succeed())
+			Expect(storage.Read()).To(Equal([]byte("b\n")))
+			Expect(string(mustReadFile(fs, storage.Path))).NotTo(ContainSubstring("[previous]"))
+			Expect(RollbackSnapshot(storage)).To(MatchError(ErrNoPreviousVersion))
+		})
+
+		It("should not keep unchanged content", func() {
+			other := storage.Named("bar")
+			Expect(other.Write([]byte("x\n"))).To(Succeed())
+			Expect(other.Write([]byte("x\n"))).To(Succeed())
+			Expect(RollbackSnapshot(other)).To(MatchError(ErrNoPreviousVersion))
+		})
+
+		It("should not roll back variants", func() {
+			variant := *storage
+			variant.Variant = "linux"
+			Expect(variant.Write([]byte("d\n"))).To(Succeed())
+			Expect(RollbackSnapshot(&variant)).To(MatchError(`rollback of variant "linux" is not supported`))
+			Expect(storage.Read()).To(Equal([]byte("c\n")))
+			Expect(variant.Read()).To(Equal([]byte("d\n")))
+		})
+
+		It("should drop the previous version when the snapshot is deleted", func() {
+			Expect(storage.Delete()).To(Succeed())
+			Expect(string(mustReadFile(fs, storage.Path))).NotTo(ContainSubstring("[previous]"))
+		})
+	})
+
+	When("storage is DirStorage", func() {
+		var storage *DirStorage
+
+		BeforeEach(func() {
+			storage = &DirStorage{Dir: "golden", Name: "foo", Fs: fs, KeepPrevious: true}
+			Expect(storage.Write([]byte("a"))).To(Succeed())
+			Expect(storage.Write([]byte("b"))).To(Succeed())
+		})
+
+		It("should write an orig file", func() {
+			Expect(mustReadFile(fs, filepath.Join("golden", "foo.golden.orig"))).To(Equal([]byte("a")))
+		})
+
+		It("should restore the previous version", func() {
+			Expect(RollbackSnapshot(storage)).To(Succeed())
+			Expect(storage.Read()).To(Equal([]byte("a")))
+			Expect(afero.Exists(fs, filepath.Join("golden", "foo.golden.orig"))).To(BeFalse())
+			Expect(RollbackSnapshot(storage)).To(MatchError(ErrNoPreviousVersion))
+		})
+	})
+
+	When("previous versions are not kept", func() {
+		It("should fail", func() {
+			storage := &SingleStorage{Path: "foo.golden", Fs: fs}
+			Expect(storage.Write([]byte("a"))).To(Succeed())
+			Expect(storage.Write([]byte("b"))).To(Succeed())
+			Expect(RollbackSnapshot(storage)).To(MatchError(ErrNoPreviousVersion))
+		})
+	})
+
+	It("should be enabled by WithPreviousVersions", func() {
+		m := newMatcher("foo", "foo",
+			WithStorage(&SuiteStorage{Path: "golden/suite.golden", Name: "foo", Fs: fs}),
+			WithPreviousVersions(),
+		)
+		Expect(m.Storage.(*SuiteStorage).KeepPrevious).To(BeTrue())
+	})
+})
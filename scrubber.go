@@ -0,0 +1,56 @@
+package goldga
+
+import (
+	"regexp"
+)
+
+// nolint: gochecknoglobals
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?( ?(Z|[+-]\d{2}:?\d{2}))?( [A-Z]{3,5})?( m=[+-]\d+\.\d+)?`)
+	uuidPattern      = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	pointerPattern   = regexp.MustCompile(`0x[0-9a-f]{6,16}`)
+)
+
+// Scrubber replaces dynamic data such as timestamps in serialized content. Scrubbers are applied
+// to both the actual content and the golden file before comparison.
+type Scrubber interface {
+	Scrub(content []byte) []byte
+}
+
+var _ Scrubber = (*RegexpScrubber)(nil)
+
+type RegexpScrubber struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+func (r *RegexpScrubber) Scrub(content []byte) []byte {
+	return r.Pattern.ReplaceAll(content, []byte(r.Replacement))
+}
+
+// WithScrubber replaces every match of pattern with replacement. The replacement may refer to
+// submatches as in regexp.Regexp.ReplaceAll.
+func WithScrubber(pattern *regexp.Regexp, replacement string) Option {
+	return func(matcher *Matcher) {
+		matcher.Scrubbers = append(matcher.Scrubbers, &RegexpScrubber{
+			Pattern:     pattern,
+			Replacement: replacement,
+		})
+	}
+}
+
+// ScrubTimestamps replaces RFC 3339 timestamps and time.Time strings with "<TIMESTAMP>".
+func ScrubTimestamps() Option {
+	return WithScrubber(timestampPattern, "<TIMESTAMP>")
+}
+
+// ScrubUUIDs replaces UUIDs with "<UUID>".
+func ScrubUUIDs() Option {
+	return WithScrubber(uuidPattern, "<UUID>")
+}
+
+// ScrubPointers replaces memory addresses, such as the ones printed by the default serializer,
+// with "<POINTER>".
+func ScrubPointers() Option {
+	return WithScrubber(pointerPattern, "<POINTER>")
+}
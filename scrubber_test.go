@@ -0,0 +1,36 @@
+package goldga
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scrubbers", func() {
+	DescribeTable("built-in scrubbers", func(option Option, input, expected string) {
+		matcher := &Matcher{}
+		option(matcher)
+		Expect(string(matcher.normalize([]byte(input)))).To(Equal(expected))
+	},
+		Entry("RFC 3339", ScrubTimestamps(), `"createdAt": "2021-09-01T12:34:56.789Z"`, `"createdAt": "<TIMESTAMP>"`),
+		Entry("RFC 3339 with offset", ScrubTimestamps(), `at 2021-09-01T12:34:56+08:00.`, `at <TIMESTAMP>.`),
+		Entry("time.Time", ScrubTimestamps(), `2021-09-01 12:34:56.123456 +0000 UTC m=+0.001`, `<TIMESTAMP>`),
+		Entry("UUID", ScrubUUIDs(), `id: 123e4567-E89B-12d3-a456-426614174000`, `id: <UUID>`),
+		Entry("pointer", ScrubPointers(), `(*int)(0xc00001c030)(42)`, `(*int)(<POINTER>)(42)`),
+	)
+
+	It("should support submatches", func() {
+		matcher := &Matcher{}
+		WithScrubber(regexp.MustCompile(`token=(\w)\w+`), "token=${1}***")(matcher)
+		Expect(string(matcher.normalize([]byte("token=abcdef")))).To(Equal("token=a***"))
+	})
+
+	It("should apply scrubbers in order", func() {
+		matcher := &Matcher{}
+		WithScrubber(regexp.MustCompile(`a`), "b")(matcher)
+		WithScrubber(regexp.MustCompile(`b`), "c")(matcher)
+		Expect(string(matcher.normalize([]byte("ab")))).To(Equal("cc"))
+	})
+})
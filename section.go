@@ -0,0 +1,193 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSectionNotFound is returned when a snapshot does not contain the requested section.
+	ErrSectionNotFound = errors.New("section not found")
+
+	// ErrInvalidSectionName is returned by JoinSections for names which cannot be split again,
+	// which are empty names and names containing line breaks.
+	ErrInvalidSectionName = errors.New("invalid section name")
+)
+
+const (
+	sectionMarkerPrefix = "---"
+	sectionMarkerSuffix = "---"
+	sectionEscape       = '\\'
+)
+
+// Section is a named part of a snapshot value.
+type Section struct {
+	Name    string
+	Content []byte
+}
+
+func sectionMarker(name string) []byte {
+	return []byte(sectionMarkerPrefix + name + sectionMarkerSuffix + "\n")
+}
+
+// JoinSections combines sections into a single snapshot value. Each section starts with a
+// "---name---" line and sections are separated by a newline. Content lines which look like a
+// section marker are escaped with a backslash.
+func JoinSections(sections ...Section) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for i, section := range sections {
+		if section.Name == "" || bytes.ContainsAny([]byte(section.Name), "\r\n") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSectionName, section.Name)
+		}
+
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+
+		buf.Write(sectionMarker(section.Name))
+		buf.Write(escapeSectionContent(section.Content))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SplitSections is the reverse of JoinSections. Content before the first section marker is ignored.
+func SplitSections(data []byte) []Section {
+	var sections []Section
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	current := -1
+	start := 0
+	offset := 0
+
+	closeSection := func(end int) {
+		if current >= 0 {
+			sections[current].Content = unescapeSectionContent(data[start:end])
+		}
+	}
+
+	for _, line := range lines {
+		if name, ok := parseSectionMarker(line); ok {
+			// The newline before a marker separates sections and is not part of the content.
+			end := offset
+			if end > start && data[end-1] == '\n' {
+				end--
+			}
+
+			closeSection(end)
+			sections = append(sections, Section{Name: name})
+			current = len(sections) - 1
+			start = offset + len(line)
+		}
+
+		offset += len(line)
+	}
+
+	closeSection(len(data))
+
+	return sections
+}
+
+func parseSectionMarker(line []byte) (string, bool) {
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		return "", false
+	}
+
+	return parseSectionMarkerText(bytes.TrimSuffix(line, []byte("\n")))
+}
+
+// parseSectionMarkerText parses a marker line without its line break. Markers without a name
+// are not valid.
+func parseSectionMarkerText(line []byte) (string, bool) {
+	if len(line) <= len(sectionMarkerPrefix)+len(sectionMarkerSuffix) ||
+		!bytes.HasPrefix(line, []byte(sectionMarkerPrefix)) ||
+		!bytes.HasSuffix(line, []byte(sectionMarkerSuffix)) {
+		return "", false
+	}
+
+	return string(line[len(sectionMarkerPrefix) : len(line)-len(sectionMarkerSuffix)]), true
+}
+
+// needsSectionEscape reports whether a content line is a section marker after removing leading
+// escape characters. Such lines get one more escape character in JoinSections, which
+// SplitSections removes again.
+func needsSectionEscape(line []byte) bool {
+	_, ok := parseSectionMarkerText(bytes.TrimLeft(bytes.TrimSuffix(line, []byte("\n")), string(sectionEscape)))
+
+	return ok
+}
+
+func escapeSectionContent(content []byte) []byte {
+	if !bytes.Contains(content, []byte(sectionMarkerPrefix)) {
+		return content
+	}
+
+	var buf bytes.Buffer
+
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if needsSectionEscape(line) {
+			buf.WriteByte(sectionEscape)
+		}
+
+		buf.Write(line)
+	}
+
+	return buf.Bytes()
+}
+
+func unescapeSectionContent(content []byte) []byte {
+	if !bytes.Contains(content, []byte{sectionEscape}) {
+		return content
+	}
+
+	var buf bytes.Buffer
+
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if len(line) > 0 && line[0] == sectionEscape && needsSectionEscape(line) {
+			line = line[1:]
+		}
+
+		buf.Write(line)
+	}
+
+	return buf.Bytes()
+}
+
+// WithFilter compares only the part of the content returned by filter, which is applied to both
+// the actual content and the golden file. The whole content is still written to the golden file.
+func WithFilter(filter func(content []byte) []byte) Option {
+	return func(matcher *Matcher) {
+		matcher.Filters = append(matcher.Filters, filter)
+	}
+}
+
+// WithSection compares only the section with the given name, see JoinSections.
+func WithSection(name string) Option {
+	return WithFilter(func(content []byte) []byte {
+		for _, section := range SplitSections(content) {
+			if section.Name == name {
+				return section.Content
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReadSection reads a section of the snapshot with the given name.
+func (s *SuiteStorage) ReadSection(name, section string) ([]byte, error) {
+	data, err := s.Named(name).Read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sec := range SplitSections(data) {
+		if sec.Name == section {
+			return sec.Content, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrSectionNotFound, section)
+}
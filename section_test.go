@@ -0,0 +1,134 @@
+package goldga
+
+import (
+	"errors"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Sections", func() {
+	sections := []Section{
+		{Name: "headers", Content: []byte("Content-Type: text/plain\n")},
+		{Name: "body", Content: []byte("hello\nworld")},
+		{Name: "trailer", Content: []byte("")},
+	}
+
+	It("should split joined sections", func() {
+		Expect(SplitSections(mustJoinSections(sections...))).To(Equal(sections))
+	})
+
+	It("should join split sections", func() {
+		data := mustJoinSections(sections...)
+		Expect(JoinSections(SplitSections(data)...)).To(Equal(data))
+	})
+
+	It("should escape content which looks like a marker", func() {
+		escaped := []Section{
+			{Name: "a", Content: []byte("---b---\n\\---c---\n------\n---d---")},
+			{Name: "b", Content: []byte("x\n")},
+		}
+		data := mustJoinSections(escaped...)
+		Expect(string(data)).To(Equal("---a---\n\\---b---\n\\\\---c---\n------\n\\---d---\n---b---\nx\n"))
+		Expect(SplitSections(data)).To(Equal(escaped))
+	})
+
+	It("should not parse a marker without a name", func() {
+		Expect(SplitSections([]byte("---a---\n------\nfoo"))).To(Equal([]Section{
+			{Name: "a", Content: []byte("------\nfoo")},
+		}))
+	})
+
+	It("should reject invalid names", func() {
+		_, err := JoinSections(Section{Name: ""})
+		Expect(err).To(MatchError(ErrInvalidSectionName))
+		_, err = JoinSections(Section{Name: "a\nb"})
+		Expect(err).To(MatchError(ErrInvalidSectionName))
+	})
+
+	Describe("SuiteStorage.ReadSection", func() {
+		var storage *SuiteStorage
+
+		BeforeEach(func() {
+			storage = &SuiteStorage{
+				Path: filepath.Join("testdata", "section.golden"),
+				Name: "test",
+				Fs:   afero.NewMemMapFs(),
+			}
+			Expect(storage.Write(mustJoinSections(sections...))).To(Succeed())
+		})
+
+		It("should read the full value", func() {
+			Expect(storage.Read()).To(Equal(mustJoinSections(sections...)))
+		})
+
+		It("should read a section", func() {
+			Expect(storage.ReadSection("test", "body")).To(Equal([]byte("hello\nworld")))
+		})
+
+		It("should return error when section does not exist", func() {
+			_, err := storage.ReadSection("test", "foo")
+			Expect(errors.Is(err, ErrSectionNotFound)).To(BeTrue())
+		})
+
+		It("should return error when snapshot does not exist", func() {
+			_, err := storage.ReadSection("foo", "body")
+			Expect(err).To(Equal(afero.ErrFileNotFound))
+		})
+	})
+})
+
+var _ = Describe("WithSection", func() {
+	var (
+		storage *SingleStorage
+		matcher *Matcher
+	)
+
+	golden := mustJoinSections(
+		Section{Name: "users", Content: []byte("alice\n")},
+		Section{Name: "groups", Content: []byte("admin\n")},
+	)
+
+	BeforeEach(func() {
+		storage = &SingleStorage{Path: "foo.golden", Fs: afero.NewMemMapFs()}
+		Expect(storage.Write(golden)).To(Succeed())
+		matcher = newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+			WithSection("users"),
+		)
+	})
+
+	It("should ignore other sections", func() {
+		actual := mustJoinSections(
+			Section{Name: "users", Content: []byte("alice\n")},
+			Section{Name: "groups", Content: []byte("staff\n")},
+		)
+		Expect(matcher.Match(actual)).To(BeTrue())
+	})
+
+	It("should fail if the section differs", func() {
+		actual := mustJoinSections(Section{Name: "users", Content: []byte("bob\n")})
+		Expect(matcher.Match(actual)).To(BeFalse())
+		Expect(matcher.FailureMessage(actual)).NotTo(ContainSubstring("admin"))
+	})
+
+	It("should write the whole content", func() {
+		matcher.UpdatePolicy = UpdatePolicyAlways
+		actual := mustJoinSections(Section{Name: "users", Content: []byte("bob\n")})
+		Expect(matcher.Match(actual)).To(BeTrue())
+		Expect(storage.Read()).To(Equal(actual))
+	})
+})
+
+func mustJoinSections(sections ...Section) []byte {
+	data, err := JoinSections(sections...)
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
@@ -1,9 +1,14 @@
 package goldga
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/davecgh/go-spew/spew"
@@ -21,12 +26,91 @@ type Serializer interface {
 	Serialize(w io.Writer, input interface{}) error
 }
 
+// SerializeOptions are set per matcher and passed to serializers implementing SerializerV2.
+// Zero values keep the settings of the serializer.
+type SerializeOptions struct {
+	// Indent is the number of spaces per indentation level. It is used by DumpSerializer,
+	// JSONSerializer and TOMLSerializer.
+	Indent int
+	// SortKeys sorts map keys in DumpSerializer output. JSON and YAML keys are always sorted.
+	SortKeys bool
+	// MaxDepth limits how deep nested values are dumped by DumpSerializer.
+	MaxDepth int
+	// TimeFormat is the layout time.Time values are formatted with by all built-in serializers.
+	// The input is not changed, the times are rewritten in the encoded output. JSON output cannot
+	// tell times from strings, so strings in RFC 3339 format are formatted as well.
+	TimeFormat string
+}
+
+// SerializerV2 is a Serializer which receives the SerializeOptions of the matcher.
+type SerializerV2 interface {
+	SerializeWithOptions(w io.Writer, input interface{}, options SerializeOptions) error
+}
+
+// AdaptSerializer returns serializer as a SerializerV2. Serializers which do not implement
+// SerializerV2 ignore the options.
+func AdaptSerializer(serializer Serializer) SerializerV2 {
+	if v2, ok := serializer.(SerializerV2); ok {
+		return v2
+	}
+
+	return serializerAdapter{serializer}
+}
+
+type serializerAdapter struct {
+	Serializer
+}
+
+func (s serializerAdapter) SerializeWithOptions(w io.Writer, input interface{}, _ SerializeOptions) error {
+	return s.Serialize(w, input)
+}
+
+// Deserializer decodes serialized content back into a value.
+type Deserializer interface {
+	Deserialize(r io.Reader, output interface{}) error
+}
+
+var (
+	_ Serializer   = (*DumpSerializer)(nil)
+	_ SerializerV2 = (*DumpSerializer)(nil)
+)
+
 type DumpSerializer struct {
 	Config *spew.ConfigState
 }
 
 func (d *DumpSerializer) Serialize(w io.Writer, input interface{}) error {
-	d.Config.Fdump(w, input)
+	return d.SerializeWithOptions(w, input, SerializeOptions{})
+}
+
+func (d *DumpSerializer) SerializeWithOptions(w io.Writer, input interface{}, options SerializeOptions) error {
+	conf := *d.Config
+
+	if options.Indent > 0 {
+		conf.Indent = strings.Repeat(" ", options.Indent)
+	}
+
+	if options.SortKeys {
+		conf.SortKeys = true
+	}
+
+	if options.MaxDepth > 0 {
+		conf.MaxDepth = options.MaxDepth
+	}
+
+	if options.TimeFormat == "" {
+		conf.Fdump(w, input)
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	conf.Fdump(&buf, input)
+
+	if _, err := w.Write(dumpTimeSyntax.format(buf.Bytes(), options.TimeFormat)); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
 
 	return nil
 }
@@ -39,19 +123,53 @@ func newDefaultDumpConfig() *spew.ConfigState {
 	return conf
 }
 
+var (
+	_ Serializer   = (*YAMLSerializer)(nil)
+	_ SerializerV2 = (*YAMLSerializer)(nil)
+	_ Deserializer = (*YAMLSerializer)(nil)
+)
+
+// YAMLSerializer encodes values as YAML. Map keys are sorted, so the output is deterministic.
 type YAMLSerializer struct{}
 
 func (y *YAMLSerializer) Serialize(w io.Writer, input interface{}) error {
-	enc := yaml.NewEncoder(w)
-	defer enc.Close()
+	return y.SerializeWithOptions(w, input, SerializeOptions{})
+}
+
+func (y *YAMLSerializer) SerializeWithOptions(w io.Writer, input interface{}, options SerializeOptions) error {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
 
 	if err := enc.Encode(input); err != nil {
 		return fmt.Errorf("yaml encode error: %w", err)
 	}
 
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("yaml encode error: %w", err)
+	}
+
+	if _, err := w.Write(yamlTimeSyntax.format(buf.Bytes(), options.TimeFormat)); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
 	return nil
 }
 
+func (y *YAMLSerializer) Deserialize(r io.Reader, output interface{}) error {
+	if err := yaml.NewDecoder(r).Decode(output); err != nil {
+		return fmt.Errorf("yaml decode error: %w", err)
+	}
+
+	return nil
+}
+
+var (
+	_ Serializer   = (*JSONSerializer)(nil)
+	_ SerializerV2 = (*JSONSerializer)(nil)
+	_ Deserializer = (*JSONSerializer)(nil)
+)
+
 type JSONSerializer struct {
 	EscapeHTML   bool
 	IndentPrefix string
@@ -59,39 +177,96 @@ type JSONSerializer struct {
 }
 
 func (j *JSONSerializer) Serialize(w io.Writer, input interface{}) error {
-	enc := json.NewEncoder(w)
+	return j.SerializeWithOptions(w, input, SerializeOptions{})
+}
+
+func (j *JSONSerializer) SerializeWithOptions(w io.Writer, input interface{}, options SerializeOptions) error {
+	indent := j.Indent
+	if options.Indent > 0 {
+		indent = strings.Repeat(" ", options.Indent)
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
 	enc.SetEscapeHTML(j.EscapeHTML)
-	enc.SetIndent(j.IndentPrefix, j.Indent)
+	enc.SetIndent(j.IndentPrefix, indent)
 
 	if err := enc.Encode(input); err != nil {
 		return fmt.Errorf("json encode error: %w", err)
 	}
 
+	if _, err := w.Write(jsonTimeSyntax.format(buf.Bytes(), options.TimeFormat)); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
+	return nil
+}
+
+func (j *JSONSerializer) Deserialize(r io.Reader, output interface{}) error {
+	if err := json.NewDecoder(r).Decode(output); err != nil {
+		return fmt.Errorf("json decode error: %w", err)
+	}
+
 	return nil
 }
 
+var (
+	_ Serializer   = (*TOMLSerializer)(nil)
+	_ SerializerV2 = (*TOMLSerializer)(nil)
+)
+
 type TOMLSerializer struct {
 	Indent string
 }
 
 func (t *TOMLSerializer) Serialize(w io.Writer, input interface{}) error {
-	enc := toml.NewEncoder(w)
+	return t.SerializeWithOptions(w, input, SerializeOptions{})
+}
+
+func (t *TOMLSerializer) SerializeWithOptions(w io.Writer, input interface{}, options SerializeOptions) error {
+	var buf bytes.Buffer
+
+	enc := toml.NewEncoder(&buf)
 	enc.Indent = t.Indent
 
+	if options.Indent > 0 {
+		enc.Indent = strings.Repeat(" ", options.Indent)
+	}
+
 	if err := enc.Encode(input); err != nil {
 		return fmt.Errorf("toml encode error: %w", err)
 	}
 
+	if _, err := w.Write(tomlTimeSyntax.format(buf.Bytes(), options.TimeFormat)); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
 	return nil
 }
 
+var (
+	_ Serializer   = (*StringSerializer)(nil)
+	_ SerializerV2 = (*StringSerializer)(nil)
+)
+
 type StringSerializer struct {
 	FallbackSerializer Serializer
 }
 
 func (s *StringSerializer) Serialize(w io.Writer, input interface{}) error {
+	return s.SerializeWithOptions(w, input, SerializeOptions{})
+}
+
+// SerializeWithOptions formats time.Time input with TimeFormat if set and passes options to the
+// fallback serializer.
+func (s *StringSerializer) SerializeWithOptions(w io.Writer, input interface{}, options SerializeOptions) error {
 	var buf []byte
 
+	if t, ok := input.(time.Time); ok && options.TimeFormat != "" {
+		input = t.Format(options.TimeFormat)
+	}
+
 	switch input := input.(type) {
 	case string:
 		buf = []byte(input)
@@ -106,7 +281,7 @@ func (s *StringSerializer) Serialize(w io.Writer, input interface{}) error {
 			fallback = DefaultSerializer
 		}
 
-		if err := fallback.Serialize(w, input); err != nil {
+		if err := AdaptSerializer(fallback).SerializeWithOptions(w, input, options); err != nil {
 			return fmt.Errorf("fallback serialize error: %w", err)
 		}
 
@@ -119,3 +294,81 @@ func (s *StringSerializer) Serialize(w io.Writer, input interface{}) error {
 
 	return nil
 }
+
+const defaultBinaryLineLength = 76
+
+var (
+	_ Serializer   = (*BinarySerializer)(nil)
+	_ Deserializer = (*BinarySerializer)(nil)
+)
+
+// BinarySerializer encodes binary data such as images or protobuf wire bytes as base64 text,
+// so it can be stored in suite files. Input must be a []byte, string or io.Reader.
+type BinarySerializer struct {
+	// LineLength is the maximum length of lines in the output. Defaults to 76.
+	LineLength int
+}
+
+func (b *BinarySerializer) Serialize(w io.Writer, input interface{}) error {
+	var data []byte
+
+	switch input := input.(type) {
+	case []byte:
+		data = input
+	case string:
+		data = []byte(input)
+	case io.Reader:
+		buf, err := ioutil.ReadAll(input)
+		if err != nil {
+			return fmt.Errorf("read error: %w", err)
+		}
+
+		data = buf
+	default:
+		return fmt.Errorf("unsupported binary input type %T", input)
+	}
+
+	lineLength := b.LineLength
+	if lineLength <= 0 {
+		lineLength = defaultBinaryLineLength
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	for len(encoded) > 0 {
+		n := lineLength
+		if n > len(encoded) {
+			n = len(encoded)
+		}
+
+		if _, err := fmt.Fprintln(w, encoded[:n]); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+
+		encoded = encoded[n:]
+	}
+
+	return nil
+}
+
+// Deserialize decodes base64 text into a *[]byte.
+func (b *BinarySerializer) Deserialize(r io.Reader, output interface{}) error {
+	out, ok := output.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unsupported binary output type %T", output)
+	}
+
+	text, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read error: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(text)), ""))
+	if err != nil {
+		return fmt.Errorf("base64 decode error: %w", err)
+	}
+
+	*out = data
+
+	return nil
+}
@@ -3,6 +3,9 @@ package goldga
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	. "github.com/onsi/ginkgo"
@@ -49,6 +52,36 @@ var _ = Describe("YAMLSerializer", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(testSerializer(&YAMLSerializer{})).To(MatchYAML(expected))
 	})
+
+	It("should sort map keys", func() {
+		input := map[string]interface{}{}
+
+		for _, k := range []string{"z", "b", "y", "a", "x", "c"} {
+			input[k] = map[string]int{"z": 1, "a": 2}
+		}
+
+		var buf bytes.Buffer
+		Expect((&YAMLSerializer{}).Serialize(&buf, input)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("a:\n  a: 2\n  z: 1\nb:\n"))
+	})
+
+	It("should round-trip", func() {
+		type config struct {
+			Name  string            `yaml:"name"`
+			Tags  []string          `yaml:"tags"`
+			Extra map[string]string `yaml:"extra"`
+		}
+
+		input := config{Name: "foo", Tags: []string{"a", "b"}, Extra: map[string]string{"k": "v"}}
+		serializer := &YAMLSerializer{}
+
+		var buf bytes.Buffer
+		Expect(serializer.Serialize(&buf, input)).To(Succeed())
+
+		var output config
+		Expect(serializer.Deserialize(&buf, &output)).To(Succeed())
+		Expect(output).To(Equal(input))
+	})
 })
 
 var _ = Describe("JSONSerializer", func() {
@@ -57,6 +90,17 @@ var _ = Describe("JSONSerializer", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(testSerializer(&JSONSerializer{})).To(MatchJSON(expected))
 	})
+
+	It("should round-trip", func() {
+		serializer := &JSONSerializer{}
+
+		var buf bytes.Buffer
+		Expect(serializer.Serialize(&buf, serializerTestData)).To(Succeed())
+
+		var output map[string]interface{}
+		Expect(serializer.Deserialize(&buf, &output)).To(Succeed())
+		Expect(output).To(Equal(serializerTestData))
+	})
 })
 
 var _ = Describe("TOMLSerializer", func() {
@@ -102,3 +146,145 @@ var _ = Describe("StringSerializer", func() {
 		})
 	})
 })
+
+var _ = Describe("BinarySerializer", func() {
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe}
+
+	DescribeTable("Serialize", func(input interface{}) {
+		var buf bytes.Buffer
+		Expect((&BinarySerializer{}).Serialize(&buf, input)).To(Succeed())
+		Expect(buf.String()).To(Equal("iVBORwD//g==\n"))
+	},
+		Entry("[]byte", data),
+		Entry("string", string(data)),
+		Entry("io.Reader", bytes.NewReader(data)),
+	)
+
+	It("should wrap lines", func() {
+		var buf bytes.Buffer
+		Expect((&BinarySerializer{LineLength: 4}).Serialize(&buf, data)).To(Succeed())
+		Expect(buf.String()).To(Equal("iVBO\nRwD/\n/g==\n"))
+	})
+
+	It("should round-trip", func() {
+		var (
+			buf    bytes.Buffer
+			output []byte
+		)
+
+		serializer := &BinarySerializer{LineLength: 4}
+		Expect(serializer.Serialize(&buf, data)).To(Succeed())
+		Expect(serializer.Deserialize(&buf, &output)).To(Succeed())
+		Expect(output).To(Equal(data))
+	})
+
+	It("should return error on unsupported input", func() {
+		var buf bytes.Buffer
+		Expect((&BinarySerializer{}).Serialize(&buf, 42)).NotTo(Succeed())
+	})
+})
+
+type customJSON struct {
+	Value time.Time
+}
+
+func (customJSON) MarshalJSON() ([]byte, error) {
+	return []byte(`"custom"`), nil
+}
+
+type plainSerializer struct{}
+
+func (plainSerializer) Serialize(w io.Writer, input interface{}) error {
+	_, err := fmt.Fprint(w, input)
+
+	return err
+}
+
+var _ = Describe("SerializeOptions", func() {
+	type event struct {
+		Name string
+		At   time.Time
+		Done *time.Time
+	}
+
+	at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	serialize := func(s Serializer, input interface{}, options SerializeOptions) string {
+		var buf bytes.Buffer
+		Expect(AdaptSerializer(s).SerializeWithOptions(&buf, input, options)).To(Succeed())
+
+		return buf.String()
+	}
+
+	It("should indent JSON", func() {
+		actual := serialize(&JSONSerializer{}, map[string]int{"a": 1}, SerializeOptions{Indent: 4})
+		Expect(actual).To(Equal("{\n    \"a\": 1\n}\n"))
+	})
+
+	It("should indent TOML", func() {
+		input := map[string]interface{}{"a": map[string]int{"b": 1}}
+		actual := serialize(&TOMLSerializer{}, input, SerializeOptions{Indent: 1})
+		Expect(actual).To(Equal("[a]\n b = 1\n"))
+	})
+
+	It("should format times in nested values", func() {
+		input := []interface{}{event{Name: "a", At: at, Done: &at}}
+		actual := serialize(&JSONSerializer{}, input, SerializeOptions{TimeFormat: "2006-01-02"})
+		Expect(actual).To(MatchJSON(`[{"Name":"a","At":"2021-03-04","Done":"2021-03-04"}]`))
+	})
+
+	It("should format times in YAML", func() {
+		actual := serialize(&YAMLSerializer{}, map[string]time.Time{"at": at}, SerializeOptions{TimeFormat: time.Kitchen})
+		Expect(actual).To(Equal("at: 5:06AM\n"))
+	})
+
+	It("should format times in dumps without changing types", func() {
+		serializer := &DumpSerializer{Config: newDefaultDumpConfig()}
+		actual := serialize(serializer, event{Name: "a", At: at.In(time.FixedZone("CET", 3600))},
+			SerializeOptions{TimeFormat: "2006-01-02 15:04 MST"})
+		Expect(actual).To(ContainSubstring("(goldga.event) {"))
+		Expect(actual).To(ContainSubstring("At: (time.Time) 2021-03-04 06:06 CET,"))
+	})
+
+	It("should format times in TOML", func() {
+		actual := serialize(&TOMLSerializer{}, map[string]interface{}{"at": at, "list": []time.Time{at, at}},
+			SerializeOptions{TimeFormat: "2006"})
+		Expect(actual).To(Equal("at = \"2021\"\nlist = [\"2021\", \"2021\"]\n"))
+	})
+
+	It("should keep custom JSON encoding", func() {
+		input := []interface{}{customJSON{}, at}
+		actual := serialize(&JSONSerializer{}, input, SerializeOptions{TimeFormat: "2006"})
+		Expect(actual).To(MatchJSON(`["custom","2021"]`))
+	})
+
+	It("should format time input of StringSerializer", func() {
+		actual := serialize(&StringSerializer{}, at, SerializeOptions{TimeFormat: "2006"})
+		Expect(actual).To(Equal("2021"))
+	})
+
+	It("should pass options to the fallback serializer", func() {
+		serializer := &StringSerializer{FallbackSerializer: &JSONSerializer{}}
+		actual := serialize(serializer, []int{1}, SerializeOptions{Indent: 1})
+		Expect(actual).To(Equal("[\n 1\n]\n"))
+	})
+
+	It("should limit the depth of dumps", func() {
+		input := map[string]interface{}{"a": map[string]interface{}{"b": 1}}
+		serializer := &DumpSerializer{Config: newDefaultDumpConfig()}
+		actual := serialize(serializer, input, SerializeOptions{MaxDepth: 1})
+		Expect(actual).To(ContainSubstring("<max depth reached>"))
+		Expect(actual).NotTo(ContainSubstring("(int) 1"))
+	})
+
+	It("should not change the dump config", func() {
+		conf := newDefaultDumpConfig()
+		serialize(&DumpSerializer{Config: conf}, 1, SerializeOptions{Indent: 4, MaxDepth: 1})
+		Expect(conf.Indent).To(Equal(" "))
+		Expect(conf.MaxDepth).To(Equal(0))
+	})
+
+	It("should ignore options of serializers implementing only Serializer", func() {
+		Expect(serialize(plainSerializer{}, at, SerializeOptions{TimeFormat: "2006"})).To(Equal(at.String()))
+	})
+})
@@ -0,0 +1,84 @@
+package goldga
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/spf13/afero"
+)
+
+// Sign signs the snapshot with the given name, including its variants, and stores the signature
+// in the suite file. Any later change to the snapshot or its variants invalidates the signature.
+func (s *SuiteStorage) Sign(name string, priv ed25519.PrivateKey) error {
+	if len(priv) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid private key length %d, expected %d", len(priv), ed25519.PrivateKeySize)
+	}
+
+	return s.updateSuiteData(func(data *suiteData) error {
+		_, ok := data.Snapshots[name]
+		if _, hasVariants := data.Variants[name]; !ok && !hasVariants {
+			return afero.ErrFileNotFound
+		}
+
+		sig := ed25519.Sign(priv, data.signedContent(name))
+		data.Signatures[name] = base64.StdEncoding.EncodeToString(sig)
+
+		return nil
+	})
+}
+
+// VerifySignatures returns the sorted names of snapshots whose signature is missing or invalid.
+func (s *SuiteStorage) VerifySignatures(pub ed25519.PublicKey) ([]string, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key length %d, expected %d", len(pub), ed25519.PublicKeySize)
+	}
+
+	data, err := s.getSuiteData()
+	if err != nil {
+		return nil, err
+	}
+
+	var invalid []string
+
+	for _, name := range data.sortSnapshotAndVariantKeys() {
+		sig, err := base64.StdEncoding.DecodeString(data.Signatures[name])
+
+		if err != nil || !ed25519.Verify(pub, data.signedContent(name), sig) {
+			invalid = append(invalid, name)
+		}
+	}
+
+	return invalid, nil
+}
+
+// signedContent returns the message signed for a snapshot: whether the snapshot exists, the
+// snapshot and its sorted variants, each prefixed with its length so fields cannot be shifted
+// into each other.
+func (s *suiteData) signedContent(name string) []byte {
+	var buf bytes.Buffer
+
+	if _, ok := s.Snapshots[name]; ok {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+
+	writeField := func(value string) {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(value)))
+		buf.Write(size[:])
+		buf.WriteString(value)
+	}
+
+	writeField(s.Snapshots[name])
+
+	for _, variant := range sortKeys(s.Variants[name]) {
+		writeField(variant)
+		writeField(s.Variants[name][variant])
+	}
+
+	return buf.Bytes()
+}
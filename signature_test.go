@@ -0,0 +1,90 @@
+package goldga
+
+import (
+	"crypto/ed25519"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("SuiteStorage signatures", func() {
+	var (
+		storage *SuiteStorage
+		pub     ed25519.PublicKey
+		priv    ed25519.PrivateKey
+	)
+
+	BeforeEach(func() {
+		var err error
+		pub, priv, err = ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		storage = &SuiteStorage{
+			Path: filepath.Join("testdata", "signature.golden"),
+			Fs:   afero.NewMemMapFs(),
+		}
+		Expect(storage.Named("a").Write([]byte("a"))).To(Succeed())
+		Expect(storage.Named("b").Write([]byte("b"))).To(Succeed())
+		Expect(storage.Sign("a", priv)).To(Succeed())
+	})
+
+	It("should report unsigned snapshots", func() {
+		Expect(storage.VerifySignatures(pub)).To(Equal([]string{"b"}))
+	})
+
+	It("should report nothing when all snapshots are signed", func() {
+		Expect(storage.Sign("b", priv)).To(Succeed())
+		Expect(storage.VerifySignatures(pub)).To(BeEmpty())
+	})
+
+	It("should report tampered snapshots", func() {
+		Expect(storage.Sign("b", priv)).To(Succeed())
+		Expect(storage.Named("a").Write([]byte("changed"))).To(Succeed())
+		Expect(storage.VerifySignatures(pub)).To(Equal([]string{"a"}))
+	})
+
+	It("should report snapshots signed by another key", func() {
+		otherPub, _, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storage.VerifySignatures(otherPub)).To(Equal([]string{"a", "b"}))
+	})
+
+	It("should keep signatures in the suite file", func() {
+		content, err := afero.ReadFile(storage.Fs, storage.Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring("[signatures]\n\"a\" = "))
+	})
+
+	It("should report snapshots with tampered variants", func() {
+		variant := &SuiteStorage{Path: storage.Path, Name: "a", Variant: "linux", Fs: storage.Fs}
+		Expect(variant.Write([]byte("linux"))).To(Succeed())
+		Expect(storage.Sign("a", priv)).To(Succeed())
+		Expect(storage.Sign("b", priv)).To(Succeed())
+		Expect(storage.VerifySignatures(pub)).To(BeEmpty())
+
+		Expect(variant.Write([]byte("changed"))).To(Succeed())
+		Expect(storage.VerifySignatures(pub)).To(Equal([]string{"a"}))
+	})
+
+	It("should report snapshots with added variants", func() {
+		Expect(storage.Sign("b", priv)).To(Succeed())
+		variant := &SuiteStorage{Path: storage.Path, Name: "b", Variant: "linux", Fs: storage.Fs}
+		Expect(variant.Write([]byte("linux"))).To(Succeed())
+		Expect(storage.VerifySignatures(pub)).To(Equal([]string{"b"}))
+	})
+
+	It("should return error when private key is invalid", func() {
+		Expect(storage.Sign("a", priv[:10])).To(MatchError(ContainSubstring("invalid private key length")))
+	})
+
+	It("should return error when public key is invalid", func() {
+		_, err := storage.VerifySignatures(pub[:10])
+		Expect(err).To(MatchError(ContainSubstring("invalid public key length")))
+	})
+
+	It("should return error when snapshot does not exist", func() {
+		Expect(storage.Sign("c", priv)).To(Equal(afero.ErrFileNotFound))
+	})
+})
@@ -0,0 +1,119 @@
+package goldga
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// SimilarityUnit is the unit texts are split into to compute their similarity.
+type SimilarityUnit int
+
+const (
+	// SimilarityLines compares texts line by line.
+	SimilarityLines SimilarityUnit = iota
+	// SimilarityTokens compares whitespace separated tokens, so whitespace changes are ignored.
+	SimilarityTokens
+)
+
+var (
+	_ Comparer = (*SimilarityComparer)(nil)
+	_ Differ   = (*SimilarityComparer)(nil)
+)
+
+// SimilarityComparer matches texts which are at least MinSimilarity similar, for outputs which
+// drift slightly between runs. The similarity is twice the length of the longest common
+// subsequence of lines or tokens divided by their total count, from 0 to 1.
+type SimilarityComparer struct {
+	// MinSimilarity is the minimum similarity from 0 to 1. Only identical units match if it is 1.
+	MinSimilarity float64
+	Unit          SimilarityUnit
+
+	// Differ renders the diff shown above the similarity on mismatch. Defaults to DefaultDiffer.
+	Differ Differ
+}
+
+// WithSimilarity matches content which is at least minSimilarity (from 0 to 1) similar by unit.
+// The diff and the computed similarity are shown on mismatch.
+func WithSimilarity(minSimilarity float64, unit SimilarityUnit) Option {
+	return func(matcher *Matcher) {
+		comparer := &SimilarityComparer{
+			MinSimilarity: minSimilarity,
+			Unit:          unit,
+			Differ:        matcher.Differ,
+		}
+		matcher.Comparer = comparer
+		matcher.Differ = comparer
+	}
+}
+
+func (s *SimilarityComparer) Compare(expected, actual []byte) (bool, error) {
+	if bytes.Equal(expected, actual) {
+		return true, nil
+	}
+
+	return s.Similarity(expected, actual) >= s.MinSimilarity, nil
+}
+
+func (s *SimilarityComparer) Diff(snapshot, received []byte) []byte {
+	differ := s.Differ
+	if differ == nil {
+		differ = DefaultDiffer
+	}
+
+	result := differ.Diff(snapshot, received)
+	msg := fmt.Sprintf("\n\nSimilarity: %.2f%% (min %.2f%%)",
+		100*s.Similarity(snapshot, received), 100*s.MinSimilarity)
+
+	return append(result, msg...)
+}
+
+// Similarity returns the similarity of a and b from 0 to 1.
+func (s *SimilarityComparer) Similarity(a, b []byte) float64 {
+	x, y := s.split(a), s.split(b)
+
+	if len(x)+len(y) == 0 {
+		return 1
+	}
+
+	return 2 * float64(lcsLength(x, y)) / float64(len(x)+len(y))
+}
+
+func (s *SimilarityComparer) split(content []byte) []string {
+	if s.Unit == SimilarityTokens {
+		return strings.Fields(string(content))
+	}
+
+	if len(content) == 0 {
+		return nil
+	}
+
+	return strings.Split(string(content), "\n")
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,72 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("SimilarityComparer", func() {
+	DescribeTable("Similarity", func(unit SimilarityUnit, a, b string, expected float64) {
+		comparer := &SimilarityComparer{Unit: unit}
+		Expect(comparer.Similarity([]byte(a), []byte(b))).To(BeNumerically("~", expected, 1e-9))
+	},
+		Entry("empty", SimilarityLines, "", "", 1.0),
+		Entry("identical lines", SimilarityLines, "a\nb\n", "a\nb\n", 1.0),
+		Entry("one changed line", SimilarityLines, "a\nb\nc\nd", "a\nb\nx\nd", 0.75),
+		Entry("inserted line", SimilarityLines, "a\nb", "a\nx\nb", 0.8),
+		Entry("nothing in common", SimilarityLines, "a", "b", 0.0),
+		Entry("whitespace drift in tokens", SimilarityTokens, "a  b\nc", "a b c\n", 1.0),
+		Entry("changed token", SimilarityTokens, "the quick fox", "the slow fox", 2.0/3),
+	)
+
+	DescribeTable("Compare", func(min float64, a, b string, expected bool) {
+		comparer := &SimilarityComparer{MinSimilarity: min}
+		Expect(comparer.Compare([]byte(a), []byte(b))).To(Equal(expected))
+	},
+		Entry("above threshold", 0.7, "a\nb\nc\nd", "a\nb\nx\nd", true),
+		Entry("below threshold", 0.8, "a\nb\nc\nd", "a\nb\nx\nd", false),
+		Entry("exact match only", 1.0, "a\nb", "a\nc", false),
+		Entry("identical", 1.0, "a\nb", "a\nb", true),
+	)
+
+	It("should show the diff and the similarity", func() {
+		comparer := &SimilarityComparer{MinSimilarity: 0.9, Differ: &UnifiedDiffer{DisableColor: true}}
+		expected := string((&UnifiedDiffer{DisableColor: true}).Diff([]byte("a\nb\n"), []byte("a\nc\n")))
+		Expect(string(comparer.Diff([]byte("a\nb\n"), []byte("a\nc\n")))).
+			To(Equal(expected + "\n\nSimilarity: 66.67% (min 90.00%)"))
+	})
+})
+
+var _ = Describe("WithSimilarity", func() {
+	var suite *SuiteStorage
+
+	BeforeEach(func() {
+		suite = &SuiteStorage{Path: "testdata/foo.golden", Fs: afero.NewMemMapFs()}
+		Expect(suite.Named("foo").Write([]byte("one two three four\n"))).To(Succeed())
+	})
+
+	newSimilarityMatcher := func(opts ...Option) *Matcher {
+		return newMatcher("foo", "foo", append([]Option{
+			WithStorage(suite.Named("foo")),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+		}, opts...)...)
+	}
+
+	It("should default to exact match", func() {
+		Expect(newSimilarityMatcher().Match("one two three  four\n")).To(BeFalse())
+	})
+
+	It("should pass when the content is similar enough", func() {
+		m := newSimilarityMatcher(WithSimilarity(0.7, SimilarityTokens))
+		Expect(m.Match("one two three five\n")).To(BeTrue())
+	})
+
+	It("should fail with the similarity", func() {
+		m := newSimilarityMatcher(WithSimilarity(0.8, SimilarityTokens))
+		Expect(m.Match("one two six five\n")).To(BeFalse())
+		Expect(m.FailureMessage("one two six five\n")).To(ContainSubstring("Similarity: 50.00% (min 80.00%)"))
+	})
+})
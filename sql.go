@@ -0,0 +1,268 @@
+package goldga
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const defaultSQLNull = "NULL"
+
+var _ Serializer = (*SQLRowsSerializer)(nil)
+
+// SQLRowsSerializer renders a *sql.Rows as a table with a header of column names and database
+// types. Strings are quoted, so they can be told apart from numbers and NULL. The rows are
+// consumed and closed.
+type SQLRowsSerializer struct {
+	// SortBy lists the columns used to order rows. Rows are kept in result order if empty.
+	SortBy []string
+	// Null is rendered for NULL values. Defaults to "NULL".
+	Null string
+}
+
+func (s *SQLRowsSerializer) Serialize(w io.Writer, input interface{}) error {
+	rows, ok := input.(*sql.Rows)
+	if !ok {
+		return fmt.Errorf("unsupported SQL input type %T", input)
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	header, err := sqlHeader(rows)
+	if err != nil {
+		return err
+	}
+
+	var values [][]interface{}
+
+	for rows.Next() {
+		row := make([]interface{}, len(header))
+		dest := make([]interface{}, len(header))
+
+		for i := range row {
+			dest[i] = &row[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		values = append(values, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	if err := s.sortRows(columns, values); err != nil {
+		return err
+	}
+
+	table := [][]string{header}
+
+	for _, row := range values {
+		cells := make([]string, len(row))
+
+		for i, v := range row {
+			cells[i] = s.formatValue(v)
+		}
+
+		table = append(table, cells)
+	}
+
+	if _, err := w.Write(formatSQLTable(table)); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLRowsSerializer) sortRows(columns []string, values [][]interface{}) error {
+	if len(s.SortBy) == 0 {
+		return nil
+	}
+
+	indexes := make([]int, len(s.SortBy))
+
+	for i, name := range s.SortBy {
+		indexes[i] = -1
+
+		for j, col := range columns {
+			if col == name {
+				indexes[i] = j
+
+				break
+			}
+		}
+
+		if indexes[i] < 0 {
+			return fmt.Errorf("unknown sort column %q", name)
+		}
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		for _, idx := range indexes {
+			if c := compareSQLValues(values[i][idx], values[j][idx]); c != 0 {
+				return c < 0
+			}
+		}
+
+		return false
+	})
+
+	return nil
+}
+
+func (s *SQLRowsSerializer) formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		if s.Null != "" {
+			return s.Null
+		}
+
+		return defaultSQLNull
+	case []byte:
+		if utf8.Valid(v) {
+			return strconv.Quote(string(v))
+		}
+
+		return fmt.Sprintf("0x%x", v)
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func sqlHeader(rows *sql.Rows) ([]string, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+
+	header := make([]string, len(types))
+
+	for i, t := range types {
+		header[i] = t.Name()
+
+		if name := t.DatabaseTypeName(); name != "" {
+			header[i] += " (" + name + ")"
+		}
+	}
+
+	return header, nil
+}
+
+// compareSQLValues orders NULL first, numbers numerically, times chronologically and other
+// values by their text.
+func compareSQLValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if x, ok := sqlNumber(a); ok {
+		if y, ok := sqlNumber(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(sqlText(a), sqlText(b))
+}
+
+func sqlNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func sqlText(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+
+	return fmt.Sprint(v)
+}
+
+func formatSQLTable(table [][]string) []byte {
+	widths := make([]int, len(table[0]))
+
+	for _, row := range table {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			buf.WriteString("| ")
+			buf.WriteString(cell)
+			buf.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+1))
+		}
+
+		buf.WriteString("|\n")
+	}
+
+	writeRow(table[0])
+
+	for _, w := range widths {
+		buf.WriteString("|")
+		buf.WriteString(strings.Repeat("-", w+2))
+	}
+
+	buf.WriteString("|\n")
+
+	for _, row := range table[1:] {
+		writeRow(row)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,188 @@
+package goldga
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type fakeSQLConnector struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+}
+
+func (c *fakeSQLConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeSQLConn{connector: c}, nil
+}
+
+func (c *fakeSQLConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeSQLConn struct {
+	connector *fakeSQLConnector
+}
+
+func (c *fakeSQLConn) Prepare(string) (driver.Stmt, error) {
+	return &fakeSQLStmt{connector: c.connector}, nil
+}
+
+func (c *fakeSQLConn) Close() error {
+	return nil
+}
+
+func (c *fakeSQLConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+type fakeSQLStmt struct {
+	connector *fakeSQLConnector
+}
+
+func (s *fakeSQLStmt) Close() error {
+	return nil
+}
+
+func (s *fakeSQLStmt) NumInput() int {
+	return 0
+}
+
+func (s *fakeSQLStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *fakeSQLStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeSQLRows{connector: s.connector}, nil
+}
+
+type fakeSQLRows struct {
+	connector *fakeSQLConnector
+	index     int
+}
+
+func (r *fakeSQLRows) Columns() []string {
+	return r.connector.columns
+}
+
+func (r *fakeSQLRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.connector.types[index]
+}
+
+func (r *fakeSQLRows) Close() error {
+	return nil
+}
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.connector.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.connector.rows[r.index])
+	r.index++
+
+	return nil
+}
+
+var _ = Describe("SQLRowsSerializer", func() {
+	var db *sql.DB
+
+	BeforeEach(func() {
+		db = sql.OpenDB(&fakeSQLConnector{
+			columns: []string{"id", "name", "score", "created_at"},
+			types:   []string{"INTEGER", "TEXT", "REAL", ""},
+			rows: [][]driver.Value{
+				{int64(2), "bob", 1.5, time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)},
+				{int64(10), nil, nil, nil},
+				{int64(1), []byte("alice"), 2.25, time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	serialize := func(serializer *SQLRowsSerializer) string {
+		rows, err := db.Query("SELECT")
+		Expect(err).NotTo(HaveOccurred())
+
+		var buf bytes.Buffer
+		Expect(serializer.Serialize(&buf, rows)).To(Succeed())
+
+		return buf.String()
+	}
+
+	It("should render rows in result order", func() {
+		Expect(serialize(&SQLRowsSerializer{})).To(Equal(`| id (INTEGER) | name (TEXT) | score (REAL) | created_at           |
+|--------------|-------------|--------------|----------------------|
+| 2            | "bob"       | 1.5          | 2021-09-01T00:00:00Z |
+| 10           | NULL        | NULL         | NULL                 |
+| 1            | "alice"     | 2.25         | 2021-08-01T00:00:00Z |
+`))
+	})
+
+	It("should sort rows by the given columns", func() {
+		Expect(serialize(&SQLRowsSerializer{SortBy: []string{"id"}, Null: "<null>"})).To(Equal(`| id (INTEGER) | name (TEXT) | score (REAL) | created_at           |
+|--------------|-------------|--------------|----------------------|
+| 1            | "alice"     | 2.25         | 2021-08-01T00:00:00Z |
+| 2            | "bob"       | 1.5          | 2021-09-01T00:00:00Z |
+| 10           | <null>      | <null>       | <null>               |
+`))
+	})
+
+	It("should sort NULL first", func() {
+		Expect(serialize(&SQLRowsSerializer{SortBy: []string{"name"}})).To(HavePrefix(`| id (INTEGER) | name (TEXT) | score (REAL) | created_at           |
+|--------------|-------------|--------------|----------------------|
+| 10           | NULL        | NULL         | NULL                 |
+| 1            | "alice"     |`))
+	})
+
+	It("should return error if the sort column does not exist", func() {
+		rows, err := db.Query("SELECT")
+		Expect(err).NotTo(HaveOccurred())
+		Expect((&SQLRowsSerializer{SortBy: []string{"foo"}}).Serialize(io.Discard, rows)).To(MatchError(`unknown sort column "foo"`))
+	})
+
+	It("should return error if input is not *sql.Rows", func() {
+		Expect((&SQLRowsSerializer{}).Serialize(io.Discard, "foo")).To(MatchError("unsupported SQL input type string"))
+	})
+
+	It("should show the diff of consumed rows on mismatch", func() {
+		fs := afero.NewMemMapFs()
+		storage := &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}
+		Expect(storage.Write([]byte("foo\n"))).To(Succeed())
+
+		m := newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithSerializer(&SQLRowsSerializer{}),
+			WithUnifiedDiff(0),
+		)
+		m.Differ.(*UnifiedDiffer).DisableColor = true
+
+		rows, err := db.Query("SELECT")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Match(rows)).To(BeFalse())
+
+		message := m.FailureMessage(rows)
+		Expect(message).To(ContainSubstring("-foo\n"))
+		Expect(message).To(ContainSubstring(`+| 2            | "bob"       |`))
+	})
+
+	It("should show the error if rows cannot be serialized", func() {
+		rows, err := db.Query("SELECT")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows.Close()).To(Succeed())
+
+		m := newMatcher("foo", "foo", WithStorage(&SuiteStorage{Path: "foo.golden", Name: "foo", Fs: afero.NewMemMapFs()}),
+			WithSerializer(&SQLRowsSerializer{}))
+		Expect(m.FailureMessage(rows)).To(ContainSubstring("failed to get actual content"))
+	})
+})
@@ -0,0 +1,181 @@
+package goldga
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	staleReasonUnused  = "unused"
+	staleReasonExpired = "expired"
+)
+
+var errAlreadyVerified = errors.New("snapshot already verified today")
+
+// StaleSnapshot is a snapshot which may be dead. Reason is "unused" if it was not read or written
+// during this run, or "expired" if it was not verified for longer than the maximum age.
+type StaleSnapshot struct {
+	Path     string     `json:"path"`
+	Name     string     `json:"name"`
+	Reason   string     `json:"reason"`
+	Verified *time.Time `json:"verified,omitempty"`
+}
+
+// WithVerificationTracking records the date a snapshot was last verified in the metadata of
+// suite files, see StaleSnapshots. The date is only written once a day to limit changes, and
+// never when the matcher must not write files, i.e. with UpdatePolicyNever, in strict mode or in
+// a dry run. Use it in local runs, and GOLDGA_UPDATE=never in CI to avoid changes there.
+func WithVerificationTracking() Option {
+	return func(matcher *Matcher) {
+		matcher.TrackVerification = true
+	}
+}
+
+// MarkVerified records in metadata that the snapshot matched today.
+func (s *SuiteStorage) MarkVerified() error {
+	today := now().UTC().Truncate(24 * time.Hour)
+
+	err := s.updateSuiteData(func(data *suiteData) error {
+		if _, ok := data.Snapshots[s.Name]; !ok {
+			return afero.ErrFileNotFound
+		}
+
+		if data.Meta == nil || data.Meta.Snapshots[s.Name].Hash == "" {
+			data.updateMeta(s.Name)
+		}
+
+		meta := data.Meta.Snapshots[s.Name]
+		if meta.Verified.Equal(today) {
+			return errAlreadyVerified
+		}
+
+		meta.Verified = today
+		data.Meta.Snapshots[s.Name] = meta
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAlreadyVerified) {
+		return fmt.Errorf("failed to mark snapshot as verified: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Matcher) markVerified() error {
+	if !m.TrackVerification || m.DryRun || IsStrict() || m.effectiveUpdatePolicy() == UpdatePolicyNever {
+		return nil
+	}
+
+	if s, ok := innerStorage(m.Storage).(*SuiteStorage); ok {
+		return s.MarkVerified()
+	}
+
+	return nil
+}
+
+// StaleSnapshots returns the snapshots in suite files and directories used during this run which
+// were not used. If maxAge is positive, unused snapshots which were last verified or updated
+// longer than maxAge ago according to metadata are returned as expired instead. Snapshots used
+// during this run count as verified. Call it after all tests are done, e.g. in Ginkgo's
+// AfterSuite.
+func StaleSnapshots(maxAge time.Duration) ([]StaleSnapshot, error) {
+	orphans, err := OrphanedSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []StaleSnapshot
+
+	paths := make([]string, 0, len(orphans))
+
+	for path := range orphans {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		verified, err := getVerificationDates(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range orphans[path] {
+			stale := StaleSnapshot{Path: path, Name: name, Reason: staleReasonUnused}
+
+			if last, ok := verified[name]; ok && maxAge > 0 && last.Before(now().Add(-maxAge)) {
+				stale.Reason = staleReasonExpired
+				stale.Verified = &last
+			}
+
+			result = append(result, stale)
+		}
+	}
+
+	return result, nil
+}
+
+// getVerificationDates returns the date each snapshot in a suite file was last verified or
+// updated according to metadata. Directories of DirStorage have no metadata.
+func getVerificationDates(path string) (map[string]time.Time, error) {
+	snapshotUsagesMu.Lock()
+	usage := snapshotUsages[path]
+	snapshotUsagesMu.Unlock()
+
+	if usage == nil || usage.dir {
+		return nil, nil
+	}
+
+	data, err := (&SuiteStorage{Path: path, Fs: usage.fs}).getSuiteData()
+	if err != nil {
+		if errors.Is(err, afero.ErrFileNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if data.Meta == nil {
+		return nil, nil
+	}
+
+	result := map[string]time.Time{}
+
+	for name, meta := range data.Meta.Snapshots {
+		last := meta.Verified
+		if meta.Updated.After(last) {
+			last = meta.Updated
+		}
+
+		result[name] = last
+	}
+
+	return result, nil
+}
+
+// WriteStaleReport writes the result of StaleSnapshots as JSON, e.g. for CI gating.
+func WriteStaleReport(w io.Writer, maxAge time.Duration) error {
+	stale, err := StaleSnapshots(maxAge)
+	if err != nil {
+		return err
+	}
+
+	if stale == nil {
+		stale = []StaleSnapshot{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(stale); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package goldga
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Stale snapshots", func() {
+	var (
+		fs      afero.Fs
+		suite   *SuiteStorage
+		current time.Time
+	)
+
+	BeforeEach(func() {
+		current = time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+		now = func() time.Time { return current }
+
+		fs = afero.NewMemMapFs()
+		suite = &SuiteStorage{Path: "stale.golden", Fs: fs, Metadata: true}
+
+		for _, name := range []string{"a", "b", "c"} {
+			Expect(suite.Named(name).Write([]byte(name))).To(Succeed())
+		}
+
+		resetSnapshotUsages()
+	})
+
+	AfterEach(func() {
+		now = time.Now
+		resetSnapshotUsages()
+	})
+
+	Describe("MarkVerified", func() {
+		It("should record the verification date", func() {
+			Expect(suite.Named("a").(*SuiteStorage).MarkVerified()).To(Succeed())
+			Expect(string(mustReadFile(fs, "stale.golden"))).To(ContainSubstring(
+				"[meta.snapshots.\"a\"]\nhash = \"" + hashSnapshot("a") + "\"\nupdated = 2021-09-01T12:00:00Z\nverified = 2021-09-01T00:00:00Z\n"))
+		})
+
+		It("should keep the verification date when the snapshot is updated", func() {
+			Expect(suite.Named("a").(*SuiteStorage).MarkVerified()).To(Succeed())
+			Expect(suite.Named("a").Write([]byte("a2"))).To(Succeed())
+			Expect(string(mustReadFile(fs, "stale.golden"))).To(ContainSubstring("verified = 2021-09-01T00:00:00Z\n"))
+		})
+
+		It("should return error if the snapshot does not exist", func() {
+			Expect(suite.Named("x").(*SuiteStorage).MarkVerified()).To(MatchError(afero.ErrFileNotFound))
+		})
+	})
+
+	Describe("StaleSnapshots", func() {
+		BeforeEach(func() {
+			current = current.Add(10 * 24 * time.Hour)
+			Expect(suite.Named("a").(*SuiteStorage).MarkVerified()).To(Succeed())
+
+			recordMatchUsage(suite.Named("a"))
+			recordMatchUsage(suite.Named("b"))
+		})
+
+		It("should return unused snapshots", func() {
+			Expect(StaleSnapshots(0)).To(Equal([]StaleSnapshot{
+				{Path: "stale.golden", Name: "c", Reason: "unused"},
+			}))
+		})
+
+		It("should return unused snapshots which expired", func() {
+			updated := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+			// b was used during this run, so it does not expire although it was never verified.
+			Expect(StaleSnapshots(7 * 24 * time.Hour)).To(Equal([]StaleSnapshot{
+				{Path: "stale.golden", Name: "c", Reason: "expired", Verified: &updated},
+			}))
+			Expect(StaleSnapshots(30 * 24 * time.Hour)).To(Equal([]StaleSnapshot{
+				{Path: "stale.golden", Name: "c", Reason: "unused"},
+			}))
+		})
+
+		It("should write a JSON report", func() {
+			var buf bytes.Buffer
+			Expect(WriteStaleReport(&buf, 7*24*time.Hour)).To(Succeed())
+			Expect(buf.String()).To(MatchJSON(`[
+				{"path": "stale.golden", "name": "c", "reason": "expired", "verified": "2021-09-01T12:00:00Z"}
+			]`))
+		})
+	})
+})
+
+var _ = Describe("WithVerificationTracking", func() {
+	It("should mark matched snapshots as verified", func() {
+		fs := afero.NewMemMapFs()
+		storage := &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}
+		m := newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+			WithVerificationTracking(),
+		)
+
+		Expect(m.Match("foo")).To(BeTrue())
+		Expect(string(mustReadFile(fs, "foo.golden"))).NotTo(ContainSubstring("verified"))
+
+		Expect(m.Match("foo")).To(BeTrue())
+		Expect(string(mustReadFile(fs, "foo.golden"))).To(ContainSubstring("verified"))
+	})
+
+	It("should not write files with UpdatePolicyNever", func() {
+		fs := afero.NewMemMapFs()
+		storage := &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+
+		content := mustReadFile(fs, "foo.golden")
+		m := newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyNever),
+			WithVerificationTracking(),
+		)
+
+		Expect(m.Match("foo")).To(BeTrue())
+		Expect(mustReadFile(fs, "foo.golden")).To(Equal(content))
+		Expect(afero.Exists(fs, "foo.golden.bak")).To(BeFalse())
+	})
+})
@@ -0,0 +1,166 @@
+package goldga
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+type statKind int
+
+const (
+	statMatched statKind = iota
+	statMismatched
+	statCreated
+	statUpdated
+	statSkipped
+)
+
+// SnapshotStats summarizes the snapshots used during this run. A snapshot is counted as read
+// when its golden file was read for comparison.
+type SnapshotStats struct {
+	Read       int                  `json:"read"`
+	Matched    int                  `json:"matched"`
+	Mismatched int                  `json:"mismatched"`
+	Created    int                  `json:"created"`
+	Updated    int                  `json:"updated"`
+	Skipped    int                  `json:"skipped"`
+	Files      map[string]FileStats `json:"files"`
+}
+
+// FileStats is the number of snapshots used in a golden file or directory, and the total size
+// of their content in bytes.
+type FileStats struct {
+	Snapshots int   `json:"snapshots"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// nolint: gochecknoglobals
+var (
+	statsMu    sync.Mutex
+	stats      = SnapshotStats{}
+	statsSizes = map[string]map[string]int64{}
+)
+
+func recordStat(storage Storage, kind statKind, read bool, content []byte) {
+	recordStatSize(storage, kind, read, int64(len(content)))
+}
+
+func recordStatSize(storage Storage, kind statKind, read bool, size int64) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	if read {
+		stats.Read++
+	}
+
+	switch kind {
+	case statMatched:
+		stats.Matched++
+	case statMismatched:
+		stats.Mismatched++
+	case statCreated:
+		stats.Created++
+	case statUpdated:
+		stats.Updated++
+	case statSkipped:
+		stats.Skipped++
+	}
+
+	path := getStoragePath(storage)
+	if statsSizes[path] == nil {
+		statsSizes[path] = map[string]int64{}
+	}
+
+	statsSizes[path][getStorageName(storage)] = size
+}
+
+func resetStats() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	stats = SnapshotStats{}
+	statsSizes = map[string]map[string]int64{}
+}
+
+// Stats returns the statistics of snapshots matched during this run.
+func Stats() SnapshotStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	result := stats
+	result.Files = map[string]FileStats{}
+
+	for path, sizes := range statsSizes {
+		var file FileStats
+
+		for _, size := range sizes {
+			file.Snapshots++
+			file.Bytes += size
+		}
+
+		result.Files[path] = file
+	}
+
+	return result
+}
+
+// WriteStats writes a summary of Stats, e.g. in Ginkgo's AfterSuite or after m.Run in TestMain.
+func WriteStats(w io.Writer) error {
+	s := Stats()
+
+	if _, err := fmt.Fprintf(w, "Snapshots: %d read, %d matched, %d mismatched, %d created, %d updated, %d skipped\n",
+		s.Read, s.Matched, s.Mismatched, s.Created, s.Updated, s.Skipped); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
+	paths := make([]string, 0, len(s.Files))
+
+	for path := range s.Files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		file := s.Files[path]
+
+		if _, err := fmt.Fprintf(w, "  %s: %d snapshots, %d bytes\n", path, file.Snapshots, file.Bytes); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteStatsJSON writes Stats as JSON, for CI dashboards.
+func WriteStatsJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(Stats()); err != nil {
+		return fmt.Errorf("json encode error: %w", err)
+	}
+
+	return nil
+}
+
+// getStoragePath returns the golden file or directory of storage.
+func getStoragePath(storage Storage) string {
+	switch s := storage.(type) {
+	case *SuiteStorage:
+		return s.Path
+	case *DirStorage:
+		return s.Dir
+	case *SingleStorage:
+		return s.Path
+	case *InlineStorage:
+		return s.Path
+	case StorageWrapper:
+		return getStoragePath(s.Unwrap())
+	default:
+		return fmt.Sprintf("%T", storage)
+	}
+}
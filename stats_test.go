@@ -0,0 +1,72 @@
+package goldga
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Stats", func() {
+	var suite *SuiteStorage
+
+	newTestMatcher := func(name string, options ...Option) *Matcher {
+		return newMatcher("foo", name, append([]Option{
+			WithStorage(suite.Named(name)),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+		}, options...)...)
+	}
+
+	BeforeEach(func() {
+		resetStats()
+		suite = &SuiteStorage{Path: "foo.golden", Fs: afero.NewMemMapFs()}
+
+		Expect(newTestMatcher("a").Match("aaa")).To(BeTrue())
+		Expect(newTestMatcher("a").Match("aaa")).To(BeTrue())
+		Expect(newTestMatcher("b").Match("b")).To(BeTrue())
+		Expect(newTestMatcher("b").Match("bb")).To(BeFalse())
+		Expect(newTestMatcher("b", WithUpdatePolicy(UpdatePolicyAlways)).Match("bbbb")).To(BeTrue())
+
+		matcher := newTestMatcher("b")
+		matcher.Approver = &fakeApprover{decision: DecisionSkip}
+		_, err := matcher.Match("b")
+		Expect(err).To(MatchError(ErrSnapshotSkipped))
+	})
+
+	AfterEach(func() {
+		resetStats()
+	})
+
+	It("should count snapshots", func() {
+		Expect(Stats()).To(Equal(SnapshotStats{
+			Read:       3,
+			Matched:    1,
+			Mismatched: 1,
+			Created:    2,
+			Updated:    1,
+			Skipped:    1,
+			Files: map[string]FileStats{
+				"foo.golden": {Snapshots: 2, Bytes: 4},
+			},
+		}))
+	})
+
+	It("should write a summary", func() {
+		var buf bytes.Buffer
+		Expect(WriteStats(&buf)).To(Succeed())
+		Expect(buf.String()).To(Equal(`Snapshots: 3 read, 1 matched, 1 mismatched, 2 created, 1 updated, 1 skipped
+  foo.golden: 2 snapshots, 4 bytes
+`))
+	})
+
+	It("should write JSON", func() {
+		var buf bytes.Buffer
+		Expect(WriteStatsJSON(&buf)).To(Succeed())
+		Expect(buf.String()).To(MatchJSON(`{
+			"read": 3, "matched": 1, "mismatched": 1, "created": 2, "updated": 1, "skipped": 1,
+			"files": {"foo.golden": {"snapshots": 2, "bytes": 4}}
+		}`))
+	})
+})
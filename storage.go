@@ -4,71 +4,234 @@ package goldga
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/BurntSushi/toml"
 	"github.com/spf13/afero"
+	yaml "gopkg.in/yaml.v2"
 )
 
+const defaultDecodeRetries = 2
+
 // nolint: gochecknoglobals
-var defaultFs = afero.NewCacheOnReadFs(
-	afero.NewOsFs(),
-	afero.NewMemMapFs(),
-	time.Minute,
+var (
+	defaultBaseFs = afero.NewOsFs()
+	defaultFs     = afero.NewCacheOnReadFs(
+		defaultBaseFs,
+		afero.NewMemMapFs(),
+		time.Minute,
+	)
 )
 
+// StrictNoWrite forbids writing golden files outside update mode, for teams where writing a golden
+// file during a normal run always indicates a miswired test. Match fails with ErrStrictNoWrite
+// instead of writing, and storages written directly, e.g. by a test calling Write, panic with
+// ErrStrictNoWrite unless UPDATE_GOLDEN=1 or GOLDGA_UPDATE selects update or pending mode.
+// nolint: gochecknoglobals
+var StrictNoWrite bool
+
+// nolint: gochecknoglobals
+var strictWritesAllowed int32
+
+// ErrStrictNoWrite is returned by Match, or the panic value of storage writes, when StrictNoWrite
+// is enabled and a golden file would be written outside update mode.
+var ErrStrictNoWrite = errors.New("golden file written outside update mode while StrictNoWrite is enabled, " +
+	"set UPDATE_GOLDEN=1 to update golden files")
+
+// checkStrictNoWrite returns ErrStrictNoWrite if StrictNoWrite forbids writing a golden file with
+// the effective update policy of m. Pending updates are always allowed.
+func (m *Matcher) checkStrictNoWrite() error {
+	if StrictNoWrite && m.effectiveUpdatePolicy() != UpdatePolicyAlways && !m.Pending {
+		return ErrStrictNoWrite
+	}
+
+	return nil
+}
+
+// allowStrictWrites lets storages be written under StrictNoWrite until the returned function is
+// called. Match uses it once checkStrictNoWrite allowed the write with the policy of the matcher.
+func allowStrictWrites() func() {
+	atomic.AddInt32(&strictWritesAllowed, 1)
+
+	return func() {
+		atomic.AddInt32(&strictWritesAllowed, -1)
+	}
+}
+
+// checkStrictWrite panics with ErrStrictNoWrite if a storage is written outside update mode while
+// StrictNoWrite is enabled.
+func checkStrictWrite() {
+	if StrictNoWrite && atomic.LoadInt32(&strictWritesAllowed) == 0 &&
+		getUpdatePolicy() != UpdatePolicyAlways && getUpdateMode() != updateModePending {
+		panic(ErrStrictNoWrite)
+	}
+}
+
+// uncachedFs returns the file system underlying the default cache.
+func uncachedFs(fs afero.Fs) afero.Fs {
+	if fs == defaultFs {
+		return defaultBaseFs
+	}
+
+	return fs
+}
+
+// ErrLFSPointer is returned when a golden file is a git-lfs pointer instead of the actual content.
+var ErrLFSPointer = errors.New("file is a git-lfs pointer which has not been hydrated, run `git lfs pull` to fetch the content")
+
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/"
+
 type Storage interface {
 	Read() ([]byte, error)
 	Write(data []byte) error
 }
 
-var _ Storage = (*SingleStorage)(nil)
+var _ DeletableStorage = (*SingleStorage)(nil)
 
 type SingleStorage struct {
 	Path string
 	Fs   afero.Fs
+
+	// Locale is inserted before the extension of Path (e.g. "greeting.fr-CA.golden").
+	// Read falls back to less specific locales ("fr-CA" -> "fr" -> none) when a file is missing.
+	Locale string
+
+	// KeepPrevious keeps the previous content of an overwritten file in an ".orig" file, see
+	// WithPreviousVersions.
+	KeepPrevious bool
+
+	// exact disables the fallback to less specific locales, see exactStorage.
+	exact bool
+}
+
+// localeFallbacks returns locale followed by its less specific forms ("fr-CA" -> "fr").
+func localeFallbacks(locale string) []string {
+	var locales []string
+
+	for locale != "" {
+		locales = append(locales, locale)
+
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+
+		locale = locale[:i]
+	}
+
+	return locales
+}
+
+// localePaths returns the candidate paths from the most specific locale to Path itself.
+func (s *SingleStorage) localePaths() []string {
+	ext := filepath.Ext(s.Path)
+	base := strings.TrimSuffix(s.Path, ext)
+	paths := []string{}
+
+	for _, locale := range localeFallbacks(s.Locale) {
+		paths = append(paths, base+"."+locale+ext)
+	}
+
+	paths = append(paths, s.Path)
+
+	if s.exact {
+		return paths[:1]
+	}
+
+	return paths
 }
 
 func (s *SingleStorage) Read() ([]byte, error) {
-	data, err := afero.ReadFile(s.Fs, s.Path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+	var err error
+
+	for _, path := range s.localePaths() {
+		var data []byte
+
+		if data, err = afero.ReadFile(s.Fs, path); err == nil {
+			debugf("read %s", path)
+
+			if bytes.HasPrefix(data, []byte(lfsPointerPrefix)) {
+				return nil, fmt.Errorf("%w: %s", ErrLFSPointer, path)
+			}
+
+			return data, nil
+		}
+
+		if !errors.Is(err, afero.ErrFileNotFound) {
+			break
+		}
 	}
 
-	return data, nil
+	return nil, fmt.Errorf("failed to read file: %w", err)
 }
 
 func (s *SingleStorage) Write(data []byte) error {
-	if err := s.Fs.MkdirAll(filepath.Dir(s.Path), os.ModePerm); err != nil {
+	checkStrictWrite()
+
+	path := s.localePaths()[0]
+
+	debugf("write %s", path)
+
+	if err := s.Fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create directories: %w", err)
 	}
 
-	if err := afero.WriteFile(s.Fs, s.Path, data, os.ModePerm); err != nil {
+	if s.KeepPrevious {
+		if err := s.writePrevious(path, data); err != nil {
+			return err
+		}
+	}
+
+	if err := afero.WriteFile(s.Fs, path, data, os.ModePerm); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
+func (s *SingleStorage) Delete() error {
+	if err := s.Fs.Remove(s.localePaths()[0]); err != nil {
+		return fmt.Errorf("failed to remove file: %w", err)
+	}
+
+	return nil
+}
+
 type suiteData struct {
-	Snapshots map[string]string `toml:"snapshots"`
+	Snapshots  map[string]string            `toml:"snapshots" json:"snapshots" yaml:"snapshots"`
+	Variants   map[string]map[string]string `toml:"variants" json:"variants,omitempty" yaml:"variants,omitempty"`
+	Signatures map[string]string            `toml:"signatures" json:"signatures,omitempty" yaml:"signatures,omitempty"`
+	Meta       *suiteMeta                   `toml:"meta" json:"meta,omitempty" yaml:"meta,omitempty"`
+	Previous   map[string]string            `toml:"previous" json:"previous,omitempty" yaml:"previous,omitempty"`
+
+	// format is the format the suite file was decoded from.
+	format SuiteFormat
 }
 
 func newSuiteData() *suiteData {
 	return &suiteData{
-		Snapshots: map[string]string{},
+		Snapshots:  map[string]string{},
+		Variants:   map[string]map[string]string{},
+		Signatures: map[string]string{},
 	}
 }
 
-func (s *suiteData) sortSnapshotKeys() []string {
-	keys := make([]string, 0, len(s.Snapshots))
+func sortKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
 
-	for k := range s.Snapshots {
+	for k := range m {
 		keys = append(keys, k)
 	}
 
@@ -77,16 +240,138 @@ func (s *suiteData) sortSnapshotKeys() []string {
 	return keys
 }
 
-var _ Storage = (*SuiteStorage)(nil)
+func (s *suiteData) sortSnapshotKeys() []string {
+	return sortKeys(s.Snapshots)
+}
+
+func (s *suiteData) deleteSnapshot(name string) {
+	delete(s.Snapshots, name)
+	delete(s.Variants, name)
+	delete(s.Signatures, name)
+	delete(s.Previous, name)
+
+	if s.Meta != nil {
+		delete(s.Meta.Snapshots, name)
+	}
+}
+
+type suiteDecodeError struct {
+	format SuiteFormat
+	err    error
+}
+
+func (e *suiteDecodeError) Error() string {
+	return string(e.format) + " decode error: " + e.err.Error()
+}
+
+func (e *suiteDecodeError) Unwrap() error {
+	return e.err
+}
+
+func decodeSuiteData(r io.Reader) (*suiteData, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	data := newSuiteData()
+	data.format = detectSuiteFormat(content)
+
+	switch data.format {
+	case SuiteFormatJSON:
+		err = json.Unmarshal(content, data)
+	case SuiteFormatYAML:
+		err = yaml.Unmarshal(content, data)
+	default:
+		_, err = toml.Decode(string(content), data)
+	}
+
+	if err != nil {
+		return nil, &suiteDecodeError{format: data.format, err: err}
+	}
+
+	return data, nil
+}
+
+// ParseSuite decodes the content of a suite file and returns its snapshots keyed by name.
+func ParseSuite(data []byte) (map[string]string, error) {
+	suite, err := decodeSuiteData(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return suite.Snapshots, nil
+}
+
+var (
+	_ NamedStorage     = (*SuiteStorage)(nil)
+	_ DeletableStorage = (*SuiteStorage)(nil)
+)
 
 type SuiteStorage struct {
 	Path string
 	Name string
 	Fs   afero.Fs
+
+	// Metadata records the format version, and the content hash and update time of each snapshot
+	// in a [meta] table. Metadata is always kept up to date once a suite file contains it.
+	Metadata bool
+
+	// DecodeRetries is the number of times Write reads the file again, bypassing the default
+	// file cache, when the file cannot be decoded. This works around partial reads on flaky
+	// file systems.
+	DecodeRetries int
+
+	// Variant stores the snapshot in the [variants] table under this key, see Variant.
+	Variant string
+
+	// Format is the encoding of the suite file. Existing files keep their format if empty, and
+	// new files are written as TOML.
+	Format SuiteFormat
+
+	// KeepPrevious keeps the previous content of overwritten snapshots in a [previous] table, see
+	// WithPreviousVersions.
+	KeepPrevious bool
+
+	// exact disables the fallback to less specific variants, see exactStorage.
+	exact bool
+
+	// removeEmpty removes the suite file instead of writing it without snapshots.
+	removeEmpty bool
+}
+
+func (s *SuiteStorage) Named(name string) Storage {
+	named := *s
+	named.Name = name
+
+	return &named
 }
 
 func (s *SuiteStorage) getSuiteData() (*suiteData, error) {
-	exists, err := afero.Exists(s.Fs, s.Path)
+	data, err := s.readSuiteData(s.Fs)
+
+	return s.recoverSuiteData(s.Fs, data, err)
+}
+
+// getSuiteDataWithRetry reads the suite file bypassing the default file cache, which may be
+// stale when other processes write the same file.
+func (s *SuiteStorage) getSuiteDataWithRetry() (*suiteData, error) {
+	data, err := s.readSuiteData(uncachedFs(s.Fs))
+	decodeErr := new(suiteDecodeError)
+
+	for i := 0; i < s.DecodeRetries && errors.As(err, &decodeErr); i++ {
+		data, err = s.readSuiteData(uncachedFs(s.Fs))
+	}
+
+	return s.recoverSuiteData(uncachedFs(s.Fs), data, err)
+}
+
+func (s *SuiteStorage) readSuiteData(fs afero.Fs) (*suiteData, error) {
+	return readSuiteFile(fs, s.Path)
+}
+
+func readSuiteFile(fs afero.Fs, path string) (*suiteData, error) {
+	exists, err := afero.Exists(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check file exist: %w", err)
 	}
@@ -95,37 +380,106 @@ func (s *SuiteStorage) getSuiteData() (*suiteData, error) {
 		return nil, afero.ErrFileNotFound
 	}
 
-	file, err := s.Fs.Open(s.Path)
+	file, err := fs.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	defer file.Close()
 
-	data := newSuiteData()
-
-	if _, err := toml.DecodeReader(file, &data); err != nil {
-		return nil, fmt.Errorf("toml decode error: %w", err)
-	}
-
-	return data, nil
+	return decodeSuiteData(file)
 }
 
 func (s *SuiteStorage) Read() ([]byte, error) {
-	data, err := s.getSuiteData()
+	data, err := s.getCachedSuiteData()
 	if err != nil {
+		if errors.Is(err, afero.ErrFileNotFound) {
+			debugf("snapshot %q not found, %s does not exist", s.Name, s.Path)
+		}
+
 		return nil, err
 	}
 
-	if s, ok := data.Snapshots[s.Name]; ok {
-		return []byte(s), nil
+	for _, variant := range localeFallbacks(s.Variant) {
+		if v, ok := data.Variants[s.Name][variant]; ok {
+			debugf("read %q (variant %s) from %s", s.Name, variant, s.Path)
+
+			return []byte(v), nil
+		}
+
+		if s.exact {
+			return nil, afero.ErrFileNotFound
+		}
+	}
+
+	if v, ok := data.Snapshots[s.Name]; ok {
+		debugf("read %q from %s", s.Name, s.Path)
+
+		return []byte(v), nil
 	}
 
+	debugf("snapshot %q not found in %s", s.Name, s.Path)
+
 	return nil, afero.ErrFileNotFound
 }
 
 func (s *SuiteStorage) Write(input []byte) error {
-	data, err := s.getSuiteData()
+	checkStrictWrite()
+
+	if !utf8.Valid(input) {
+		return errors.New("snapshot is not valid UTF-8, use BinarySerializer to store binary data")
+	}
+
+	debugf("write %q to %s", s.Name, s.Path)
+
+	return s.updateSuiteData(func(data *suiteData) error {
+		if s.Variant != "" {
+			data.setVariant(s.Name, s.Variant, string(input))
+
+			return nil
+		}
+
+		if previous, ok := data.Snapshots[s.Name]; ok && s.KeepPrevious && previous != string(input) {
+			data.setPrevious(s.Name, previous)
+		}
+
+		data.Snapshots[s.Name] = string(input)
+
+		if s.Metadata || data.Meta != nil {
+			data.updateMeta(s.Name)
+		}
+
+		return nil
+	})
+}
+
+func (s *SuiteStorage) Delete() error {
+	return s.updateSuiteData(func(data *suiteData) error {
+		if s.Variant != "" {
+			return data.deleteVariant(s.Name, s.Variant)
+		}
+
+		if _, ok := data.Snapshots[s.Name]; !ok {
+			return afero.ErrFileNotFound
+		}
+
+		data.deleteSnapshot(s.Name)
+
+		return nil
+	})
+}
+
+// updateSuiteData locks the suite file, applies fn to its latest content and writes it back, so
+// snapshots written by parallel processes are merged instead of overwritten.
+func (s *SuiteStorage) updateSuiteData(fn func(data *suiteData) error) error {
+	unlock, err := lockFile(uncachedFs(s.Fs), s.Path+lockExt)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	data, err := s.getSuiteDataWithRetry()
 	if err != nil {
 		if !errors.Is(err, afero.ErrFileNotFound) {
 			return err
@@ -134,22 +488,58 @@ func (s *SuiteStorage) Write(input []byte) error {
 		data = newSuiteData()
 	}
 
-	data.Snapshots[s.Name] = string(input)
+	if err := fn(data); err != nil {
+		return err
+	}
+
+	return s.writeSuiteData(data)
+}
 
+func (s *SuiteStorage) writeSuiteData(data *suiteData) error {
 	if err := s.Fs.MkdirAll(filepath.Dir(s.Path), os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create directories: %w", err)
 	}
 
-	file, err := s.Fs.Create(s.Path)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	if err := s.backupSuiteFile(); err != nil {
+		return err
 	}
 
-	defer file.Close()
+	defer s.invalidateSuiteCache()
+
+	if s.removeEmpty && len(data.Snapshots) == 0 && len(data.Variants) == 0 {
+		if err := s.Fs.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove file: %w", err)
+		}
+
+		return nil
+	}
+
+	return writeFileAtomic(s.Fs, s.Path, func(w *bufio.Writer) error {
+		return s.encodeSuiteData(w, data)
+	})
+}
 
-	w := bufio.NewWriter(file)
+func (s *SuiteStorage) encodeSuiteData(w *bufio.Writer, data *suiteData) error {
+	format := s.Format
+	if format == "" {
+		format = data.format
+	}
+
+	switch format {
+	case "", SuiteFormatTOML:
+		return writeSuiteTOML(w, data)
+	case SuiteFormatJSON:
+		return writeSuiteJSON(w, data)
+	case SuiteFormatYAML:
+		return writeSuiteYAML(w, data)
+	default:
+		return fmt.Errorf("unknown suite format %q", format)
+	}
+}
+
+func writeSuiteTOML(w *bufio.Writer, data *suiteData) error {
 	lines := []string{
-		"# Generated by goldga. DO NOT EDIT.",
+		suiteHeader,
 		"[snapshots]",
 	}
 
@@ -164,14 +554,31 @@ func (s *SuiteStorage) Write(input []byte) error {
 	for _, k := range data.sortSnapshotKeys() {
 		v := data.Snapshots[k]
 
-		if _, err := fmt.Fprintf(w, "%q = '''\n%s'''\n", k, v); err != nil {
+		if _, err := fmt.Fprintf(w, "%q = %s\n", k, tomlMultilineString(v)); err != nil {
 			return fmt.Errorf("snapshot write error: %w", err)
 		}
 	}
 
-	if err := w.Flush(); err != nil {
-		return fmt.Errorf("flush error: %w", err)
+	if err := writeSuiteVariants(w, data.Variants); err != nil {
+		return err
 	}
 
-	return nil
+	if err := writeSuitePrevious(w, data.Previous); err != nil {
+		return err
+	}
+
+	// Print signatures
+	if len(data.Signatures) > 0 {
+		if _, err := fmt.Fprintln(w, "[signatures]"); err != nil {
+			return fmt.Errorf("header write error: %w", err)
+		}
+
+		for _, k := range sortKeys(data.Signatures) {
+			if _, err := fmt.Fprintf(w, "%q = %q\n", k, data.Signatures[k]); err != nil {
+				return fmt.Errorf("signature write error: %w", err)
+			}
+		}
+	}
+
+	return writeSuiteMeta(w, data.Meta)
 }
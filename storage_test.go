@@ -2,6 +2,7 @@ package goldga
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 
@@ -105,6 +106,70 @@ var _ = Describe("SingleStorage", func() {
 	})
 })
 
+var _ = Describe("SingleStorage with Locale", func() {
+	var (
+		storage *SingleStorage
+		fs      afero.Fs
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		storage = &SingleStorage{
+			Path:   filepath.Join("testdata", "greeting.golden"),
+			Locale: "fr-CA",
+			Fs:     fs,
+		}
+	})
+
+	writeFile := func(name, data string) {
+		Expect(afero.WriteFile(fs, filepath.Join("testdata", name), []byte(data), os.ModePerm)).To(Succeed())
+	}
+
+	Context("Read", func() {
+		It("should read the most specific locale", func() {
+			writeFile("greeting.fr-CA.golden", "bonjour, eh")
+			writeFile("greeting.fr.golden", "bonjour")
+			Expect(storage.Read()).To(Equal([]byte("bonjour, eh")))
+		})
+
+		It("should fall back to the parent locale", func() {
+			writeFile("greeting.fr.golden", "bonjour")
+			writeFile("greeting.golden", "hello")
+			Expect(storage.Read()).To(Equal([]byte("bonjour")))
+		})
+
+		It("should fall back to the default path", func() {
+			writeFile("greeting.golden", "hello")
+			Expect(storage.Read()).To(Equal([]byte("hello")))
+		})
+
+		It("should return not found error when no file exists", func() {
+			_, err := storage.Read()
+			Expect(errors.Is(err, afero.ErrFileNotFound)).To(BeTrue())
+		})
+	})
+
+	Context("Read git-lfs pointer", func() {
+		It("should return ErrLFSPointer", func() {
+			writeFile("greeting.golden", `version https://git-lfs.github.com/spec/v1
+oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345
+`)
+			_, err := storage.Read()
+			Expect(errors.Is(err, ErrLFSPointer)).To(BeTrue())
+		})
+	})
+
+	Context("Write", func() {
+		It("should write the most specific locale path", func() {
+			writeFile("greeting.fr.golden", "bonjour")
+			Expect(storage.Write([]byte("salut"))).To(Succeed())
+			Expect(afero.ReadFile(fs, filepath.Join("testdata", "greeting.fr-CA.golden"))).To(Equal([]byte("salut")))
+			Expect(afero.ReadFile(fs, filepath.Join("testdata", "greeting.fr.golden"))).To(Equal([]byte("bonjour")))
+		})
+	})
+})
+
 var _ = Describe("SuiteStorage", func() {
 	var (
 		storage *SuiteStorage
@@ -219,6 +284,20 @@ zzz'''
 			})
 		})
 
+		When("input is not valid UTF-8", func() {
+			BeforeEach(func() {
+				input = []byte{0xff, 0xfe}
+			})
+
+			AfterEach(func() {
+				input = []byte("bar")
+			})
+
+			It("should return error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
 		When("file not exist", func() {
 			It("should write the file", func() {
 				Expect(readFile()).To(Equal(`# Generated by goldga. DO NOT EDIT.
@@ -234,3 +313,169 @@ bar'''
 		})
 	})
 })
+
+var _ = Describe("ParseSuite", func() {
+	It("should return snapshots", func() {
+		snapshots, err := ParseSuite([]byte(`# Generated by goldga. DO NOT EDIT.
+[snapshots]
+"A" = '''
+abc'''
+"B" = ''''''
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(Equal(map[string]string{
+			"A": "abc",
+			"B": "",
+		}))
+	})
+
+	It("should return empty map when snapshots table is missing", func() {
+		snapshots, err := ParseSuite([]byte(""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(BeEmpty())
+	})
+
+	It("should return error when content is malformed", func() {
+		_, err := ParseSuite([]byte(`[snapshots`))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+type flakyFs struct {
+	afero.Fs
+
+	failures int
+}
+
+func (f *flakyFs) Open(name string) (afero.File, error) {
+	if f.failures > 0 {
+		f.failures--
+
+		return f.Fs.Open(name + ".truncated")
+	}
+
+	return f.Fs.Open(name)
+}
+
+var _ = Describe("SuiteStorage with DecodeRetries", func() {
+	var (
+		storage *SuiteStorage
+		fs      *flakyFs
+		err     error
+	)
+
+	BeforeEach(func() {
+		fs = &flakyFs{Fs: afero.NewMemMapFs()}
+		storage = &SuiteStorage{
+			Path:          "suite.golden",
+			Name:          "b",
+			Fs:            fs,
+			DecodeRetries: 2,
+		}
+		Expect(afero.WriteFile(fs, "suite.golden", []byte("[snapshots]\n\"a\" = '''\nfoo'''\n"), os.ModePerm)).To(Succeed())
+		Expect(afero.WriteFile(fs, "suite.golden.truncated", []byte("[snapshots]\n\"a\" = '''\nfo"), os.ModePerm)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		err = storage.Write([]byte("bar"))
+	})
+
+	When("read recovers within retries", func() {
+		BeforeEach(func() {
+			fs.failures = 2
+		})
+
+		It("should write the file", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ParseSuite(mustReadFile(fs, "suite.golden"))).To(Equal(map[string]string{
+				"a": "foo",
+				"b": "bar",
+			}))
+		})
+	})
+
+	When("file is persistently invalid", func() {
+		BeforeEach(func() {
+			fs.failures = 3
+		})
+
+		It("should return error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should not change the file", func() {
+			Expect(ParseSuite(mustReadFile(fs, "suite.golden"))).To(Equal(map[string]string{"a": "foo"}))
+		})
+	})
+})
+
+func mustReadFile(fs afero.Fs, path string) []byte {
+	data, err := afero.ReadFile(fs, path)
+	Expect(err).NotTo(HaveOccurred())
+
+	return data
+}
+
+var _ = Describe("StrictNoWrite", func() {
+	var (
+		fs      afero.Fs
+		storage *SingleStorage
+	)
+
+	BeforeEach(func() {
+		StrictNoWrite = true
+		fs = afero.NewMemMapFs()
+		storage = &SingleStorage{Path: "foo.golden", Fs: fs}
+	})
+
+	AfterEach(func() {
+		StrictNoWrite = false
+	})
+
+	match := func(options ...Option) (bool, error) {
+		options = append([]Option{WithStorage(storage), WithSerializer(&StringSerializer{})}, options...)
+
+		return newMatcher("foo.golden", "foo", options...).Match("foo")
+	}
+
+	It("should fail outside update mode", func() {
+		_, err := match(WithUpdatePolicy(UpdatePolicyCreateOnly))
+		Expect(err).To(MatchError(ErrStrictNoWrite))
+		Expect(afero.Exists(fs, "foo.golden")).To(BeFalse())
+	})
+
+	It("should write with the update policy of the matcher", func() {
+		Expect(match(WithUpdatePolicy(UpdatePolicyAlways))).To(BeTrue())
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	When("UPDATE_GOLDEN is set", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("UPDATE_GOLDEN", "1")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("UPDATE_GOLDEN")).To(Succeed())
+		})
+
+		It("should write", func() {
+			Expect(match()).To(BeTrue())
+			Expect(storage.Read()).To(Equal([]byte("foo")))
+		})
+	})
+
+	It("should panic on direct writes outside update mode", func() {
+		Expect(func() { _ = storage.Write([]byte("foo")) }).To(PanicWith(ErrStrictNoWrite))
+		Expect(func() {
+			_ = (&SuiteStorage{Path: "suite.golden", Name: "foo", Fs: fs}).Write([]byte("foo"))
+		}).To(Panic())
+		Expect(afero.Exists(fs, "foo.golden")).To(BeFalse())
+	})
+
+	It("should allow direct writes in update mode", func() {
+		Expect(os.Setenv("UPDATE_GOLDEN", "1")).To(Succeed())
+		defer func() { Expect(os.Unsetenv("UPDATE_GOLDEN")).To(Succeed()) }()
+
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+	})
+})
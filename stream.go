@@ -0,0 +1,452 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// streamDiffWindow is the number of bytes around the first difference shown on mismatch when
+// streaming.
+const streamDiffWindow = 4096
+
+var errStreamUnsupported = errors.New("streaming requires a StreamStorage and ExactComparer without normalization, " +
+	"filters, approver or pending updates")
+
+// StreamStorage is a Storage which can read and write snapshots without loading them into memory.
+type StreamStorage interface {
+	Storage
+
+	Open() (io.ReadCloser, error)
+	Create() (io.WriteCloser, error)
+}
+
+var (
+	_ StreamStorage = (*SingleStorage)(nil)
+	_ StreamStorage = (*DirStorage)(nil)
+)
+
+// WithStreaming compares the serialized content with the golden file chunk by chunk, so very
+// large snapshots are never fully loaded into memory. On mismatch, only the region around the
+// first difference is shown and reported. Linters load the content into memory when a golden
+// file is written.
+func WithStreaming() Option {
+	return func(matcher *Matcher) {
+		matcher.Streaming = true
+	}
+}
+
+func (s *SingleStorage) Open() (io.ReadCloser, error) {
+	var err error
+
+	for _, path := range s.localePaths() {
+		var file afero.File
+
+		if file, err = s.Fs.Open(path); err == nil {
+			return file, nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+
+	if errors.Is(err, os.ErrNotExist) {
+		err = afero.ErrFileNotFound
+	}
+
+	return nil, fmt.Errorf("failed to open file: %w", err)
+}
+
+func (s *SingleStorage) Create() (io.WriteCloser, error) {
+	checkStrictWrite()
+
+	path := s.localePaths()[0]
+
+	if err := s.Fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	file, err := s.Fs.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	return file, nil
+}
+
+func (d *DirStorage) Open() (io.ReadCloser, error) {
+	return d.single().Open()
+}
+
+func (d *DirStorage) Create() (io.WriteCloser, error) {
+	if err := d.recordName(); err != nil {
+		return nil, err
+	}
+
+	return d.single().Create()
+}
+
+func (m *Matcher) streamStorage() (StreamStorage, error) {
+	s, ok := m.Storage.(StreamStorage)
+	if !ok || m.NormalizeJSONNumbers || m.NormalizeLineEndings || m.NormalizePathSeparators ||
+		len(m.Scrubbers) > 0 || len(m.Filters) > 0 || m.Approver != nil || m.Pending {
+		return nil, errStreamUnsupported
+	}
+
+	if _, ok := m.comparer().(*ExactComparer); !ok {
+		return nil, errStreamUnsupported
+	}
+
+	return s, nil
+}
+
+func (m *Matcher) matchStream(actual interface{}) (bool, error) {
+	storage, err := m.streamStorage()
+	if err != nil {
+		return false, err
+	}
+
+	if m.DryRun || (!m.UpdateFile && m.UpdatePolicy != UpdatePolicyAlways) {
+		r, err := storage.Open()
+		if err == nil {
+			defer r.Close()
+
+			return m.compareStreamContent(r, actual)
+		}
+
+		if !errors.Is(err, afero.ErrFileNotFound) {
+			return false, fmt.Errorf("failed to get expected content: %w", err)
+		}
+
+		if m.DryRun || m.UpdatePolicy == UpdatePolicyNever {
+			head := &windowWriter{end: streamDiffWindow}
+			written := false
+
+			if !m.DryRun {
+				err = m.writeReceivedWith(func(w io.Writer) error {
+					written = true
+
+					return m.serialize(io.MultiWriter(w, head), actual)
+				})
+			}
+
+			if err == nil && !written {
+				err = m.serialize(head, actual)
+			}
+
+			if err != nil {
+				return false, err
+			}
+
+			recordStatSize(m.Storage, statMismatched, false, head.offset)
+			recordFailure(m.Storage, nil, head.buf.Bytes())
+
+			if m.DryRun {
+				recordDryRunChange(m.Storage, DryRunCreate)
+
+				return false, fmt.Errorf("dry run: %w", errGoldenFileWouldBeCreated)
+			}
+
+			return false, m.missingGoldenFileError()
+		}
+	}
+
+	if err := m.checkStrictNoWrite(); err != nil {
+		return false, err
+	}
+
+	size, err := m.writeStream(storage, actual)
+	if err != nil {
+		return false, err
+	}
+
+	if m.UpdateFile || m.UpdatePolicy == UpdatePolicyAlways {
+		recordStatSize(m.Storage, statUpdated, false, size)
+	} else {
+		recordStatSize(m.Storage, statCreated, false, size)
+	}
+
+	return true, nil
+}
+
+// compareStreamContent compares the golden file with the actual content. On mismatch, the regions
+// around the first difference are kept for the failure message and report, and the received
+// file is written.
+func (m *Matcher) compareStreamContent(r io.Reader, actual interface{}) (bool, error) {
+	offset, err := m.compareStream(r, actual)
+	if err != nil {
+		return false, err
+	}
+
+	m.streamMismatch = offset
+
+	if offset < 0 {
+		recordStatSize(m.Storage, statMatched, true, m.streamSize)
+
+		return true, nil
+	}
+
+	if m.DryRun {
+		recordDryRunChange(m.Storage, DryRunModify)
+	}
+
+	start := m.streamWindowStart()
+	expected := &windowWriter{start: start, end: offset + streamDiffWindow}
+
+	if err := m.readStreamWindow(expected); err != nil {
+		return false, err
+	}
+
+	m.matched = true
+	m.expectedContent = trimPartialLines(expected.buf.Bytes(), start > 0)
+	m.actualContent = trimPartialLines(m.streamReceived, start > 0)
+
+	counter := &windowWriter{}
+	counted := false
+
+	if !m.DryRun {
+		err = m.writeReceivedWith(func(w io.Writer) error {
+			counted = true
+
+			return m.serialize(io.MultiWriter(w, counter), actual)
+		})
+	}
+
+	if err == nil && !counted {
+		err = m.serialize(counter, actual)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	recordStatSize(m.Storage, statMismatched, true, counter.offset)
+	recordFailure(m.Storage, m.expectedContent, m.actualContent)
+
+	return false, nil
+}
+
+// writeStream writes the actual content to the golden file and returns its size. Linters and
+// the line width check need the whole content, so it is buffered in memory if they are used.
+func (m *Matcher) writeStream(storage StreamStorage, actual interface{}) (int64, error) {
+	var buf *bytes.Buffer
+
+	if len(m.Linters) > 0 || (m.MaxLineWidth > 0 && m.OnLongLine != nil) {
+		buf = new(bytes.Buffer)
+
+		if err := m.serialize(buf, actual); err != nil {
+			return 0, err
+		}
+
+		m.checkLineWidth(buf.Bytes())
+
+		if err := m.lint(buf.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+
+	allowed := allowStrictWrites()
+	w, err := storage.Create()
+
+	allowed()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	counter := &windowWriter{}
+
+	if buf != nil {
+		_, err = io.Copy(io.MultiWriter(w, counter), buf)
+	} else {
+		err = m.serialize(io.MultiWriter(w, counter), actual)
+	}
+
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return counter.offset, nil
+}
+
+// compareStream returns the offset of the first difference between r and the serialized actual
+// content, or -1 if they are identical. The received content around the difference is kept in
+// streamReceived for the failure message.
+func (m *Matcher) compareStream(r io.Reader, actual interface{}) (int64, error) {
+	cw := &compareWriter{r: r, mismatch: -1}
+
+	if err := m.serialize(cw, actual); err != nil && !errors.Is(err, errStreamMismatch) {
+		return 0, err
+	}
+
+	if cw.mismatch < 0 {
+		// The golden file must not have any content left.
+		if n, _ := r.Read(make([]byte, 1)); n > 0 {
+			cw.mismatch = cw.offset
+		}
+	}
+
+	m.streamReceived = cw.received
+	m.streamSize = cw.offset
+
+	return cw.mismatch, nil
+}
+
+var errStreamMismatch = errors.New("stream mismatch")
+
+// compareWriter compares written bytes with r. It keeps the last streamDiffWindow bytes before
+// the first difference and stops streamDiffWindow bytes after it.
+type compareWriter struct {
+	r        io.Reader
+	buf      []byte
+	offset   int64
+	mismatch int64
+	received []byte
+	after    int
+}
+
+func (c *compareWriter) Write(p []byte) (int, error) {
+	if c.mismatch >= 0 {
+		return c.keepAfter(p)
+	}
+
+	if cap(c.buf) < len(p) {
+		c.buf = make([]byte, len(p))
+	}
+
+	buf := c.buf[:len(p)]
+	n, _ := io.ReadFull(c.r, buf)
+
+	for i := 0; i < len(p); i++ {
+		if i >= n || buf[i] != p[i] {
+			c.mismatch = c.offset + int64(i)
+			c.keepBefore(p[:i])
+
+			written, err := c.keepAfter(p[i:])
+
+			return i + written, err
+		}
+	}
+
+	c.offset += int64(len(p))
+	c.keepBefore(p)
+
+	return len(p), nil
+}
+
+func (c *compareWriter) keepBefore(p []byte) {
+	c.received = append(c.received, p...)
+
+	if len(c.received) > streamDiffWindow {
+		c.received = c.received[len(c.received)-streamDiffWindow:]
+	}
+}
+
+func (c *compareWriter) keepAfter(p []byte) (int, error) {
+	n := streamDiffWindow - c.after
+	if n > len(p) {
+		n = len(p)
+	}
+
+	c.received = append(c.received, p[:n]...)
+	c.after += n
+
+	if n < len(p) || c.after >= streamDiffWindow {
+		return n, errStreamMismatch
+	}
+
+	return n, nil
+}
+
+// windowWriter keeps only the bytes written between start and end. offset is the total number
+// of bytes written.
+type windowWriter struct {
+	start, end int64
+	offset     int64
+	buf        bytes.Buffer
+}
+
+func (w *windowWriter) Write(p []byte) (int, error) {
+	lo, hi := w.start-w.offset, w.end-w.offset
+
+	if lo < 0 {
+		lo = 0
+	}
+
+	if hi > int64(len(p)) {
+		hi = int64(len(p))
+	}
+
+	if lo < hi {
+		w.buf.Write(p[lo:hi])
+	}
+
+	w.offset += int64(len(p))
+
+	return len(p), nil
+}
+
+func (m *Matcher) streamWindowStart() int64 {
+	if m.streamMismatch < streamDiffWindow {
+		return 0
+	}
+
+	return m.streamMismatch - streamDiffWindow
+}
+
+// getStreamDiff shows the diff of the region around the first difference. The regions were kept
+// by Match, so the actual value is not serialized again.
+func (m *Matcher) getStreamDiff() ([]byte, error) {
+	expected, received := m.expectedContent, m.actualContent
+
+	if !m.matched {
+		start := m.streamWindowStart()
+		w := &windowWriter{start: start, end: m.streamMismatch + streamDiffWindow}
+
+		if err := m.readStreamWindow(w); err != nil {
+			return nil, err
+		}
+
+		expected = trimPartialLines(w.buf.Bytes(), start > 0)
+		received = trimPartialLines(m.streamReceived, start > 0)
+	}
+
+	return append([]byte(fmt.Sprintf("First difference at byte %d:\n", m.streamMismatch)),
+		m.Differ.Diff(expected, received)...), nil
+}
+
+func (m *Matcher) readStreamWindow(w *windowWriter) error {
+	r, err := m.Storage.(StreamStorage).Open()
+	if err != nil {
+		return fmt.Errorf("failed to get expected content: %w", err)
+	}
+	defer r.Close()
+
+	if _, err := io.Copy(w, io.LimitReader(r, w.end)); err != nil {
+		return fmt.Errorf("failed to get expected content: %w", err)
+	}
+
+	return nil
+}
+
+// trimPartialLines removes the incomplete first line if the window does not start at the
+// beginning of the content.
+func trimPartialLines(content []byte, partial bool) []byte {
+	if partial {
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			content = content[i+1:]
+		}
+	}
+
+	return content
+}
@@ -0,0 +1,131 @@
+package goldga
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Streaming", func() {
+	var (
+		fs      afero.Fs
+		storage *SingleStorage
+		matcher *Matcher
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		storage = &SingleStorage{Path: "testdata/foo.golden", Fs: fs}
+		matcher = newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+			WithStreaming(),
+		)
+	})
+
+	When("golden file does not exist", func() {
+		It("should write the golden file", func() {
+			Expect(matcher.Match("foo\n")).To(BeTrue())
+			Expect(storage.Read()).To(Equal([]byte("foo\n")))
+		})
+	})
+
+	When("golden file exists", func() {
+		BeforeEach(func() {
+			Expect(storage.Write([]byte(strings.Repeat("line\n", 10000)))).To(Succeed())
+		})
+
+		It("should match identical content", func() {
+			Expect(matcher.Match(strings.Repeat("line\n", 10000))).To(BeTrue())
+		})
+
+		It("should not match longer content", func() {
+			Expect(matcher.Match(strings.Repeat("line\n", 10001))).To(BeFalse())
+			Expect(matcher.streamMismatch).To(BeEquivalentTo(50000))
+		})
+
+		It("should not match shorter content", func() {
+			Expect(matcher.Match(strings.Repeat("line\n", 9999))).To(BeFalse())
+			Expect(matcher.streamMismatch).To(BeEquivalentTo(49995))
+		})
+
+		When("content differs", func() {
+			actual := strings.Repeat("line\n", 5000) + "changed\n" + strings.Repeat("line\n", 4999)
+
+			BeforeEach(func() {
+				matcher.Differ = &UnifiedDiffer{Context: 1, DisableColor: true}
+				Expect(matcher.Match(actual)).To(BeFalse())
+			})
+
+			It("should find the first difference", func() {
+				Expect(matcher.streamMismatch).To(BeEquivalentTo(25000))
+			})
+
+			It("should show only the region around the difference", func() {
+				message := matcher.FailureMessage(actual)
+				Expect(message).To(HavePrefix("Expected to match the golden file\nFirst difference at byte 25000:\n"))
+				Expect(message).To(ContainSubstring("-line\n+changed\n"))
+				Expect(len(message)).To(BeNumerically("<", 3*streamDiffWindow))
+			})
+		})
+	})
+
+	When("linters are set", func() {
+		It("should lint written golden files", func() {
+			WithMaxSize(3)(matcher)
+			_, err := matcher.Match("foo\n")
+			Expect(err).To(MatchError(ErrSnapshotTooLarge))
+			Expect(afero.Exists(fs, storage.Path)).To(BeFalse())
+		})
+	})
+
+	When("content mismatches", func() {
+		BeforeEach(func() {
+			resetStats()
+			resetFailures()
+			EnableReport()
+			receivedFs = fs
+			Expect(storage.Write([]byte("foo\n"))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resetStats()
+			resetFailures()
+			receivedFs = defaultBaseFs
+		})
+
+		It("should record stats and failures", func() {
+			Expect(matcher.Match("bar\n")).To(BeFalse())
+			Expect(Stats().Mismatched).To(Equal(1))
+			Expect(Failures()).To(Equal([]SnapshotFailure{
+				{Name: storage.Path, Path: storage.Path, Expected: "foo\n", Actual: "bar\n"},
+			}))
+		})
+
+		It("should write the received file", func() {
+			matcher.WriteReceived = true
+			Expect(matcher.Match("bar\n")).To(BeFalse())
+			Expect(mustReadFile(fs, storage.Path+receivedExt)).To(Equal([]byte("bar\n")))
+			Expect(matcher.FailureMessage("bar\n")).To(HaveSuffix("Received content written to " + storage.Path + receivedExt))
+		})
+
+		It("should use the failure formatter", func() {
+			matcher.FailureFormatter = FailureFormatterFunc(func(info FailureInfo) string {
+				return info.Name + "\n" + string(info.Diff)
+			})
+			Expect(matcher.Match("bar\n")).To(BeFalse())
+			Expect(matcher.FailureMessage("bar\n")).To(HavePrefix(storage.Path + "\nFirst difference at byte 0:\n"))
+		})
+	})
+
+	When("storage does not support streaming", func() {
+		It("should return error", func() {
+			matcher.Storage = &SuiteStorage{Path: "foo", Name: "foo", Fs: fs}
+			_, err := matcher.Match("foo")
+			Expect(err).To(MatchError(errStreamUnsupported))
+		})
+	})
+})
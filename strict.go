@@ -0,0 +1,66 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const strictEnv = "GOLDGA_STRICT"
+
+// ErrUnknownSnapshots is returned by VerifySnapshots in strict mode when golden files contain
+// snapshots which were not used during the run.
+var ErrUnknownSnapshots = errors.New("golden files contain unknown snapshots")
+
+// IsStrict reports whether strict mode is enabled with GOLDGA_STRICT=1.
+func IsStrict() bool {
+	strict, _ := strconv.ParseBool(os.Getenv(strictEnv))
+
+	return strict
+}
+
+// VerifySnapshots returns ErrUnknownSnapshots listing the snapshots returned by
+// OrphanedSnapshots if strict mode is enabled, so renamed tests cannot silently stop validating
+// their snapshots. Call it once all tests ran, e.g. in Ginkgo's AfterSuite or in TestMain. Like
+// PruneSnapshots, it returns ErrPartialRun when only some tests ran.
+func VerifySnapshots() error {
+	if !IsStrict() {
+		return nil
+	}
+
+	if isPartialRun() {
+		return ErrPartialRun
+	}
+
+	orphans, err := OrphanedSnapshots()
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(orphans))
+
+	for path := range orphans {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	var b strings.Builder
+
+	for _, path := range paths {
+		fmt.Fprintf(&b, "\n%s:", path)
+
+		for _, name := range orphans[path] {
+			fmt.Fprintf(&b, "\n  %s", name)
+		}
+	}
+
+	return fmt.Errorf("%w:%s", ErrUnknownSnapshots, b.String())
+}
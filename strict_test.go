@@ -0,0 +1,59 @@
+package goldga
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("VerifySnapshots", func() {
+	var suite *SuiteStorage
+
+	BeforeEach(func() {
+		suite = &SuiteStorage{Path: "strict.golden", Fs: afero.NewMemMapFs()}
+
+		for _, name := range []string{"a", "b", "c"} {
+			Expect(suite.Named(name).Write([]byte(name))).To(Succeed())
+		}
+
+		resetSnapshotUsages()
+		isPartialRun = func() bool { return false }
+
+		recordMatchUsage(suite.Named("a"))
+	})
+
+	AfterEach(func() {
+		resetSnapshotUsages()
+		isPartialRun = detectPartialRun
+		Expect(os.Unsetenv(strictEnv)).To(Succeed())
+	})
+
+	It("should do nothing when strict mode is disabled", func() {
+		Expect(VerifySnapshots()).To(Succeed())
+	})
+
+	When("strict mode is enabled", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv(strictEnv, "1")).To(Succeed())
+		})
+
+		It("should list snapshots which were not used", func() {
+			err := VerifySnapshots()
+			Expect(err).To(MatchError(ErrUnknownSnapshots))
+			Expect(err.Error()).To(Equal("golden files contain unknown snapshots:\nstrict.golden:\n  b\n  c"))
+		})
+
+		It("should succeed when all snapshots were used", func() {
+			recordMatchUsage(suite.Named("b"))
+			recordMatchUsage(suite.Named("c"))
+			Expect(VerifySnapshots()).To(Succeed())
+		})
+
+		It("should refuse to verify when not all tests ran", func() {
+			isPartialRun = func() bool { return true }
+			Expect(VerifySnapshots()).To(MatchError(ErrPartialRun))
+		})
+	})
+})
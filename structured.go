@@ -0,0 +1,87 @@
+package goldga
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DiffFunc returns a human-readable difference between two values, or an empty string if they
+// are equal. cmp.Diff with options can be wrapped as a DiffFunc.
+type DiffFunc func(expected, actual interface{}) string
+
+var (
+	_ Comparer = (*StructuredComparer)(nil)
+	_ Differ   = (*StructuredComparer)(nil)
+)
+
+// StructuredComparer decodes the golden file and the actual content back into Go values and
+// compares them with DiffFunc, instead of comparing bytes. It is also a Differ which renders the
+// output of DiffFunc.
+//
+//	goldga.WithStructuredComparison(&goldga.JSONSerializer{}, func() interface{} { return new(User) },
+//		func(x, y interface{}) string { return cmp.Diff(x, y, cmpopts.IgnoreFields(User{}, "ID")) })
+type StructuredComparer struct {
+	Deserializer Deserializer
+	// New returns a pointer to a new value of the type to decode into.
+	New      func() interface{}
+	DiffFunc DiffFunc
+}
+
+// WithStructuredComparison serializes values with serializer, which must also be a Deserializer,
+// and compares them with a StructuredComparer.
+func WithStructuredComparison(serializer Serializer, newValue func() interface{}, diff DiffFunc) Option {
+	deserializer, ok := serializer.(Deserializer)
+	if !ok {
+		panic(fmt.Sprintf("goldga: serializer %T is not a Deserializer", serializer))
+	}
+
+	comparer := &StructuredComparer{Deserializer: deserializer, New: newValue, DiffFunc: diff}
+
+	return func(matcher *Matcher) {
+		matcher.Serializer = serializer
+		matcher.Comparer = comparer
+		matcher.Differ = comparer
+	}
+}
+
+func (s *StructuredComparer) Compare(expected, actual []byte) (bool, error) {
+	diff, err := s.diff(expected, actual)
+	if err != nil {
+		return false, err
+	}
+
+	return diff == "", nil
+}
+
+func (s *StructuredComparer) Diff(snapshot, received []byte) []byte {
+	diff, err := s.diff(snapshot, received)
+	if err != nil {
+		return []byte(err.Error())
+	}
+
+	return []byte(diff)
+}
+
+func (s *StructuredComparer) diff(expected, actual []byte) (string, error) {
+	x, err := s.decode(expected)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode golden file: %w", err)
+	}
+
+	y, err := s.decode(actual)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode actual content: %w", err)
+	}
+
+	return s.DiffFunc(x, y), nil
+}
+
+func (s *StructuredComparer) decode(data []byte) (interface{}, error) {
+	value := s.New()
+
+	if err := s.Deserializer.Deserialize(bytes.NewReader(data), value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,77 @@
+package goldga
+
+import (
+	"fmt"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type structuredUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// diffIgnoringID is a DiffFunc like cmp.Diff with an option ignoring the ID field.
+func diffIgnoringID(expected, actual interface{}) string {
+	x := *expected.(*structuredUser)
+	y := *actual.(*structuredUser)
+	x.ID, y.ID = 0, 0
+
+	if reflect.DeepEqual(x, y) {
+		return ""
+	}
+
+	return fmt.Sprintf("-%+v\n+%+v\n", x, y)
+}
+
+var _ = Describe("StructuredComparer", func() {
+	var comparer *StructuredComparer
+
+	BeforeEach(func() {
+		comparer = &StructuredComparer{
+			Deserializer: &JSONSerializer{},
+			New:          func() interface{} { return new(structuredUser) },
+			DiffFunc:     diffIgnoringID,
+		}
+	})
+
+	It("should match if the diff is empty", func() {
+		Expect(comparer.Compare([]byte(`{"id":1,"name":"foo"}`), []byte(`{"name":"foo","id":2}`))).To(BeTrue())
+	})
+
+	It("should not match if the diff is not empty", func() {
+		Expect(comparer.Compare([]byte(`{"id":1,"name":"foo"}`), []byte(`{"id":1,"name":"bar"}`))).To(BeFalse())
+		Expect(string(comparer.Diff([]byte(`{"id":1,"name":"foo"}`), []byte(`{"id":1,"name":"bar"}`)))).To(Equal(
+			"-{ID:0 Name:foo}\n+{ID:0 Name:bar}\n"))
+	})
+
+	It("should return error if the golden file cannot be decoded", func() {
+		_, err := comparer.Compare([]byte(`{`), []byte(`{}`))
+		Expect(err).To(MatchError(HavePrefix("failed to decode golden file")))
+		Expect(string(comparer.Diff([]byte(`{`), []byte(`{}`)))).To(HavePrefix("failed to decode golden file"))
+	})
+})
+
+var _ = Describe("WithStructuredComparison", func() {
+	It("should compare decoded values", func() {
+		storage := &SingleStorage{Path: "foo.golden", Fs: afero.NewMemMapFs()}
+		Expect(storage.Write([]byte(`{"id":1,"name":"foo"}`))).To(Succeed())
+
+		m := newMatcher("foo", "foo",
+			WithStorage(storage),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+			WithStructuredComparison(&JSONSerializer{}, func() interface{} { return new(structuredUser) }, diffIgnoringID),
+		)
+
+		Expect(m.Match(structuredUser{ID: 2, Name: "foo"})).To(BeTrue())
+		Expect(m.Match(structuredUser{ID: 1, Name: "bar"})).To(BeFalse())
+		Expect(m.FailureMessage(structuredUser{ID: 1, Name: "bar"})).To(ContainSubstring("+{ID:0 Name:bar}"))
+	})
+
+	It("should panic if the serializer is not a Deserializer", func() {
+		Expect(func() { WithStructuredComparison(&DumpSerializer{}, nil, nil) }).To(Panic())
+	})
+})
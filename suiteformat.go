@@ -0,0 +1,169 @@
+package goldga
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const suiteHeader = "# Generated by goldga. DO NOT EDIT."
+
+// SuiteFormat is the encoding of a suite file. The format of existing files is detected on read.
+type SuiteFormat string
+
+const (
+	// SuiteFormatTOML stores snapshots as multi-line strings in TOML.
+	SuiteFormatTOML SuiteFormat = "toml"
+	// SuiteFormatJSON stores snapshots as escaped JSON strings.
+	SuiteFormatJSON SuiteFormat = "json"
+	// SuiteFormatYAML stores snapshots as YAML block scalars.
+	SuiteFormatYAML SuiteFormat = "yaml"
+)
+
+// WithSuiteFormat sets the encoding of new suite files, and converts existing ones when they are
+// written.
+func WithSuiteFormat(format SuiteFormat) Option {
+	return func(matcher *Matcher) {
+		matcher.Storage = mapStorage(matcher.Storage, func(inner Storage) Storage {
+			if s, ok := inner.(*SuiteStorage); ok {
+				named := *s
+				named.Format = format
+
+				return &named
+			}
+
+			return inner
+		})
+	}
+}
+
+// detectSuiteFormat guesses the format from the first line which is not blank or a comment.
+func detectSuiteFormat(content []byte) SuiteFormat {
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '{' {
+			return SuiteFormatJSON
+		}
+
+		if line[0] == '[' {
+			return SuiteFormatTOML
+		}
+
+		colon := bytes.IndexByte(line, ':')
+		equal := bytes.IndexByte(line, '=')
+
+		if colon >= 0 && (equal < 0 || colon < equal) {
+			return SuiteFormatYAML
+		}
+
+		return SuiteFormatTOML
+	}
+
+	return SuiteFormatTOML
+}
+
+func writeSuiteJSON(w *bufio.Writer, data *suiteData) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("json encode error: %w", err)
+	}
+
+	return nil
+}
+
+func writeSuiteYAML(w *bufio.Writer, data *suiteData) error {
+	if _, err := fmt.Fprintln(w, suiteHeader); err != nil {
+		return fmt.Errorf("header write error: %w", err)
+	}
+
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("yaml encode error: %w", err)
+	}
+
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("snapshot write error: %w", err)
+	}
+
+	return nil
+}
+
+func (m snapshotMeta) MarshalJSON() ([]byte, error) {
+	type jsonSnapshotMeta struct {
+		Hash     string     `json:"hash"`
+		Updated  time.Time  `json:"updated"`
+		Verified *time.Time `json:"verified,omitempty"`
+	}
+
+	out := jsonSnapshotMeta{Hash: m.Hash, Updated: m.Updated}
+
+	if !m.Verified.IsZero() {
+		out.Verified = &m.Verified
+	}
+
+	return json.Marshal(out)
+}
+
+// tomlMultilineString returns value as a multi-line literal string, or as a multi-line basic
+// string with escapes if value cannot be represented literally, e.g. when it contains three
+// single quotes.
+func tomlMultilineString(value string) string {
+	if isTOMLLiteral(value) {
+		return "'''\n" + value + "'''"
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(`"""` + "\n")
+
+	for _, r := range value {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '"':
+			sb.WriteString(`\"`)
+		case r == '\n', r == '\t':
+			sb.WriteRune(r)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04X`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	sb.WriteString(`"""`)
+
+	return sb.String()
+}
+
+func isTOMLLiteral(value string) bool {
+	if strings.Contains(value, "'''") || strings.HasSuffix(value, "'") {
+		return false
+	}
+
+	for i, r := range value {
+		switch {
+		case r == '\n', r == '\t':
+		case r == '\r' && strings.HasPrefix(value[i+1:], "\n"):
+		case r < 0x20 || r == 0x7f:
+			return false
+		}
+	}
+
+	return true
+}
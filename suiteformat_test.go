@@ -0,0 +1,123 @@
+package goldga
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("detectSuiteFormat", func() {
+	DescribeTable("cases", func(content string, expected SuiteFormat) {
+		Expect(detectSuiteFormat([]byte(content))).To(Equal(expected))
+	},
+		Entry("empty", "", SuiteFormatTOML),
+		Entry("toml", "# Generated by goldga. DO NOT EDIT.\n[snapshots]\n", SuiteFormatTOML),
+		Entry("toml key", `"foo" = "a:b"`, SuiteFormatTOML),
+		Entry("json", "\n{\n  \"snapshots\": {}\n}\n", SuiteFormatJSON),
+		Entry("yaml", "# Generated by goldga. DO NOT EDIT.\nsnapshots:\n  foo: bar\n", SuiteFormatYAML),
+	)
+})
+
+var _ = Describe("tomlMultilineString", func() {
+	DescribeTable("round trip", func(value, expected string) {
+		encoded := tomlMultilineString(value)
+		Expect(encoded).To(Equal(expected))
+
+		var out struct{ V string }
+		_, err := toml.Decode("V = "+encoded, &out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.V).To(Equal(value))
+	},
+		Entry("plain", "foo\n", "'''\nfoo\n'''"),
+		Entry("backslash", `a\b`, "'''\na\\b'''"),
+		Entry("triple quotes", "a'''b\n", "\"\"\"\na'''b\n\"\"\""),
+		Entry("trailing quote", "it's'", "\"\"\"\nit's'\"\"\""),
+		Entry("control characters", "a\x00\"\\\rb", "\"\"\"\na\\u0000\\\"\\\\\\rb\"\"\""),
+		Entry("crlf", "a\r\nb\r\n", "'''\na\r\nb\r\n'''"),
+	)
+})
+
+var _ = Describe("SuiteStorage formats", func() {
+	var (
+		fs    afero.Fs
+		suite *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		suite = &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}
+		now = func() time.Time { return time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC) }
+	})
+
+	AfterEach(func() {
+		now = time.Now
+	})
+
+	It("should round-trip content with triple quotes in TOML", func() {
+		Expect(suite.Write([]byte("a '''quoted''' value\n"))).To(Succeed())
+		Expect(suite.Read()).To(Equal([]byte("a '''quoted''' value\n")))
+	})
+
+	It("should write JSON", func() {
+		suite.Format = SuiteFormatJSON
+		suite.Metadata = true
+		Expect(suite.Write([]byte("a\n\"b\"\n"))).To(Succeed())
+		Expect(string(mustReadFile(fs, "foo.golden"))).To(MatchJSON(`{
+			"snapshots": {"foo": "a\n\"b\"\n"},
+			"meta": {
+				"version": 1,
+				"go_version": "` + runtime.Version() + `",
+				"snapshots": {"foo": {"hash": "` + hashSnapshot("a\n\"b\"\n") + `", "updated": "2021-09-01T00:00:00Z"}}
+			}
+		}`))
+		Expect(suite.Read()).To(Equal([]byte("a\n\"b\"\n")))
+	})
+
+	It("should write YAML with block scalars", func() {
+		suite.Format = SuiteFormatYAML
+		Expect(suite.Write([]byte("a\n'''b'''\n"))).To(Succeed())
+		Expect(string(mustReadFile(fs, "foo.golden"))).To(Equal(`# Generated by goldga. DO NOT EDIT.
+snapshots:
+  foo: |
+    a
+    '''b'''
+`))
+		Expect(suite.Read()).To(Equal([]byte("a\n'''b'''\n")))
+	})
+
+	It("should keep the format of existing files", func() {
+		suite.Format = SuiteFormatJSON
+		Expect(suite.Write([]byte("a"))).To(Succeed())
+
+		other := &SuiteStorage{Path: "foo.golden", Name: "bar", Fs: fs}
+		Expect(other.Write([]byte("b"))).To(Succeed())
+		Expect(string(mustReadFile(fs, "foo.golden"))).To(MatchJSON(`{"snapshots": {"foo": "a", "bar": "b"}}`))
+	})
+
+	It("should convert existing files", func() {
+		Expect(suite.Write([]byte("a"))).To(Succeed())
+
+		suite.Format = SuiteFormatYAML
+		Expect(suite.Named("bar").Write([]byte("b"))).To(Succeed())
+		Expect(detectSuiteFormat(mustReadFile(fs, "foo.golden"))).To(Equal(SuiteFormatYAML))
+		Expect(suite.Read()).To(Equal([]byte("a")))
+	})
+
+	It("should return decode errors with the format", func() {
+		Expect(afero.WriteFile(fs, "foo.golden", []byte("{"), 0o644)).To(Succeed())
+		_, err := suite.Read()
+		Expect(err).To(MatchError(HavePrefix("json decode error: ")))
+	})
+})
+
+var _ = Describe("WithSuiteFormat", func() {
+	It("should set the format", func() {
+		m := newMatcher("testdata/foo.golden", "foo", WithSuiteFormat(SuiteFormatJSON))
+		Expect(m.Storage.(*SuiteStorage).Format).To(Equal(SuiteFormatJSON))
+	})
+})
@@ -0,0 +1,113 @@
+package goldga
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Tester matches golden files in plain Go tests without Gomega.
+type Tester struct {
+	t       testing.TB
+	file    string
+	path    string
+	name    string
+	options []Option
+}
+
+// New returns a Tester for the current test. Snapshots are named after t.Name() and stored in
+// the golden file of the test file calling New.
+func New(t testing.TB, options ...Option) *Tester {
+	t.Helper()
+
+	return newTester(t, options)
+}
+
+// Table returns a Tester for table-driven tests. Use Case to get a Tester for each case.
+//
+//	table := goldga.Table(t)
+//	for _, tc := range cases {
+//		table.Case(tc.name).Assert(parse(tc.input))
+//	}
+func Table(t testing.TB, options ...Option) *Tester {
+	t.Helper()
+
+	return newTester(t, options)
+}
+
+// NewAt returns a Tester storing snapshots in the golden file at path, named name or t.Name() if
+// name is empty. It is meant for helpers wrapping Tester, whose caller is not the test file.
+func NewAt(t testing.TB, path, name string, options ...Option) *Tester {
+	return &Tester{
+		t:       t,
+		path:    path,
+		name:    name,
+		options: options,
+	}
+}
+
+func newTester(t testing.TB, options []Option) *Tester {
+	t.Helper()
+
+	_, file, _, ok := runtime.Caller(2)
+	if !ok {
+		t.Fatal("goldga: unable to get the test file name")
+	}
+
+	return &Tester{
+		t:       t,
+		file:    file,
+		path:    getGoldenPath(file),
+		options: options,
+	}
+}
+
+// Case returns a Tester whose snapshots are named "<test name>/<name>", so each case of a
+// table-driven test has its own snapshot in the same golden file.
+func (g *Tester) Case(name string) *Tester {
+	c := *g
+	c.name = g.snapshotName() + "/" + name
+
+	return &c
+}
+
+func (g *Tester) snapshotName() string {
+	if g.name != "" {
+		return g.name
+	}
+
+	return g.t.Name()
+}
+
+// Assert fails the test if actual does not match the golden file, and reports whether it matched.
+func (g *Tester) Assert(actual interface{}, options ...Option) bool {
+	g.t.Helper()
+
+	options = append(append([]Option{}, g.options...), options...)
+	name := g.snapshotName()
+	m := newMatcherWithInfo(g.path, NameInfo{
+		File:     g.file,
+		Texts:    strings.Split(name, "/"),
+		FullText: name,
+	}, options...)
+
+	success, err := m.Match(actual)
+	if err != nil {
+		g.t.Fatalf("goldga: %v", err)
+
+		return false
+	}
+
+	if !success {
+		g.t.Error(m.FailureMessage(actual))
+	}
+
+	return success
+}
+
+// AssertString is like Assert but stores the string as is instead of dumping it.
+func (g *Tester) AssertString(actual string, options ...Option) bool {
+	g.t.Helper()
+
+	return g.Assert(actual, append([]Option{WithSerializer(&StringSerializer{})}, options...)...)
+}
@@ -0,0 +1,151 @@
+package goldga
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type fakeT struct {
+	testing.TB
+
+	name   string
+	errors []string
+	fatal  bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Name() string {
+	return f.name
+}
+
+func (f *fakeT) Error(args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func (f *fakeT) Fatal(args ...interface{}) {
+	f.Error(args...)
+	f.fatal = true
+}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.Fatal(fmt.Sprintf(format, args...))
+}
+
+var _ = Describe("Tester", func() {
+	var (
+		t       *fakeT
+		storage *SuiteStorage
+		tester  *Tester
+	)
+
+	BeforeEach(func() {
+		t = &fakeT{name: "TestFoo"}
+		tester = New(t)
+		storage = &SuiteStorage{
+			Path: tester.path,
+			Name: "TestFoo",
+			Fs:   afero.NewMemMapFs(),
+		}
+		tester.options = []Option{WithStorage(storage)}
+	})
+
+	It("should use the golden file of the caller", func() {
+		Expect(tester.path).To(Equal(filepath.Join("testdata", "tester.golden")))
+	})
+
+	It("should write the golden file when it does not exist", func() {
+		tester.Assert("foo")
+		Expect(t.errors).To(BeEmpty())
+		Expect(storage.Read()).To(Equal([]byte(`(string) (len=3) "foo"` + "\n")))
+	})
+
+	It("should pass when matched", func() {
+		Expect(storage.Write([]byte(`(string) (len=3) "foo"` + "\n"))).To(Succeed())
+		tester.Assert("foo")
+		Expect(t.errors).To(BeEmpty())
+	})
+
+	It("should fail when not matched", func() {
+		Expect(storage.Write([]byte("bar"))).To(Succeed())
+		Expect(tester.Assert("foo")).To(BeFalse())
+		Expect(t.errors).To(HaveLen(1))
+		Expect(t.errors[0]).To(HavePrefix("Expected to match the golden file"))
+		Expect(t.fatal).To(BeFalse())
+	})
+
+	It("should store strings as is", func() {
+		tester.AssertString("foo")
+		Expect(t.errors).To(BeEmpty())
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+})
+
+var _ = Describe("Table", func() {
+	var (
+		t     *fakeT
+		fs    afero.Fs
+		table *Tester
+	)
+
+	BeforeEach(func() {
+		t = &fakeT{name: "TestParse"}
+		fs = afero.NewMemMapFs()
+		table = Table(t, func(m *Matcher) {
+			m.Storage.(*SuiteStorage).Fs = fs
+		})
+	})
+
+	It("should use the golden file of the caller", func() {
+		Expect(table.path).To(Equal(filepath.Join("testdata", "tester.golden")))
+	})
+
+	It("should name snapshots after the cases", func() {
+		for _, name := range []string{"empty", "simple"} {
+			table.Case(name).AssertString(name)
+		}
+
+		Expect(t.errors).To(BeEmpty())
+		Expect(ParseSuite(mustReadFile(fs, table.path))).To(Equal(map[string]string{
+			"TestParse/empty":  "empty",
+			"TestParse/simple": "simple",
+		}))
+	})
+
+	It("should nest cases", func() {
+		table.Case("a").Case("b").AssertString("foo")
+		Expect(ParseSuite(mustReadFile(fs, table.path))).To(HaveKey("TestParse/a/b"))
+	})
+
+	It("should fail when a case does not match", func() {
+		table.Case("a").AssertString("foo")
+		table.Case("a").AssertString("bar")
+		Expect(t.errors).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("NewAt", func() {
+	It("should store snapshots at the given path and name", func() {
+		t := &fakeT{name: "TestFoo"}
+		fs := afero.NewMemMapFs()
+		tester := NewAt(t, "testdata/other.golden", "bar", func(m *Matcher) {
+			m.Storage.(*SuiteStorage).Fs = fs
+		})
+
+		Expect(tester.AssertString("foo")).To(BeTrue())
+		Expect(t.errors).To(BeEmpty())
+		Expect(ParseSuite(mustReadFile(fs, "testdata/other.golden"))).To(Equal(map[string]string{
+			"bar": "foo",
+		}))
+	})
+
+	It("should default to the test name", func() {
+		t := &fakeT{name: "TestFoo"}
+		Expect(NewAt(t, "testdata/other.golden", "").snapshotName()).To(Equal("TestFoo"))
+	})
+})
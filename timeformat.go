@@ -0,0 +1,101 @@
+package goldga
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	rfc3339Pattern = `\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(?:\.\d+)?(?:Z|[+-]\d\d:\d\d)`
+	dumpTimeLayout = "2006-01-02 15:04:05.999999999 -0700"
+)
+
+// timeSyntax finds the time.Time values written by an encoder, so SerializeOptions.TimeFormat
+// can be applied to the encoded output without changing the type of the input. The pattern
+// captures the text before the time and the time itself.
+type timeSyntax struct {
+	pattern *regexp.Regexp
+	parse   func(groups [][]byte) (time.Time, error)
+	quote   func(value string) string
+}
+
+// nolint: gochecknoglobals
+var (
+	// spew prints times with their String method, after the type or the pointer address.
+	dumpTimeSyntax = &timeSyntax{
+		pattern: regexp.MustCompile(`(time\.Time\)(?: |\(0x[0-9a-f]+\)\())` +
+			`(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d(?:\.\d+)? [+-]\d{4}) ([^\s,)]+)(?: m=[+-]\d+\.\d+)?`),
+		parse: parseDumpTime,
+		quote: func(value string) string { return value },
+	}
+
+	// JSON cannot tell times from strings, so strings in RFC 3339 format are formatted as well.
+	jsonTimeSyntax = &timeSyntax{
+		pattern: regexp.MustCompile(`(^|[\s:,\[])"(` + rfc3339Pattern + `)"`),
+		parse:   parseRFC3339Time,
+		quote:   quoteJSONString,
+	}
+
+	// Strings which look like times are quoted in YAML, so plain timestamps are always times.
+	yamlTimeSyntax = &timeSyntax{
+		pattern: regexp.MustCompile(`(?m)(^|: |- )(` + rfc3339Pattern + `)$`),
+		parse:   parseRFC3339Time,
+		quote:   quoteYAMLString,
+	}
+
+	tomlTimeSyntax = &timeSyntax{
+		pattern: regexp.MustCompile(`(= |\[|, )(` + rfc3339Pattern + `)`),
+		parse:   parseRFC3339Time,
+		quote:   strconv.Quote,
+	}
+)
+
+// format rewrites the times in content with layout. Times which fail to parse are kept.
+func (s *timeSyntax) format(content []byte, layout string) []byte {
+	if layout == "" {
+		return content
+	}
+
+	return s.pattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		groups := s.pattern.FindSubmatch(match)
+
+		t, err := s.parse(groups)
+		if err != nil {
+			return match
+		}
+
+		return append(append([]byte{}, groups[1]...), s.quote(t.Format(layout))...)
+	})
+}
+
+func parseDumpTime(groups [][]byte) (time.Time, error) {
+	t, err := time.Parse(dumpTimeLayout, string(groups[2]))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	_, offset := t.Zone()
+
+	return t.In(time.FixedZone(string(groups[3]), offset)), nil
+}
+
+func parseRFC3339Time(groups [][]byte) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, string(groups[2]))
+}
+
+func quoteJSONString(value string) string {
+	data, _ := json.Marshal(value)
+
+	return string(data)
+}
+
+func quoteYAMLString(value string) string {
+	data, _ := yaml.Marshal(value)
+
+	return strings.TrimSuffix(string(data), "\n")
+}
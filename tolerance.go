@@ -0,0 +1,107 @@
+package goldga
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// numberPattern matches numbers. A "-" is only a sign if it does not follow a word character, so
+// "a-1" contains the number 1.
+// nolint: gochecknoglobals
+var numberPattern = regexp.MustCompile(`(?:\B-)?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?`)
+
+// FloatTolerance is the allowed difference between two numbers. Numbers are equal if they
+// differ by at most Absolute, or by at most Relative times the larger magnitude.
+type FloatTolerance struct {
+	Absolute float64
+	Relative float64
+}
+
+// Equal reports whether a and b are equal within the tolerance.
+func (t FloatTolerance) Equal(a, b float64) bool {
+	if a == b {
+		return true
+	}
+
+	d := math.Abs(a - b)
+
+	return d <= t.Absolute || d <= t.Relative*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// WithFloatTolerance compares numbers within the given absolute or relative tolerance. With
+// WithJSONComparison, in any order, JSON is still compared structurally. Otherwise content is
+// compared by NumericComparer.
+func WithFloatTolerance(absolute, relative float64) Option {
+	return func(matcher *Matcher) {
+		tolerance := FloatTolerance{Absolute: absolute, Relative: relative}
+
+		if comparer, ok := matcher.Comparer.(*JSONComparer); ok {
+			withTolerance := *comparer
+			withTolerance.Tolerance = tolerance
+			matcher.Comparer = &withTolerance
+
+			if matcher.Differ == comparer {
+				matcher.Differ = &withTolerance
+			}
+
+			return
+		}
+
+		matcher.Comparer = &NumericComparer{Tolerance: tolerance}
+	}
+}
+
+var _ Comparer = (*NumericComparer)(nil)
+
+// NumericComparer compares text where numbers may differ within Tolerance. Everything else must
+// be identical.
+type NumericComparer struct {
+	Tolerance FloatTolerance
+}
+
+func (n *NumericComparer) Compare(expected, actual []byte) (bool, error) {
+	expectedIndexes := numberPattern.FindAllIndex(expected, -1)
+	actualIndexes := numberPattern.FindAllIndex(actual, -1)
+
+	if len(expectedIndexes) != len(actualIndexes) {
+		return false, nil
+	}
+
+	var expectedPos, actualPos int
+
+	for i, e := range expectedIndexes {
+		a := actualIndexes[i]
+
+		if !bytes.Equal(expected[expectedPos:e[0]], actual[actualPos:a[0]]) {
+			return false, nil
+		}
+
+		if !n.equalNumbers(expected[e[0]:e[1]], actual[a[0]:a[1]]) {
+			return false, nil
+		}
+
+		expectedPos, actualPos = e[1], a[1]
+	}
+
+	return bytes.Equal(expected[expectedPos:], actual[actualPos:]), nil
+}
+
+func (n *NumericComparer) equalNumbers(expected, actual []byte) bool {
+	if bytes.Equal(expected, actual) {
+		return true
+	}
+
+	e, err := strconv.ParseFloat(string(expected), 64)
+	if err != nil {
+		return false
+	}
+
+	a, err := strconv.ParseFloat(string(actual), 64)
+	if err != nil {
+		return false
+	}
+
+	return n.Tolerance.Equal(e, a)
+}
@@ -0,0 +1,67 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FloatTolerance", func() {
+	DescribeTable("Equal", func(tolerance FloatTolerance, a, b float64, expected bool) {
+		Expect(tolerance.Equal(a, b)).To(Equal(expected))
+	},
+		Entry("identical", FloatTolerance{}, 1.5, 1.5, true),
+		Entry("within absolute", FloatTolerance{Absolute: 1e-9}, 0.1+0.2, 0.3, true),
+		Entry("outside absolute", FloatTolerance{Absolute: 1e-9}, 0.3, 0.31, false),
+		Entry("within relative", FloatTolerance{Relative: 1e-6}, 1e12, 1e12+1, true),
+		Entry("outside relative", FloatTolerance{Relative: 1e-6}, 1.0, 1.1, false),
+	)
+})
+
+var _ = Describe("NumericComparer", func() {
+	comparer := &NumericComparer{Tolerance: FloatTolerance{Absolute: 1e-9}}
+
+	DescribeTable("Compare", func(expected, actual string, result bool) {
+		Expect(comparer.Compare([]byte(expected), []byte(actual))).To(Equal(result))
+	},
+		Entry("identical", "a: 1.5\n", "a: 1.5\n", true),
+		Entry("within tolerance", "a: 0.3\nb: 2\n", "a: 0.30000000000000004\nb: 2\n", true),
+		Entry("outside tolerance", "a: 0.3\n", "a: 0.4\n", false),
+		Entry("text changed", "a: 0.3\n", "b: 0.3\n", false),
+		Entry("trailing text changed", "a: 0.3 x\n", "a: 0.3 y\n", false),
+		Entry("number count changed", "a: 0.3\n", "a: 0.3 1\n", false),
+		Entry("negative number", "a: -0.3\n", "a: -0.30000000000000004\n", true),
+		Entry("hyphen after word", "a-0\n", "a0\n", false),
+	)
+})
+
+var _ = Describe("WithFloatTolerance", func() {
+	It("should use NumericComparer by default", func() {
+		m := newMatcher("foo", "foo", WithFloatTolerance(1e-9, 0))
+		Expect(m.Comparer).To(Equal(&NumericComparer{Tolerance: FloatTolerance{Absolute: 1e-9}}))
+	})
+
+	It("should set the tolerance of JSONComparer", func() {
+		m := newMatcher("foo", "foo", WithJSONComparison("id"), WithFloatTolerance(0, 1e-6))
+		Expect(m.Comparer).To(Equal(&JSONComparer{
+			IgnorePaths: []string{"id"},
+			Tolerance:   FloatTolerance{Relative: 1e-6},
+		}))
+		Expect(m.Differ).To(BeIdenticalTo(m.Comparer))
+	})
+
+	It("should keep the tolerance when used before WithJSONComparison", func() {
+		m := newMatcher("foo", "foo", WithFloatTolerance(0, 1e-6), WithJSONComparison("id"))
+		Expect(m.Comparer).To(Equal(&JSONComparer{
+			IgnorePaths: []string{"id"},
+			Tolerance:   FloatTolerance{Relative: 1e-6},
+		}))
+	})
+
+	It("should not change a shared JSONComparer", func() {
+		comparer := &JSONComparer{}
+		m := newMatcher("foo", "foo", WithComparer(comparer), WithFloatTolerance(1e-9, 0))
+		Expect(m.Comparer.(*JSONComparer).Tolerance.Absolute).To(Equal(1e-9))
+		Expect(comparer.Tolerance).To(Equal(FloatTolerance{}))
+	})
+})
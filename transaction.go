@@ -0,0 +1,137 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// DeletableStorage is a Storage which can delete its snapshot.
+type DeletableStorage interface {
+	Storage
+
+	Delete() error
+}
+
+type transactionStage struct {
+	storage Storage
+	data    []byte
+}
+
+type transactionBackup struct {
+	storage Storage
+	data    []byte
+	existed bool
+}
+
+// Transaction writes data to multiple storages at once. If any write fails, storages written
+// before are restored to their previous content, and snapshots created by the transaction are
+// deleted when the storage implements DeletableStorage.
+type Transaction struct {
+	stages []transactionStage
+}
+
+func NewTransaction() *Transaction {
+	return &Transaction{}
+}
+
+// Stage adds a write to the transaction. Nothing is written until Commit is called.
+func (t *Transaction) Stage(storage Storage, data []byte) {
+	t.stages = append(t.stages, transactionStage{
+		storage: storage,
+		data:    data,
+	})
+}
+
+func (t *Transaction) Commit() error {
+	backups := make([]transactionBackup, 0, len(t.stages))
+
+	for i, stage := range t.stages {
+		backup := transactionBackup{storage: stage.storage}
+		data, err := exactStorage(stage.storage).Read()
+
+		switch {
+		case err == nil:
+			backup.data = data
+			backup.existed = true
+		case !errors.Is(err, afero.ErrFileNotFound):
+			return rollback(backups, fmt.Errorf("failed to read stage %d: %w", i, err))
+		}
+
+		if err := stage.storage.Write(stage.data); err != nil {
+			return rollback(backups, fmt.Errorf("failed to write stage %d: %w", i, err))
+		}
+
+		backups = append(backups, backup)
+	}
+
+	t.stages = nil
+
+	return nil
+}
+
+// exactStorage returns a storage which only reads the snapshot that Write of storage replaces. It
+// does not fall back to less specific locales or variants, and bypasses caches, so backups are
+// not restored with the content of another snapshot.
+func exactStorage(storage Storage) Storage {
+	switch s := storage.(type) {
+	case *CacheStorage:
+		return exactStorage(s.Inner)
+	case StorageWrapper:
+		return s.Wrap(exactStorage(s.Unwrap()))
+	case *SingleStorage:
+		exact := *s
+		exact.exact = true
+
+		return &exact
+	case *DirStorage:
+		exact := *s
+		exact.exact = true
+
+		return &exact
+	case *SuiteStorage:
+		exact := *s
+		exact.exact = true
+
+		return &exact
+	default:
+		return storage
+	}
+}
+
+// rollback restores every backup, even if some of them fail, and returns cause with the errors
+// of the failed restores.
+func rollback(backups []transactionBackup, cause error) error {
+	var failures []string
+
+	for i := len(backups) - 1; i >= 0; i-- {
+		if err := backups[i].restore(); err != nil {
+			failures = append(failures, fmt.Sprintf("stage %d: %v", i, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w (rollback failed: %s)", cause, strings.Join(failures, "; "))
+	}
+
+	return cause
+}
+
+func (b transactionBackup) restore() error {
+	if b.existed {
+		return b.storage.Write(b.data)
+	}
+
+	deletable, ok := b.storage.(DeletableStorage)
+	if !ok {
+		return fmt.Errorf("unable to delete snapshot created in %T", b.storage)
+	}
+
+	if err := deletable.Delete(); err != nil && !errors.Is(err, afero.ErrFileNotFound) {
+		return err
+	}
+
+	return nil
+}
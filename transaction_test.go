@@ -0,0 +1,130 @@
+package goldga
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Transaction", func() {
+	var (
+		mockCtrl *gomock.Controller
+		fs       afero.Fs
+		first    *SingleStorage
+		second   *SuiteStorage
+		tx       *Transaction
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		fs = afero.NewMemMapFs()
+		first = &SingleStorage{
+			Path: filepath.Join("testdata", "first.golden"),
+			Fs:   fs,
+		}
+		second = &SuiteStorage{
+			Path: filepath.Join("testdata", "suite.golden"),
+			Name: "second",
+			Fs:   fs,
+		}
+		tx = NewTransaction()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should write all stages", func() {
+		tx.Stage(first, []byte("a"))
+		tx.Stage(second, []byte("b"))
+		Expect(tx.Commit()).To(Succeed())
+		Expect(first.Read()).To(Equal([]byte("a")))
+		Expect(second.Read()).To(Equal([]byte("b")))
+	})
+
+	When("a stage fails", func() {
+		var failing *MockStorage
+
+		BeforeEach(func() {
+			failing = NewMockStorage(mockCtrl)
+			failing.EXPECT().Read().Return(nil, afero.ErrFileNotFound)
+			failing.EXPECT().Write([]byte("c")).Return(errors.New("error"))
+		})
+
+		It("should restore overwritten snapshots", func() {
+			Expect(first.Write([]byte("old"))).To(Succeed())
+			Expect(second.Write([]byte("old"))).To(Succeed())
+
+			tx.Stage(first, []byte("a"))
+			tx.Stage(second, []byte("b"))
+			tx.Stage(failing, []byte("c"))
+			Expect(tx.Commit()).NotTo(Succeed())
+
+			Expect(first.Read()).To(Equal([]byte("old")))
+			Expect(second.Read()).To(Equal([]byte("old")))
+		})
+
+		It("should restore the other snapshots when a restore fails", func() {
+			Expect(first.Write([]byte("old"))).To(Succeed())
+			Expect(second.Write([]byte("old"))).To(Succeed())
+
+			unrestorable := NewMockStorage(mockCtrl)
+			unrestorable.EXPECT().Read().Return([]byte("old"), nil)
+			unrestorable.EXPECT().Write([]byte("x")).Return(nil)
+			unrestorable.EXPECT().Write([]byte("old")).Return(errors.New("restore error"))
+
+			tx.Stage(first, []byte("a"))
+			tx.Stage(unrestorable, []byte("x"))
+			tx.Stage(second, []byte("b"))
+			tx.Stage(failing, []byte("c"))
+
+			err := tx.Commit()
+			Expect(err).To(MatchError(ContainSubstring("rollback failed: stage 1: restore error")))
+			Expect(first.Read()).To(Equal([]byte("old")))
+			Expect(second.Read()).To(Equal([]byte("old")))
+		})
+
+		It("should delete created snapshots", func() {
+			tx.Stage(first, []byte("a"))
+			tx.Stage(second, []byte("b"))
+			tx.Stage(failing, []byte("c"))
+			Expect(tx.Commit()).NotTo(Succeed())
+
+			_, err := first.Read()
+			Expect(errors.Is(err, afero.ErrFileNotFound)).To(BeTrue())
+			_, err = second.Read()
+			Expect(errors.Is(err, afero.ErrFileNotFound)).To(BeTrue())
+		})
+
+		It("should delete created locales instead of restoring the fallback", func() {
+			Expect(first.Write([]byte("fr"))).To(Succeed())
+			first.Locale = "fr"
+			Expect(first.Write([]byte("fr"))).To(Succeed())
+
+			locale := &SingleStorage{Path: first.Path, Fs: fs, Locale: "fr-CA"}
+			tx.Stage(locale, []byte("a"))
+			tx.Stage(failing, []byte("c"))
+			Expect(tx.Commit()).NotTo(Succeed())
+
+			Expect(afero.Exists(fs, filepath.Join("testdata", "first.fr-CA.golden"))).To(BeFalse())
+			Expect(locale.Read()).To(Equal([]byte("fr")))
+		})
+
+		It("should delete created variants instead of restoring the base snapshot", func() {
+			Expect(second.Write([]byte("old"))).To(Succeed())
+
+			variant := withVariant(second, "linux")
+			tx.Stage(variant, []byte("a"))
+			tx.Stage(failing, []byte("c"))
+			Expect(tx.Commit()).NotTo(Succeed())
+
+			Expect(second.Read()).To(Equal([]byte("old")))
+			Expect(variant.Read()).To(Equal([]byte("old")))
+			Expect(mustReadFile(fs, second.Path)).NotTo(ContainSubstring("variants"))
+		})
+	})
+})
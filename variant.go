@@ -0,0 +1,128 @@
+package goldga
+
+import (
+	"bufio"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// SelectVariant returns the variant used by WithAutoVariant. It defaults to runtime.GOOS and can
+// be replaced, e.g. to include the architecture or feature flags.
+// nolint: gochecknoglobals
+var SelectVariant = func() string {
+	return runtime.GOOS
+}
+
+// Variant stores a separate version of the snapshot under the given key, for output which
+// legitimately differs between environments. Reading falls back to less specific variants
+// ("linux-amd64" -> "linux") and then to the snapshot without variant. In suite files, variants
+// are stored in a [variants."<name>"] table. With DirStorage, the variant is inserted before the
+// file extension.
+func Variant(variant string) Option {
+	return func(matcher *Matcher) {
+		matcher.Storage = withVariant(matcher.Storage, variant)
+	}
+}
+
+// WithAutoVariant selects the variant with SelectVariant.
+func WithAutoVariant() Option {
+	return func(matcher *Matcher) {
+		Variant(SelectVariant())(matcher)
+	}
+}
+
+func withVariant(storage Storage, variant string) Storage {
+	return mapStorage(storage, func(inner Storage) Storage {
+		switch s := inner.(type) {
+		case *SuiteStorage:
+			named := *s
+			named.Variant = variant
+
+			return &named
+		case *DirStorage:
+			named := *s
+			named.Variant = variant
+
+			return &named
+		default:
+			return inner
+		}
+	})
+}
+
+func (s *suiteData) setVariant(name, variant, value string) {
+	if s.Variants[name] == nil {
+		s.Variants[name] = map[string]string{}
+	}
+
+	s.Variants[name][variant] = value
+}
+
+func (s *suiteData) deleteVariant(name, variant string) error {
+	if _, ok := s.Variants[name][variant]; !ok {
+		return afero.ErrFileNotFound
+	}
+
+	delete(s.Variants[name], variant)
+
+	if len(s.Variants[name]) == 0 {
+		delete(s.Variants, name)
+	}
+
+	return nil
+}
+
+// sortSnapshotAndVariantKeys returns the sorted names of snapshots, including those which only
+// have variants.
+func (s *suiteData) sortSnapshotAndVariantKeys() []string {
+	keys := s.sortSnapshotKeys()
+
+	for name := range s.Variants {
+		if _, ok := s.Snapshots[name]; !ok {
+			keys = append(keys, name)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// trimVariant removes the variant from a DirStorage file name ("foo.linux.golden" -> "foo.golden").
+func trimVariant(fileName string) string {
+	base := strings.TrimSuffix(fileName, goldenExt)
+
+	if i := strings.LastIndexByte(base, '.'); i >= 0 {
+		return base[:i] + goldenExt
+	}
+
+	return fileName
+}
+
+func writeSuiteVariants(w *bufio.Writer, variants map[string]map[string]string) error {
+	names := make([]string, 0, len(variants))
+
+	for name := range variants {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "[variants.%q]\n", name); err != nil {
+			return fmt.Errorf("header write error: %w", err)
+		}
+
+		for _, k := range sortKeys(variants[name]) {
+			if _, err := fmt.Fprintf(w, "%q = %s\n", k, tomlMultilineString(variants[name][k])); err != nil {
+				return fmt.Errorf("variant write error: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package goldga
+
+import (
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Variant", func() {
+	var fs afero.Fs
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+	})
+
+	Describe("SuiteStorage", func() {
+		var suite *SuiteStorage
+
+		BeforeEach(func() {
+			suite = &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}
+			Expect(suite.Write([]byte("base\n"))).To(Succeed())
+		})
+
+		It("should write variants in their own table", func() {
+			Expect(withVariant(suite, "linux-amd64").Write([]byte("linux\n"))).To(Succeed())
+			Expect(withVariant(suite, "darwin").Write([]byte("darwin\n"))).To(Succeed())
+			Expect(string(mustReadFile(fs, "foo.golden"))).To(Equal(`# Generated by goldga. DO NOT EDIT.
+[snapshots]
+"foo" = '''
+base
+'''
+[variants."foo"]
+"darwin" = '''
+darwin
+'''
+"linux-amd64" = '''
+linux
+'''
+`))
+			Expect(suite.Read()).To(Equal([]byte("base\n")))
+			Expect(withVariant(suite, "linux-amd64").Read()).To(Equal([]byte("linux\n")))
+		})
+
+		It("should fall back to less specific variants", func() {
+			Expect(withVariant(suite, "linux").Write([]byte("linux\n"))).To(Succeed())
+			Expect(withVariant(suite, "linux-arm64").Read()).To(Equal([]byte("linux\n")))
+			Expect(withVariant(suite, "windows").Read()).To(Equal([]byte("base\n")))
+		})
+
+		It("should delete a variant", func() {
+			variant := withVariant(suite, "linux").(*SuiteStorage)
+			Expect(variant.Write([]byte("linux\n"))).To(Succeed())
+			Expect(variant.Delete()).To(Succeed())
+			Expect(variant.Read()).To(Equal([]byte("base\n")))
+			Expect(variant.Delete()).To(MatchError(afero.ErrFileNotFound))
+		})
+
+		It("should delete variants with the snapshot", func() {
+			Expect(withVariant(suite, "linux").Write([]byte("linux\n"))).To(Succeed())
+			Expect(suite.Delete()).To(Succeed())
+			Expect(string(mustReadFile(fs, "foo.golden"))).NotTo(ContainSubstring("variants"))
+		})
+	})
+
+	Describe("DirStorage", func() {
+		var dir *DirStorage
+
+		BeforeEach(func() {
+			dir = &DirStorage{Dir: "foo", Name: "bar", Fs: fs}
+			Expect(dir.Write([]byte("base"))).To(Succeed())
+		})
+
+		It("should insert the variant in the file name", func() {
+			Expect(withVariant(dir, "linux-amd64").Write([]byte("linux"))).To(Succeed())
+			Expect(mustReadFile(fs, "foo/bar.linux-amd64.golden")).To(Equal([]byte("linux")))
+			Expect(withVariant(dir, "windows").Read()).To(Equal([]byte("base")))
+		})
+
+		It("should keep variants of used snapshots when pruning", func() {
+			resetSnapshotUsages()
+			defer resetSnapshotUsages()
+
+			Expect(afero.WriteFile(fs, "foo/bar.darwin.golden", []byte("darwin"), 0o644)).To(Succeed())
+			Expect(afero.WriteFile(fs, "foo/baz.golden", []byte("baz"), 0o644)).To(Succeed())
+			recordMatchUsage(withVariant(dir, "linux"))
+
+			Expect(OrphanedSnapshots()).To(Equal(map[string][]string{"foo": {"baz.golden"}}))
+		})
+	})
+
+	It("should keep suite snapshots which only have variants when used", func() {
+		resetSnapshotUsages()
+		defer resetSnapshotUsages()
+
+		suite := &SuiteStorage{Path: "foo.golden", Fs: fs}
+		Expect(withVariant(suite.Named("a"), "linux").Write([]byte("a\n"))).To(Succeed())
+		Expect(withVariant(suite.Named("b"), "linux").Write([]byte("b\n"))).To(Succeed())
+		resetSnapshotUsages()
+		recordMatchUsage(suite.Named("a"))
+
+		Expect(OrphanedSnapshots()).To(Equal(map[string][]string{"foo.golden": {"b"}}))
+	})
+
+	It("should select the variant with SelectVariant", func() {
+		m := newMatcher("testdata/foo.golden", "foo", WithAutoVariant())
+		Expect(m.Storage.(*SuiteStorage).Variant).To(Equal(runtime.GOOS))
+
+		defer func(fn func() string) { SelectVariant = fn }(SelectVariant)
+		SelectVariant = func() string { return "custom" }
+
+		m = newMatcher("testdata/foo.golden", "foo", WithAutoVariant())
+		Expect(m.Storage.(*SuiteStorage).Variant).To(Equal("custom"))
+	})
+
+	It("should ignore other storages", func() {
+		single := &SingleStorage{Path: "foo.golden", Fs: fs}
+		Expect(withVariant(single, "linux")).To(BeIdenticalTo(single))
+	})
+})
@@ -0,0 +1,69 @@
+package goldga
+
+// StorageWrapper is a Storage which adds behavior to an inner storage, such as compression or
+// caching. Options which change the snapshot name or location, such as Named or WithNamespace,
+// apply to the innermost storage and keep the wrappers.
+type StorageWrapper interface {
+	Storage
+
+	// Unwrap returns the inner storage.
+	Unwrap() Storage
+	// Wrap returns a copy of the wrapper around inner.
+	Wrap(inner Storage) Storage
+}
+
+var (
+	_ StorageWrapper = (*CacheStorage)(nil)
+	_ StorageWrapper = (*CompressedStorage)(nil)
+	_ StorageWrapper = (*EncryptedStorage)(nil)
+	_ StorageWrapper = (*ReadOnlyStorage)(nil)
+)
+
+// mapStorage returns storage with fn applied to its innermost storage. Wrappers are copied, so
+// storages shared by other matchers are not changed.
+func mapStorage(storage Storage, fn func(Storage) Storage) Storage {
+	if w, ok := storage.(StorageWrapper); ok {
+		return w.Wrap(mapStorage(w.Unwrap(), fn))
+	}
+
+	return fn(storage)
+}
+
+// innerStorage returns the innermost storage of wrappers.
+func innerStorage(storage Storage) Storage {
+	for {
+		w, ok := storage.(StorageWrapper)
+		if !ok {
+			return storage
+		}
+
+		storage = w.Unwrap()
+	}
+}
+
+// namedInnerStorage implements NamedStorage for wrappers. The wrapper is returned unchanged if
+// the inner storage does not support names, like options do for storages without names.
+func namedInnerStorage(w StorageWrapper, name string) Storage {
+	if _, ok := innerStorage(w).(NamedStorage); !ok {
+		return w
+	}
+
+	return mapStorage(w, func(inner Storage) Storage {
+		return inner.(NamedStorage).Named(name)
+	})
+}
+
+// renameStorage renames the snapshot of a SuiteStorage or DirStorage with fn. Other storages are
+// returned unchanged.
+func renameStorage(storage Storage, fn func(name string) string) Storage {
+	return mapStorage(storage, func(inner Storage) Storage {
+		switch s := inner.(type) {
+		case *SuiteStorage:
+			return s.Named(fn(s.Name))
+		case *DirStorage:
+			return s.Named(fn(s.Name))
+		default:
+			return inner
+		}
+	})
+}
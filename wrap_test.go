@@ -0,0 +1,46 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("StorageWrapper", func() {
+	var fs afero.Fs
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+	})
+
+	It("should give sub-snapshots of wrapped storages their own files", func() {
+		m := newMatcher("foo", "foo",
+			WithStorage(&CompressedStorage{Inner: &DirStorage{Dir: "golden", Name: "foo", Fs: fs}}),
+			WithSerializer(&StringSerializer{}),
+			WithUpdatePolicy(UpdatePolicyCreateOnly),
+		)
+
+		Expect(m.Sub("a").Match("a")).To(BeTrue())
+		Expect(m.Sub("b").Match("b")).To(BeTrue())
+		Expect(afero.Exists(fs, "golden/foo_-_a.golden")).To(BeTrue())
+		Expect(afero.Exists(fs, "golden/foo_-_b.golden")).To(BeTrue())
+		Expect(m.Sub("a").Storage.Read()).To(Equal([]byte("a")))
+	})
+
+	It("should name nested wrappers", func() {
+		storage := &ReadOnlyStorage{Inner: &CacheStorage{Inner: &SuiteStorage{Path: "foo.golden", Name: "foo", Fs: fs}}}
+		named := withSubName(storage, "a")
+
+		Expect(getStorageName(named)).To(Equal("foo - a"))
+		Expect(named).To(BeAssignableToTypeOf(storage))
+		Expect(getStorageName(storage)).To(Equal("foo"))
+	})
+
+	It("should apply WithDescription to DirStorage without changing the given storage", func() {
+		storage := &DirStorage{Dir: "golden", Name: "foo", Fs: fs}
+		m := newMatcher("foo", "foo", WithStorage(storage), WithDescription("bar"))
+
+		Expect(m.Storage.(*DirStorage).Name).To(Equal("foo (bar)"))
+		Expect(storage.Name).To(Equal("foo"))
+	})
+})